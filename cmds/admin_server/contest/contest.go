@@ -0,0 +1,166 @@
+package contest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/insomniacslk/xjson"
+	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
+	"github.com/linuxboot/contest/pkg/transport"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+// Server is a contest server the admin server can send job actions to
+type Server struct {
+	Address   string
+	Transport transport.Transport
+}
+
+// Servers routes the job actions to the contest servers, the server running a
+// job is identified by the server id recorded in the job request.
+// Servers implements the job.Controller interface.
+type Servers struct {
+	servers []Server
+
+	mu sync.Mutex
+	// ids caches the server id returned by each server, indexed like servers
+	ids []string
+}
+
+// NewServers returns a Servers routing to the given contest servers
+func NewServers(servers []Server) *Servers {
+	return &Servers{
+		servers: servers,
+		ids:     make([]string, len(servers)),
+	}
+}
+
+// serverID returns the id of the i-th server, asking it if it's not cached yet
+func (s *Servers) serverID(ctx context.Context, requestor string, i int) (string, error) {
+	s.mu.Lock()
+	id := s.ids[i]
+	s.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+
+	resp, err := s.servers[i].Transport.Version(ctx, requestor)
+	if err == nil && resp.Err != nil {
+		err = resp.Err
+	}
+	if err != nil {
+		return "", fmt.Errorf("error while getting the id of contest server %s: %w", s.servers[i].Address, err)
+	}
+
+	s.mu.Lock()
+	s.ids[i] = resp.ServerID
+	s.mu.Unlock()
+	return resp.ServerID, nil
+}
+
+// Servers returns the servers that could be reached
+func (s *Servers) Servers(ctx context.Context, requestor string) []adminServerJob.Server {
+	res := []adminServerJob.Server{}
+	for i, server := range s.servers {
+		id, err := s.serverID(ctx, requestor, i)
+		if err != nil {
+			continue
+		}
+		res = append(res, adminServerJob.Server{ServerID: id, Address: server.Address})
+	}
+	return res
+}
+
+// get returns the transport to the server with the given id, or to the first
+// configured server if serverID is empty
+func (s *Servers) get(ctx context.Context, requestor string, serverID string) (transport.Transport, error) {
+	if len(s.servers) == 0 {
+		return nil, fmt.Errorf("%w: no contest server configured", adminServerJob.ErrUnknownServer)
+	}
+	if serverID == "" {
+		return s.servers[0].Transport, nil
+	}
+
+	var errs []error
+	for i, server := range s.servers {
+		id, err := s.serverID(ctx, requestor, i)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if id == serverID {
+			return server.Transport, nil
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s (%d servers unreachable, first error: %v)", adminServerJob.ErrUnknownServer, serverID, len(errs), errs[0])
+	}
+	return nil, fmt.Errorf("%w: %s", adminServerJob.ErrUnknownServer, serverID)
+}
+
+// apiError wraps the error returned by the contest api, if any
+func apiError(apiErr *xjson.Error) error {
+	if apiErr != nil {
+		return fmt.Errorf("%w: %v", adminServerJob.ErrActionRejected, apiErr)
+	}
+	return nil
+}
+
+// Start starts a job and returns its id and the id of the server running it
+func (s *Servers) Start(ctx context.Context, requestor string, serverID string, descriptor string) (types.JobID, string, error) {
+	t, err := s.get(ctx, requestor, serverID)
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := t.Start(ctx, requestor, descriptor)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := apiError(resp.Err); err != nil {
+		return 0, "", err
+	}
+	return resp.Data.JobID, resp.ServerID, nil
+}
+
+// Stop stops a job
+func (s *Servers) Stop(ctx context.Context, requestor string, serverID string, jobID types.JobID) error {
+	t, err := s.get(ctx, requestor, serverID)
+	if err != nil {
+		return err
+	}
+	resp, err := t.Stop(ctx, requestor, jobID)
+	if err != nil {
+		return err
+	}
+	return apiError(resp.Err)
+}
+
+// Pause pauses a job
+func (s *Servers) Pause(ctx context.Context, requestor string, serverID string, jobID types.JobID) error {
+	t, err := s.get(ctx, requestor, serverID)
+	if err != nil {
+		return err
+	}
+	resp, err := t.Pause(ctx, requestor, jobID)
+	if err != nil {
+		return err
+	}
+	return apiError(resp.Err)
+}
+
+// Retry starts a new job with the descriptor of a finished job and returns its id
+func (s *Servers) Retry(ctx context.Context, requestor string, serverID string, jobID types.JobID) (types.JobID, error) {
+	t, err := s.get(ctx, requestor, serverID)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := t.Retry(ctx, requestor, jobID)
+	if err != nil {
+		return 0, err
+	}
+	if err := apiError(resp.Err); err != nil {
+		return 0, err
+	}
+	return resp.Data.NewJobID, nil
+}
@@ -0,0 +1,91 @@
+package contest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/insomniacslk/xjson"
+	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/transport"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTransport answers the version requests with serverID or err, the
+// other methods are not expected to be called but Stop
+type stubTransport struct {
+	transport.Transport
+	serverID string
+	err      error
+
+	versionCalls int
+	stopped      []types.JobID
+}
+
+func (s *stubTransport) Version(ctx context.Context, requestor string) (*api.VersionResponse, error) {
+	s.versionCalls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &api.VersionResponse{ServerID: s.serverID}, nil
+}
+
+func (s *stubTransport) Stop(ctx context.Context, requestor string, jobID types.JobID) (*api.StopResponse, error) {
+	if jobID == 0 {
+		return &api.StopResponse{ServerID: s.serverID, Err: xjson.NewError(errors.New("job 0 does not exist"))}, nil
+	}
+	s.stopped = append(s.stopped, jobID)
+	return &api.StopResponse{ServerID: s.serverID}, nil
+}
+
+func TestServersGet(t *testing.T) {
+	ctx := context.Background()
+	t1 := &stubTransport{serverID: "server1"}
+	t2 := &stubTransport{serverID: "server2"}
+	down := &stubTransport{err: errors.New("connection refused")}
+	s := NewServers([]Server{
+		{Address: "http://down", Transport: down},
+		{Address: "http://server1", Transport: t1},
+		{Address: "http://server2", Transport: t2},
+	})
+
+	tr, err := s.get(ctx, "alice", "server2")
+	require.NoError(t, err)
+	require.Same(t, t2, tr)
+
+	// the default server is the first one, even if it's unreachable
+	tr, err = s.get(ctx, "alice", "")
+	require.NoError(t, err)
+	require.Same(t, down, tr)
+
+	_, err = s.get(ctx, "alice", "server3")
+	require.ErrorIs(t, err, adminServerJob.ErrUnknownServer)
+	require.Contains(t, err.Error(), "1 servers unreachable")
+
+	// the ids of the reachable servers are cached, the others are asked again
+	require.Equal(t, 1, t1.versionCalls)
+	require.Equal(t, 1, t2.versionCalls)
+	require.Equal(t, 2, down.versionCalls)
+
+	require.Equal(t, []adminServerJob.Server{
+		{ServerID: "server1", Address: "http://server1"},
+		{ServerID: "server2", Address: "http://server2"},
+	}, s.Servers(ctx, "alice"))
+
+	_, err = NewServers(nil).get(ctx, "alice", "")
+	require.ErrorIs(t, err, adminServerJob.ErrUnknownServer)
+}
+
+func TestServersStop(t *testing.T) {
+	ctx := context.Background()
+	t1 := &stubTransport{serverID: "server1"}
+	s := NewServers([]Server{{Address: "http://server1", Transport: t1}})
+
+	require.NoError(t, s.Stop(ctx, "alice", "server1", 7))
+	require.Equal(t, []types.JobID{7}, t1.stopped)
+
+	require.ErrorIs(t, s.Stop(ctx, "alice", "server1", 0), adminServerJob.ErrActionRejected)
+	require.ErrorIs(t, s.Stop(ctx, "alice", "server2", 7), adminServerJob.ErrUnknownServer)
+}
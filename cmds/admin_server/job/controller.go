@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"errors"
+
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+var (
+	// ErrUnknownServer is returned when no configured contest server has the requested server id
+	ErrUnknownServer = errors.New("unknown contest server")
+	// ErrActionRejected is returned when a contest server refuses a job action
+	ErrActionRejected = errors.New("job action rejected")
+)
+
+// Server describes a reachable contest server
+type Server struct {
+	ServerID string
+	Address  string
+}
+
+// Controller sends job actions to the contest servers on behalf of requestor,
+// a job can only be controlled through the server which runs it.
+// An empty serverID means the default server.
+type Controller interface {
+	Servers(ctx context.Context, requestor string) []Server
+	Start(ctx context.Context, requestor string, serverID string, descriptor string) (types.JobID, string, error)
+	Stop(ctx context.Context, requestor string, serverID string, jobID types.JobID) error
+	Pause(ctx context.Context, requestor string, serverID string, jobID types.JobID) error
+	// Retry returns the id of the new job
+	Retry(ctx context.Context, requestor string, serverID string, jobID types.JobID) (types.JobID, error)
+}
@@ -1,16 +1,30 @@
 package rdb
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/go-safeweb/safesql"
 	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
+	"github.com/linuxboot/contest/pkg/analytics"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
 
 var (
 	tagsStmt = safesql.New(`SELECT tag, COUNT(tag) FROM job_tags WHERE tag REGEXP CONCAT('.*',?,'.*') GROUP BY tag`)
 	jobStmt  = safesql.New(`SELECT t.job_id, r.reporter_name, r.report_time, r.data FROM job_tags t LEFT JOIN final_reports r ON t.job_id = r.job_id WHERE t.tag = ?`)
+	// targetEventsStmt selects the target routing events used by analytics.
+	targetEventsStmt = safesql.New(`SELECT e.job_id, e.run_id, e.test_name, e.test_attempt, e.test_step_label, e.event_name, e.target_id, e.payload, e.emit_time FROM test_events e JOIN job_tags t ON e.job_id = t.job_id WHERE t.tag = ? AND e.event_name IN (?, ?, ?) AND e.emit_time >= ? AND e.emit_time <= ? ORDER BY e.event_id`)
+	// jobEventsStmt selects the events used to build the status of a job.
+	jobEventsStmt  = safesql.New(`SELECT e.job_id, e.run_id, e.test_name, e.test_attempt, e.test_step_label, e.event_name, e.target_id, e.payload, e.emit_time FROM test_events e WHERE e.job_id = ? AND e.event_name IN (?, ?, ?, ?, ?) ORDER BY e.event_id`)
+	jobDetailsStmt = safesql.New(`SELECT j.job_id, j.name, j.requestor, j.server_id, j.request_time, j.state, j.descriptor, j.extended_descriptor, r.reporter_name, r.report_time, r.success, r.data FROM jobs j LEFT JOIN final_reports r ON j.job_id = r.job_id WHERE j.job_id = ?`)
+	jobTagsStmt    = safesql.New(`SELECT tag FROM job_tags WHERE job_id = ? ORDER BY tag`)
 )
 
 // SQL defines a struct that wraps a db connection to job sql database
@@ -134,6 +148,155 @@ func (r *Storage) GetJobs(ctx xcontext.Context, tagName string) ([]adminServerJo
 	}
 }
 
+// GetTargetEvents returns the target routing events of the jobs under a given tagName, emitted between since and until
+func (r *Storage) GetTargetEvents(ctx xcontext.Context, tagName string, since, until time.Time) ([]testevent.Event, error) {
+	args := []interface{}{tagName}
+	for _, name := range analytics.EventNames {
+		args = append(args, string(name))
+	}
+	args = append(args, since, until)
+	return r.getTestEvents(ctx, targetEventsStmt, args...)
+}
+
+// GetJobTargetEvents returns the events needed to build the status of a job
+func (r *Storage) GetJobTargetEvents(ctx xcontext.Context, jobID types.JobID) ([]testevent.Event, error) {
+	args := []interface{}{jobID}
+	for _, name := range adminServerJob.StatusEventNames {
+		args = append(args, string(name))
+	}
+	return r.getTestEvents(ctx, jobEventsStmt, args...)
+}
+
+// getTestEvents runs a query selecting the columns of testEventColumns
+func (r *Storage) getTestEvents(ctx xcontext.Context, stmt safesql.TrustedSQLString, args ...interface{}) ([]testevent.Event, error) {
+	var resultErr error
+	res := []testevent.Event{}
+	doneChan := make(chan struct{})
+
+	go func(doneChan chan<- struct{}) {
+		defer func() {
+			doneChan <- struct{}{}
+		}()
+
+		rows, err := r.db.Query(stmt, args...)
+		if err != nil {
+			resultErr = fmt.Errorf("error while listing test events (sql: %q): %w", stmt, err)
+			return
+		}
+		defer func() {
+			err = rows.Close()
+			if err != nil {
+				ctx.Errorf("error while closing the rows reader: %w", err)
+			}
+		}()
+
+		for rows.Next() {
+			if rows.Err() != nil {
+				resultErr = fmt.Errorf("error while reading the rows from query result: %w", err)
+				return
+			}
+
+			var (
+				header    testevent.Header
+				data      testevent.Data
+				ev        testevent.Event
+				testName  sql.NullString
+				stepLabel sql.NullString
+				targetID  sql.NullString
+				payload   sql.NullString
+			)
+			if err := rows.Scan(&header.JobID, &header.RunID, &testName, &header.TestAttempt, &stepLabel, &data.EventName, &targetID, &payload, &ev.EmitTime); err != nil {
+				resultErr = fmt.Errorf("error while scaning the test event (sql: %q): %w", stmt, err)
+				return
+			}
+			header.TestName = testName.String
+			header.TestStepLabel = stepLabel.String
+			if targetID.Valid {
+				data.Target = &target.Target{ID: targetID.String}
+			}
+			if payload.Valid {
+				rawPayload := json.RawMessage(payload.String)
+				data.Payload = &rawPayload
+			}
+			ev.Header, ev.Data = &header, &data
+			res = append(res, ev)
+		}
+	}(doneChan)
+
+	for {
+		select {
+		case <-doneChan:
+			return res, resultErr
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// GetJobDetails returns the request, the state, the tags and the final report if exists of a job
+func (r *Storage) GetJobDetails(ctx xcontext.Context, jobID types.JobID) (*adminServerJob.JobDetails, error) {
+	var resultErr error
+	var res *adminServerJob.JobDetails
+	doneChan := make(chan struct{})
+
+	go func(doneChan chan<- struct{}) {
+		defer func() {
+			doneChan <- struct{}{}
+		}()
+
+		var details adminServerJob.JobDetails
+		err := r.db.QueryRow(jobDetailsStmt, jobID).Scan(
+			&details.JobID, &details.Name, &details.Requestor, &details.ServerID, &details.RequestTime,
+			&details.State, &details.Descriptor, &details.ExtendedDescriptor,
+			&details.ReporterName, &details.ReportTime, &details.Success, &details.Data,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			resultErr = fmt.Errorf("%w: %d", adminServerJob.ErrJobNotFound, jobID)
+			return
+		}
+		if err != nil {
+			resultErr = fmt.Errorf("error while getting job %d (sql: %q): %w", jobID, jobDetailsStmt, err)
+			return
+		}
+
+		rows, err := r.db.Query(jobTagsStmt, jobID)
+		if err != nil {
+			resultErr = fmt.Errorf("error while listing the tags of job %d (sql: %q): %w", jobID, jobTagsStmt, err)
+			return
+		}
+		defer func() {
+			err = rows.Close()
+			if err != nil {
+				ctx.Errorf("error while closing the rows reader: %w", err)
+			}
+		}()
+
+		details.Tags = []string{}
+		for rows.Next() {
+			var tag string
+			if err := rows.Scan(&tag); err != nil {
+				resultErr = fmt.Errorf("error while scaning the job tag (sql: %q): %w", jobTagsStmt, err)
+				return
+			}
+			details.Tags = append(details.Tags, tag)
+		}
+		if err := rows.Err(); err != nil {
+			resultErr = fmt.Errorf("error while reading the rows from query result: %w", err)
+			return
+		}
+		res = &details
+	}(doneChan)
+
+	for {
+		select {
+		case <-doneChan:
+			return res, resultErr
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
 func (r *Storage) Close() error {
 	return r.db.Close()
 }
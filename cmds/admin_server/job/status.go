@@ -0,0 +1,165 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+// StatusEventNames are the names of the test events needed to build the status of a job
+var StatusEventNames = []event.Name{
+	target.EventTargetAcquired,
+	target.EventTargetAcquireErr,
+	target.EventTargetIn,
+	target.EventTargetOut,
+	target.EventTargetErr,
+}
+
+// TargetStatus contains the times a target entered and left a test step,
+// a nil OutTime means that the target is still in the step
+type TargetStatus struct {
+	TargetID string
+	InTime   *time.Time
+	OutTime  *time.Time
+	Error    *string
+}
+
+// StepStatus contains the status of the targets that went through a test step
+type StepStatus struct {
+	TestStepLabel string
+	Targets       []TargetStatus
+}
+
+// TestStatus contains the status of the latest attempt of a test in a run
+type TestStatus struct {
+	TestName    string
+	TestAttempt uint32
+	// targets acquired by the test, in the order they were acquired
+	Targets []string
+	// error returned by the target manager if the targets could not be acquired
+	AcquireError *string
+	// steps in the order the first target entered them
+	Steps []StepStatus
+}
+
+// RunStatus contains the status of the tests of a run
+type RunStatus struct {
+	RunID types.RunID
+	Tests []TestStatus
+}
+
+// BuildRunStatuses builds the status of the runs of a job from its test events,
+// which must be sorted in the order they were emitted
+func BuildRunStatuses(events []testevent.Event) []RunStatus {
+	var runs []RunStatus
+	runIndex := make(map[types.RunID]int)
+	type testKey struct {
+		runID    types.RunID
+		testName string
+	}
+	testIndex := make(map[testKey]int)
+
+	for _, ev := range events {
+		if ev.Header == nil || ev.Data == nil {
+			continue
+		}
+		ri, ok := runIndex[ev.Header.RunID]
+		if !ok {
+			ri = len(runs)
+			runIndex[ev.Header.RunID] = ri
+			runs = append(runs, RunStatus{RunID: ev.Header.RunID})
+		}
+		run := &runs[ri]
+
+		key := testKey{runID: ev.Header.RunID, testName: ev.Header.TestName}
+		ti, ok := testIndex[key]
+		if !ok {
+			ti = len(run.Tests)
+			testIndex[key] = ti
+			run.Tests = append(run.Tests, TestStatus{TestName: ev.Header.TestName, TestAttempt: ev.Header.TestAttempt})
+		}
+		test := &run.Tests[ti]
+		switch {
+		case ev.Header.TestAttempt < test.TestAttempt:
+			// only the latest attempt is shown
+			continue
+		case ev.Header.TestAttempt > test.TestAttempt:
+			*test = TestStatus{TestName: ev.Header.TestName, TestAttempt: ev.Header.TestAttempt}
+		}
+		test.addEvent(ev)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].RunID < runs[j].RunID
+	})
+	return runs
+}
+
+func (t *TestStatus) addEvent(ev testevent.Event) {
+	emitTime := ev.EmitTime
+	switch ev.Data.EventName {
+	case target.EventTargetAcquired:
+		if ev.Data.Target != nil {
+			t.Targets = append(t.Targets, ev.Data.Target.ID)
+		}
+		return
+	case target.EventTargetAcquireErr:
+		errMsg := eventError(ev)
+		t.AcquireError = &errMsg
+		return
+	}
+	if ev.Data.Target == nil {
+		return
+	}
+
+	var step *StepStatus
+	for i := range t.Steps {
+		if t.Steps[i].TestStepLabel == ev.Header.TestStepLabel {
+			step = &t.Steps[i]
+			break
+		}
+	}
+	if step == nil {
+		t.Steps = append(t.Steps, StepStatus{TestStepLabel: ev.Header.TestStepLabel})
+		step = &t.Steps[len(t.Steps)-1]
+	}
+	var targetStatus *TargetStatus
+	for i := range step.Targets {
+		if step.Targets[i].TargetID == ev.Data.Target.ID {
+			targetStatus = &step.Targets[i]
+			break
+		}
+	}
+	if targetStatus == nil {
+		step.Targets = append(step.Targets, TargetStatus{TargetID: ev.Data.Target.ID})
+		targetStatus = &step.Targets[len(step.Targets)-1]
+	}
+
+	switch ev.Data.EventName {
+	case target.EventTargetIn:
+		targetStatus.InTime = &emitTime
+	case target.EventTargetOut:
+		targetStatus.OutTime = &emitTime
+	case target.EventTargetErr:
+		targetStatus.OutTime = &emitTime
+		errMsg := eventError(ev)
+		targetStatus.Error = &errMsg
+	}
+}
+
+// eventError returns the error carried by the payload of an error event
+func eventError(ev testevent.Event) string {
+	if ev.Data.Payload == nil {
+		return string(ev.Data.EventName)
+	}
+	errPayload, err := target.UnmarshalErrPayload(*ev.Data.Payload)
+	if err != nil {
+		return fmt.Sprintf("could not unmarshal payload error: %v", err)
+	}
+	return errPayload.Error
+}
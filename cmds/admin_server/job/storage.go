@@ -1,8 +1,11 @@
 package job
 
 import (
+	"errors"
 	"time"
 
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
@@ -11,8 +14,21 @@ import (
 type Storage interface {
 	GetTags(ctx xcontext.Context, tagPattern string) ([]Tag, error)
 	GetJobs(ctx xcontext.Context, projectName string) ([]Job, error)
+	// GetTargetEvents returns the events of the targets entering and leaving
+	// the test steps of the jobs under a given tag, emitted between since
+	// and until, in the order they were emitted.
+	GetTargetEvents(ctx xcontext.Context, tagName string, since, until time.Time) ([]testevent.Event, error)
+	// GetJobDetails returns the request, the state and the final report of a job,
+	// or ErrJobNotFound.
+	GetJobDetails(ctx xcontext.Context, jobID types.JobID) (*JobDetails, error)
+	// GetJobTargetEvents returns the events of the targets being acquired by the tests
+	// and entering and leaving the test steps of a job, in the order they were emitted.
+	GetJobTargetEvents(ctx xcontext.Context, jobID types.JobID) ([]testevent.Event, error)
 }
 
+// ErrJobNotFound is returned when the requested job does not exist
+var ErrJobNotFound = errors.New("job not found")
+
 // Tag contains metadata about jobs under a given tag
 type Tag struct {
 	Name string
@@ -29,3 +45,17 @@ type Job struct {
 	Success      *bool
 	Data         *string
 }
+
+// JobDetails contains the request, the current state and the final report of a job
+type JobDetails struct {
+	Job
+	Name        string
+	Requestor   string
+	ServerID    string
+	RequestTime time.Time
+	State       job.State
+	// the descriptor as submitted and the descriptor with the resolved test fetchers
+	Descriptor         string
+	ExtendedDescriptor *string
+	Tags               []string
+}
@@ -6,15 +6,21 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	// this import registers mysql driver for safesql to use
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/linuxboot/contest/cmds/admin_server/contest"
 	"github.com/linuxboot/contest/cmds/admin_server/job/rdb"
 	"github.com/linuxboot/contest/cmds/admin_server/server"
+	adminServerStorage "github.com/linuxboot/contest/cmds/admin_server/storage"
 	mongoStorage "github.com/linuxboot/contest/cmds/admin_server/storage/mongo"
+	rdbmsStorage "github.com/linuxboot/contest/cmds/admin_server/storage/rdbms"
 	"github.com/linuxboot/contest/pkg/logging"
+	"github.com/linuxboot/contest/pkg/transport/http"
 	"github.com/linuxboot/contest/pkg/xcontext"
 	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
 	"github.com/linuxboot/contest/pkg/xcontext/logger"
@@ -28,16 +34,20 @@ var (
 	flagTLSCert      *string
 	flagTLSKey       *string
 	flagLogLevel     *string
+	flagContestURIs  *string
+	flagTrustProxy   *bool
 )
 
 func initFlags(cmd string) {
 	flagSet = flag.NewFlagSet(cmd, flag.ContinueOnError)
 	flagPort = flagSet.Int("port", 8000, "Port to init the admin server on")
-	flagDBURI = flagSet.String("dbURI", "mongodb://localhost:27017", "Database URI")
+	flagDBURI = flagSet.String("dbURI", "mongodb://localhost:27017", "Logs database URI, either mongodb://..., mysql://<dsn> or sqlite://<path>")
 	flagContestDBURI = flagSet.String("contestdbURI", "contest:contest@tcp(localhost:3306)/contest_integ?parseTime=true", "Contest Database URI")
 	flagTLSCert = flagSet.String("tlsCert", "", "Path to the tls cert file")
 	flagTLSKey = flagSet.String("tlsKey", "", "Path to the tls key file")
 	flagLogLevel = flagSet.String("logLevel", "debug", "A log level, possible values: debug, info, warning, error, panic, fatal")
+	flagContestURIs = flagSet.String("contestURIs", "", "Comma-separated list of the contest servers URIs the job actions are sent to")
+	flagTrustProxy = flagSet.Bool("trustProxyUser", false, "Take the user of the job actions from the "+server.RequestorHeader+" header, only enable it behind an authenticating proxy setting the header")
 
 }
 
@@ -69,7 +79,15 @@ func main() {
 	storageCtx, cancel := xcontext.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	storage, err := mongoStorage.NewMongoStorage(storageCtx, *flagDBURI)
+	var storage interface {
+		adminServerStorage.Storage
+		Close(ctx xcontext.Context) error
+	}
+	if rdbmsStorage.IsURI(*flagDBURI) {
+		storage, err = rdbmsStorage.NewRDBMSStorage(storageCtx, *flagDBURI)
+	} else {
+		storage, err = mongoStorage.NewMongoStorage(storageCtx, *flagDBURI)
+	}
 	if err != nil {
 		exitWithError(err, 1)
 	}
@@ -101,7 +119,19 @@ func main() {
 		}
 	}
 
-	if err := server.Serve(ctx, *flagPort, storage, jobStorage, nil, tlsConfig); err != nil {
+	var contestServers []contest.Server
+	for _, uri := range strings.Split(*flagContestURIs, ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			contestServers = append(contestServers, contest.Server{Address: uri, Transport: &http.HTTP{Addr: uri}})
+		}
+	}
+
+	var middlewares []gin.HandlerFunc
+	if *flagTrustProxy {
+		middlewares = append(middlewares, server.TrustProxyUser())
+	}
+
+	if err := server.Serve(ctx, *flagPort, storage, jobStorage, contest.NewServers(contestServers), middlewares, tlsConfig); err != nil {
 		exitWithError(fmt.Errorf("server err: %w", err), 1)
 	}
 }
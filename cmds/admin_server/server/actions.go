@@ -0,0 +1,175 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+const (
+	// RequestorKey is the key of the gin context where authentication middlewares
+	// store the acting user, who is recorded as the requestor of the job actions
+	RequestorKey = "requestor"
+	// RequestorHeader is the header in which an authenticating proxy passes the
+	// acting user, it's only used with the TrustProxyUser middleware
+	RequestorHeader = "X-Forwarded-User"
+)
+
+var (
+	DefaultContestAccessTimeout time.Duration = 30 * time.Second
+)
+
+type Server struct {
+	ServerID string `json:"server_id"`
+	Address  string `json:"address"`
+}
+
+type JobAction struct {
+	JobID    types.JobID `json:"job_id"`
+	ServerID string      `json:"server_id"`
+}
+
+// TrustProxyUser returns a middleware storing the user from the RequestorHeader
+// as the requestor. Clients can set the header themselves, so it must only be
+// used when the admin server is reachable only through a proxy that
+// authenticates the users and overwrites the header.
+func TrustProxyUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user := c.GetHeader(RequestorHeader); user != "" && c.GetString(RequestorKey) == "" {
+			c.Set(RequestorKey, user)
+		}
+		c.Next()
+	}
+}
+
+// requestor returns the acting user, it writes the error response if it is unknown
+func requestor(c *gin.Context) (string, bool) {
+	user := c.GetString(RequestorKey)
+	if user == "" {
+		c.JSON(http.StatusUnauthorized, makeRestErr("unknown user, job actions require an authenticated user"))
+		return "", false
+	}
+	return user, true
+}
+
+// writeContestErr writes the error response of a failed job action
+func (r *RouteHandler) writeContestErr(c *gin.Context, action string, err error) {
+	r.log.Errorf("Err while sending %s to contest: %v", action, err)
+	switch {
+	case errors.Is(err, adminServerJob.ErrActionRejected):
+		c.JSON(http.StatusBadRequest, makeRestErr("%s failed: %v", action, err))
+	case errors.Is(err, adminServerJob.ErrUnknownServer):
+		c.JSON(http.StatusNotFound, makeRestErr("%v", err))
+	case errors.Is(err, adminServerJob.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, makeRestErr("%v", err))
+	default:
+		c.JSON(http.StatusBadGateway, makeRestErr("%s failed: %v", action, err))
+	}
+}
+
+// getServers gets the contest servers that job actions can be sent to
+func (r *RouteHandler) getServers(c *gin.Context) {
+	user, ok := requestor(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultContestAccessTimeout)
+	defer cancel()
+	servers := []Server{}
+	for _, s := range r.jobController.Servers(ctx, user) {
+		servers = append(servers, Server{ServerID: s.ServerID, Address: s.Address})
+	}
+	c.JSON(http.StatusOK, servers)
+}
+
+// startJob starts a job with the descriptor sent as the "descriptor" form field or file,
+// on the server with the id in the "server_id" form field or on the default server
+func (r *RouteHandler) startJob(c *gin.Context) {
+	user, ok := requestor(c)
+	if !ok {
+		return
+	}
+
+	descriptor := c.PostForm("descriptor")
+	if file, err := c.FormFile("descriptor"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, makeRestErr("error while opening the descriptor file: %v", err))
+			return
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, makeRestErr("error while reading the descriptor file: %v", err))
+			return
+		}
+		descriptor = string(data)
+	}
+	if descriptor == "" {
+		c.JSON(http.StatusBadRequest, makeRestErr("missing job descriptor"))
+		return
+	}
+
+	ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultContestAccessTimeout)
+	defer cancel()
+	ctx = ctx.WithLogger(r.log)
+	jobID, serverID, err := r.jobController.Start(ctx, user, c.PostForm("server_id"), descriptor)
+	if err != nil {
+		r.writeContestErr(c, "start", err)
+		return
+	}
+
+	r.log.Infof("User %s started job %d on server %s", user, jobID, serverID)
+	c.JSON(http.StatusOK, JobAction{JobID: jobID, ServerID: serverID})
+}
+
+// jobAction sends an action about the job in the url parameter to the server running it,
+// act returns the id of the job resulting from the action
+func (r *RouteHandler) jobAction(action string, act func(ctx xcontext.Context, jc adminServerJob.Controller, user, serverID string, jobID types.JobID) (types.JobID, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID, ok := parseJobID(c)
+		if !ok {
+			return
+		}
+		user, ok := requestor(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultContestAccessTimeout)
+		defer cancel()
+		ctx = ctx.WithLogger(r.log)
+		details, err := r.jobStorage.GetJobDetails(ctx, jobID)
+		if err != nil {
+			r.writeContestErr(c, action, err)
+			return
+		}
+		resultJobID, err := act(ctx, r.jobController, user, details.ServerID, jobID)
+		if err != nil {
+			r.writeContestErr(c, action, err)
+			return
+		}
+
+		r.log.Infof("User %s sent %s for job %d to server %s", user, action, jobID, details.ServerID)
+		c.JSON(http.StatusOK, JobAction{JobID: resultJobID, ServerID: details.ServerID})
+	}
+}
+
+func stopJob(ctx xcontext.Context, jc adminServerJob.Controller, user, serverID string, jobID types.JobID) (types.JobID, error) {
+	return jobID, jc.Stop(ctx, user, serverID, jobID)
+}
+
+func pauseJob(ctx xcontext.Context, jc adminServerJob.Controller, user, serverID string, jobID types.JobID) (types.JobID, error) {
+	return jobID, jc.Pause(ctx, user, serverID, jobID)
+}
+
+func retryJob(ctx xcontext.Context, jc adminServerJob.Controller, user, serverID string, jobID types.JobID) (types.JobID, error) {
+	return jc.Retry(ctx, user, serverID, jobID)
+}
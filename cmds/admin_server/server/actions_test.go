@@ -0,0 +1,235 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeController records the last job action and returns err
+type fakeController struct {
+	servers []adminServerJob.Server
+	err     error
+
+	requestor  string
+	serverID   string
+	descriptor string
+	jobID      types.JobID
+}
+
+func (f *fakeController) Servers(ctx context.Context, requestor string) []adminServerJob.Server {
+	f.requestor = requestor
+	return f.servers
+}
+
+func (f *fakeController) Start(ctx context.Context, requestor string, serverID string, descriptor string) (types.JobID, string, error) {
+	f.requestor, f.serverID, f.descriptor = requestor, serverID, descriptor
+	if f.err != nil {
+		return 0, "", f.err
+	}
+	return 42, "server1", nil
+}
+
+func (f *fakeController) Stop(ctx context.Context, requestor string, serverID string, jobID types.JobID) error {
+	f.requestor, f.serverID, f.jobID = requestor, serverID, jobID
+	return f.err
+}
+
+func (f *fakeController) Pause(ctx context.Context, requestor string, serverID string, jobID types.JobID) error {
+	f.requestor, f.serverID, f.jobID = requestor, serverID, jobID
+	return f.err
+}
+
+func (f *fakeController) Retry(ctx context.Context, requestor string, serverID string, jobID types.JobID) (types.JobID, error) {
+	f.requestor, f.serverID, f.jobID = requestor, serverID, jobID
+	if f.err != nil {
+		return 0, f.err
+	}
+	return jobID + 1, nil
+}
+
+// fakeJobStorage only knows the details of the jobs in jobs
+type fakeJobStorage struct {
+	adminServerJob.Storage
+	jobs map[types.JobID]*adminServerJob.JobDetails
+}
+
+func (f *fakeJobStorage) GetJobDetails(ctx xcontext.Context, jobID types.JobID) (*adminServerJob.JobDetails, error) {
+	details, ok := f.jobs[jobID]
+	if !ok {
+		return nil, adminServerJob.ErrJobNotFound
+	}
+	return details, nil
+}
+
+func newTestRouter(jc adminServerJob.Controller, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	rh := RouteHandler{
+		jobStorage: &fakeJobStorage{jobs: map[types.JobID]*adminServerJob.JobDetails{
+			7: {Job: adminServerJob.Job{JobID: 7}, ServerID: "server1"},
+		}},
+		jobController: jc,
+		log:           xcontext.Background().Logger(),
+	}
+	return initRouter(xcontext.Background(), rh, middlewares)
+}
+
+func serve(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// authenticate returns a middleware storing user as the requestor, like an
+// authentication middleware would
+func authenticate(user string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(RequestorKey, user)
+		c.Next()
+	}
+}
+
+func TestJobActionsRequireUser(t *testing.T) {
+	jc := &fakeController{servers: []adminServerJob.Server{{ServerID: "server1", Address: "http://server1"}}}
+
+	w := serve(newTestRouter(jc), httptest.NewRequest(http.MethodGet, "/server", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// the header is not trusted unless the proxy is
+	req := httptest.NewRequest(http.MethodPost, "/job/7/stop", nil)
+	req.Header.Set(RequestorHeader, "mallory")
+	w = serve(newTestRouter(jc), req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Empty(t, jc.requestor)
+
+	req = httptest.NewRequest(http.MethodGet, "/server", nil)
+	req.Header.Set(RequestorHeader, "alice")
+	w = serve(newTestRouter(jc, TrustProxyUser()), req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "alice", jc.requestor)
+	var servers []Server
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &servers))
+	require.Equal(t, []Server{{ServerID: "server1", Address: "http://server1"}}, servers)
+
+	// an authenticated user takes precedence over the header
+	req = httptest.NewRequest(http.MethodGet, "/server", nil)
+	req.Header.Set(RequestorHeader, "mallory")
+	w = serve(newTestRouter(jc, authenticate("bob"), TrustProxyUser()), req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "bob", jc.requestor)
+}
+
+func TestJobActionErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		path     string
+		expected int
+	}{
+		{
+			name:     "unknown server",
+			err:      fmt.Errorf("%w: server2", adminServerJob.ErrUnknownServer),
+			path:     "/job/7/pause",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "rejected",
+			err:      fmt.Errorf("%w: job is not running", adminServerJob.ErrActionRejected),
+			path:     "/job/7/stop",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "unreachable",
+			err:      fmt.Errorf("connection refused"),
+			path:     "/job/7/retry",
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "unknown job",
+			path:     "/job/8/stop",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "bad job id",
+			path:     "/job/abc/stop",
+			expected: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			jc := &fakeController{err: tc.err}
+			w := serve(newTestRouter(jc, authenticate("alice")), httptest.NewRequest(http.MethodPost, tc.path, nil))
+			require.Equal(t, tc.expected, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestJobActionSentToJobServer(t *testing.T) {
+	jc := &fakeController{}
+	w := serve(newTestRouter(jc, authenticate("alice")), httptest.NewRequest(http.MethodPost, "/job/7/retry", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "alice", jc.requestor)
+	require.Equal(t, "server1", jc.serverID)
+	require.Equal(t, types.JobID(7), jc.jobID)
+
+	var action JobAction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &action))
+	require.Equal(t, JobAction{JobID: 8, ServerID: "server1"}, action)
+}
+
+func TestStartJob(t *testing.T) {
+	const descriptor = `{"JobName": "test"}`
+
+	t.Run("form field", func(t *testing.T) {
+		jc := &fakeController{}
+		form := url.Values{"descriptor": {descriptor}, "server_id": {"server2"}}
+		req := httptest.NewRequest(http.MethodPost, "/job", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := serve(newTestRouter(jc, authenticate("alice")), req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, descriptor, jc.descriptor)
+		require.Equal(t, "server2", jc.serverID)
+	})
+
+	t.Run("form file", func(t *testing.T) {
+		jc := &fakeController{}
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("descriptor", "job.json")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(descriptor))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/job", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := serve(newTestRouter(jc, authenticate("alice")), req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "alice", jc.requestor)
+		require.Equal(t, descriptor, jc.descriptor)
+		require.Empty(t, jc.serverID)
+
+		var action JobAction
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &action))
+		require.Equal(t, JobAction{JobID: 42, ServerID: "server1"}, action)
+	})
+
+	t.Run("missing descriptor", func(t *testing.T) {
+		jc := &fakeController{}
+		w := serve(newTestRouter(jc, authenticate("alice")), httptest.NewRequest(http.MethodPost, "/job", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, jc.requestor)
+	})
+}
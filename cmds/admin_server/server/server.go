@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	adminServerJob "github.com/linuxboot/contest/cmds/admin_server/job"
 	"github.com/linuxboot/contest/cmds/admin_server/storage"
+	"github.com/linuxboot/contest/pkg/analytics"
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
@@ -21,6 +23,8 @@ var (
 	MaxPageSize            uint          = 100
 	DefaultPage            uint          = 0
 	DefaultDBAccessTimeout time.Duration = 10 * time.Second
+	// DefaultAnalyticsWindow is the time window of the analytics when no start date is given.
+	DefaultAnalyticsWindow time.Duration = 7 * 24 * time.Hour
 )
 
 type Query struct {
@@ -128,31 +132,168 @@ type Job struct {
 	Report *report     `json:"report"`
 }
 
+func fromStorageReport(job *adminServerJob.Job) *report {
+	if job.ReporterName == nil {
+		return nil
+	}
+	return &report{
+		ReporterName: *job.ReporterName,
+		Success:      job.Success,
+		Time:         job.ReportTime,
+		Data:         job.Data,
+	}
+}
+
 func fromStorageJobs(storageJobs []adminServerJob.Job) []Job {
 	jobs := make([]Job, 0, len(storageJobs))
 	for _, job := range storageJobs {
-		var r *report
-		if job.ReporterName != nil {
-			r = &report{
-				ReporterName: *job.ReporterName,
-				Success:      job.Success,
-				Time:         job.ReportTime,
-				Data:         job.Data,
-			}
-		}
-
 		jobs = append(jobs, Job{
 			JobID:  job.JobID,
-			Report: r,
+			Report: fromStorageReport(&job),
 		})
 	}
 	return jobs
 }
 
+type JobDetails struct {
+	JobID              types.JobID `json:"job_id"`
+	Name               string      `json:"name"`
+	Requestor          string      `json:"requestor"`
+	ServerID           string      `json:"server_id"`
+	RequestTime        time.Time   `json:"request_time"`
+	State              string      `json:"state"`
+	Descriptor         string      `json:"descriptor"`
+	ExtendedDescriptor *string     `json:"extended_descriptor"`
+	Tags               []string    `json:"tags"`
+	Report             *report     `json:"report"`
+}
+
+func fromStorageJobDetails(details *adminServerJob.JobDetails) JobDetails {
+	return JobDetails{
+		JobID:              details.JobID,
+		Name:               details.Name,
+		Requestor:          details.Requestor,
+		ServerID:           details.ServerID,
+		RequestTime:        details.RequestTime,
+		State:              details.State.String(),
+		Descriptor:         details.Descriptor,
+		ExtendedDescriptor: details.ExtendedDescriptor,
+		Tags:               details.Tags,
+		Report:             fromStorageReport(&details.Job),
+	}
+}
+
+type TargetStatus struct {
+	TargetID string     `json:"target_id"`
+	InTime   *time.Time `json:"in_time"`
+	OutTime  *time.Time `json:"out_time"`
+	Error    *string    `json:"error"`
+}
+
+type StepStatus struct {
+	TestStepLabel string         `json:"test_step_label"`
+	Targets       []TargetStatus `json:"targets"`
+}
+
+type TestStatus struct {
+	TestName     string       `json:"test_name"`
+	TestAttempt  uint32       `json:"test_attempt"`
+	Targets      []string     `json:"targets"`
+	AcquireError *string      `json:"acquire_error"`
+	Steps        []StepStatus `json:"steps"`
+}
+
+type RunStatus struct {
+	RunID types.RunID  `json:"run_id"`
+	Tests []TestStatus `json:"tests"`
+}
+
+func fromJobRunStatuses(jobRuns []adminServerJob.RunStatus) []RunStatus {
+	runs := make([]RunStatus, 0, len(jobRuns))
+	for _, jobRun := range jobRuns {
+		run := RunStatus{RunID: jobRun.RunID, Tests: make([]TestStatus, 0, len(jobRun.Tests))}
+		for _, jobTest := range jobRun.Tests {
+			test := TestStatus{
+				TestName:     jobTest.TestName,
+				TestAttempt:  jobTest.TestAttempt,
+				Targets:      append([]string{}, jobTest.Targets...),
+				AcquireError: jobTest.AcquireError,
+				Steps:        make([]StepStatus, 0, len(jobTest.Steps)),
+			}
+			for _, jobStep := range jobTest.Steps {
+				step := StepStatus{TestStepLabel: jobStep.TestStepLabel, Targets: make([]TargetStatus, 0, len(jobStep.Targets))}
+				for _, t := range jobStep.Targets {
+					step.Targets = append(step.Targets, TargetStatus(t))
+				}
+				test.Steps = append(test.Steps, step)
+			}
+			run.Tests = append(run.Tests, test)
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+type Stats struct {
+	Executions     uint    `json:"executions"`
+	Failures       uint    `json:"failures"`
+	FailureRate    float64 `json:"failure_rate"`
+	Retries        uint    `json:"retries"`
+	MeanDurationMs int64   `json:"mean_duration_ms"`
+}
+
+func fromAnalyticsStats(s analytics.Stats) Stats {
+	return Stats{
+		Executions:     s.Executions,
+		Failures:       s.Failures,
+		FailureRate:    s.FailureRate,
+		Retries:        s.Retries,
+		MeanDurationMs: s.MeanDuration.Milliseconds(),
+	}
+}
+
+type StepStats struct {
+	TestStepLabel string `json:"test_step_label"`
+	Stats
+}
+
+type TargetStats struct {
+	TargetID string `json:"target_id"`
+	Stats
+}
+
+type Analytics struct {
+	Tag     string        `json:"tag"`
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	Jobs    uint          `json:"jobs"`
+	Steps   []StepStats   `json:"steps"`
+	Targets []TargetStats `json:"targets"`
+}
+
+func fromAnalyticsReport(tag string, since, until time.Time, report *analytics.Report) Analytics {
+	res := Analytics{
+		Tag:     tag,
+		Since:   since,
+		Until:   until,
+		Jobs:    report.Jobs,
+		Steps:   make([]StepStats, 0, len(report.Steps)),
+		Targets: make([]TargetStats, 0, len(report.Targets)),
+	}
+	for _, s := range report.Steps {
+		res.Steps = append(res.Steps, StepStats{TestStepLabel: s.TestStepLabel, Stats: fromAnalyticsStats(s.Stats)})
+	}
+	for _, t := range report.Targets {
+		res.Targets = append(res.Targets, TargetStats{TargetID: t.TargetID, Stats: fromAnalyticsStats(t.Stats)})
+	}
+	return res
+}
+
 type RouteHandler struct {
-	storage    storage.Storage
-	jobStorage adminServerJob.Storage
-	log        logger.Logger
+	storage       storage.Storage
+	jobStorage    adminServerJob.Storage
+	jobController adminServerJob.Controller
+	log           logger.Logger
 }
 
 // status is a simple endpoint to check if the serves is alive
@@ -259,6 +400,105 @@ func (r *RouteHandler) getJobs(c *gin.Context) {
 	c.JSON(http.StatusOK, fromStorageJobs(res))
 }
 
+// parseJobID parses the job id url parameter, it writes the error response if it is invalid
+func parseJobID(c *gin.Context) (types.JobID, bool) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || jobID == 0 {
+		c.JSON(http.StatusBadRequest, makeRestErr("bad formatted job id %q", c.Param("id")))
+		return 0, false
+	}
+	return types.JobID(jobID), true
+}
+
+// getJob gets the request, the current state and the final report -if it exists- of a job
+func (r *RouteHandler) getJob(c *gin.Context) {
+	jobID, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultDBAccessTimeout)
+	defer cancel()
+	ctx = ctx.WithLogger(r.log)
+	res, err := r.jobStorage.GetJobDetails(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, adminServerJob.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, makeRestErr("job %d not found", jobID))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, makeRestErr("error while getting the job"))
+		r.log.Errorf("Err while getting job from storage: %v", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fromStorageJobDetails(res))
+}
+
+// getJobStatus gets the status of the runs, tests, steps and targets of a job built from its test events
+func (r *RouteHandler) getJobStatus(c *gin.Context) {
+	jobID, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultDBAccessTimeout)
+	defer cancel()
+	ctx = ctx.WithLogger(r.log)
+	events, err := r.jobStorage.GetJobTargetEvents(ctx, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, makeRestErr("error while getting the job events"))
+		r.log.Errorf("Err while getting job events from storage: %v", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fromJobRunStatuses(adminServerJob.BuildRunStatuses(events)))
+}
+
+// getAnalytics gets the failure rates, retries and durations per test step and per target
+// of the jobs under a given tag as a url parameter, over a time window
+func (r *RouteHandler) getAnalytics(c *gin.Context) {
+	tagName := c.Param("name")
+	if err := job.CheckTags([]string{tagName}, false); err != nil {
+		c.JSON(http.StatusBadRequest, makeRestErr("bad formatted job tag %v", err))
+		return
+	}
+	var query struct {
+		Since *time.Time `form:"since" time_format:"2006-01-02T15:04:05.000Z07:00"`
+		Until *time.Time `form:"until" time_format:"2006-01-02T15:04:05.000Z07:00"`
+	}
+	if err := c.BindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, makeRestErr("bad formatted query %v", err))
+		r.log.Errorf("Err while binding request body %v", err)
+		return
+	}
+	until := time.Now()
+	if query.Until != nil {
+		until = *query.Until
+	}
+	since := until.Add(-DefaultAnalyticsWindow)
+	if query.Since != nil {
+		since = *query.Since
+	}
+	if until.Before(since) {
+		c.JSON(http.StatusBadRequest, makeRestErr("until is before since"))
+		return
+	}
+
+	ctx, cancel := xcontext.WithTimeout(xcontext.Background(), DefaultDBAccessTimeout)
+	defer cancel()
+	ctx = ctx.WithLogger(r.log)
+	events, err := r.jobStorage.GetTargetEvents(ctx, tagName, since, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, makeRestErr("error while getting the target events"))
+		r.log.Errorf("Err while getting target events from storage: %v", err)
+		return
+	}
+
+	aggregator := analytics.NewAggregator()
+	aggregator.Add(events)
+	c.JSON(http.StatusOK, fromAnalyticsReport(tagName, since, until, aggregator.Report()))
+}
+
 func makeRestErr(format string, args ...any) gin.H {
 	return gin.H{"status": "err", "msg": fmt.Sprintf(format, args...)}
 }
@@ -278,6 +518,14 @@ func initRouter(ctx xcontext.Context, rh RouteHandler, middlewares []gin.Handler
 	r.GET("/log", rh.getLogs)
 	r.GET("/tag", rh.getTags)
 	r.GET("/tag/:name/jobs", rh.getJobs)
+	r.GET("/tag/:name/analytics", rh.getAnalytics)
+	r.GET("/job/:id", rh.getJob)
+	r.GET("/job/:id/status", rh.getJobStatus)
+	r.GET("/server", rh.getServers)
+	r.POST("/job", rh.startJob)
+	r.POST("/job/:id/stop", rh.jobAction("stop", stopJob))
+	r.POST("/job/:id/pause", rh.jobAction("pause", pauseJob))
+	r.POST("/job/:id/retry", rh.jobAction("retry", retryJob))
 
 	// serve the frontend app
 	r.GET("/app/*filepath", func(c *gin.Context) {
@@ -292,11 +540,12 @@ func initRouter(ctx xcontext.Context, rh RouteHandler, middlewares []gin.Handler
 	return r
 }
 
-func Serve(ctx xcontext.Context, port int, storage storage.Storage, jobStorage adminServerJob.Storage, middlewares []gin.HandlerFunc, tlsConfig *tls.Config) error {
+func Serve(ctx xcontext.Context, port int, storage storage.Storage, jobStorage adminServerJob.Storage, jobController adminServerJob.Controller, middlewares []gin.HandlerFunc, tlsConfig *tls.Config) error {
 	routeHandler := RouteHandler{
-		storage:    storage,
-		jobStorage: jobStorage,
-		log:        ctx.Logger(),
+		storage:       storage,
+		jobStorage:    jobStorage,
+		jobController: jobController,
+		log:           ctx.Logger(),
 	}
 	router := initRouter(ctx, routeHandler, middlewares)
 	server := &http.Server{
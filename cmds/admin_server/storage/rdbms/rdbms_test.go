@@ -0,0 +1,105 @@
+package rdbms
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/cmds/admin_server/storage"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newStorage(t *testing.T) *RDBMSStorage {
+	s, err := NewRDBMSStorage(xcontext.Background(), SQLiteScheme+filepath.Join(t.TempDir(), "logs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close(xcontext.Background()) })
+	return s
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestStoreAndGetLogs(t *testing.T) {
+	ctx := xcontext.Background()
+	s := newStorage(t)
+
+	logs := []storage.Log{
+		{JobID: 1, LogData: "starting job", Date: start, LogLevel: "info"},
+		{JobID: 1, LogData: "100% done", Date: start.Add(time.Minute), LogLevel: "debug"},
+		{JobID: 2, LogData: "Target failed", Date: start.Add(2 * time.Minute), LogLevel: "error"},
+		{JobID: 2, LogData: "target_1 is back", Date: start.Add(3 * time.Minute), LogLevel: "info"},
+	}
+	require.NoError(t, s.StoreLogs(ctx, logs))
+	jobID := uint64(2)
+
+	for _, tc := range []struct {
+		name     string
+		query    storage.Query
+		expected []storage.Log
+	}{
+		{
+			name:     "all",
+			query:    storage.Query{PageSize: 10},
+			expected: logs,
+		},
+		{
+			name:     "job",
+			query:    storage.Query{JobID: &jobID, PageSize: 10},
+			expected: logs[2:],
+		},
+		{
+			name:     "text is case insensitive",
+			query:    storage.Query{Text: stringPtr("target"), PageSize: 10},
+			expected: logs[2:],
+		},
+		{
+			name:     "text wildcards are escaped",
+			query:    storage.Query{Text: stringPtr("t_1"), PageSize: 10},
+			expected: logs[3:],
+		},
+		{
+			name:     "percent",
+			query:    storage.Query{Text: stringPtr("0%"), PageSize: 10},
+			expected: logs[1:2],
+		},
+		{
+			name:     "levels",
+			query:    storage.Query{LogLevel: stringPtr("error,debug"), PageSize: 10},
+			expected: []storage.Log{logs[1], logs[2]},
+		},
+		{
+			name:     "date range",
+			query:    storage.Query{StartDate: timePtr(start.Add(time.Minute)), EndDate: timePtr(start.Add(2 * time.Minute).In(time.FixedZone("X", 3600))), PageSize: 10},
+			expected: logs[1:3],
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := s.GetLogs(ctx, tc.query)
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(tc.expected)), res.Count)
+			require.Equal(t, tc.expected, res.Logs)
+		})
+	}
+
+	res, err := s.GetLogs(ctx, storage.Query{PageSize: 3, Page: 1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), res.Count)
+	require.Equal(t, logs[3:], res.Logs)
+	require.Equal(t, uint(1), res.Page)
+	require.Equal(t, uint(3), res.PageSize)
+}
+
+func TestInvalidURI(t *testing.T) {
+	_, err := NewRDBMSStorage(xcontext.Background(), "mongodb://localhost:27017")
+	require.Error(t, err)
+	_, err = NewRDBMSStorage(xcontext.Background(), SQLiteScheme)
+	require.Error(t, err)
+}
@@ -0,0 +1,129 @@
+import React, { useEffect, useState } from 'react';
+import { useParams } from 'react-router-dom';
+import { Table, useToaster, Message, DateRangePicker, Button } from 'rsuite';
+import { Column, Cell, HeaderCell } from 'rsuite-table';
+import { getAnalytics, StepStats, TargetStats } from '../api/tags';
+import { TypeAttributes } from 'rsuite/esm/@types/common';
+import 'rsuite/dist/rsuite.min.css';
+
+const DefaultWindow = 7 * 24 * 60 * 60 * 1000;
+
+function PercentCell({ rowData, dataKey, ...props }: any) {
+    return (
+        <Cell {...props}>{(100 * rowData[dataKey]).toFixed(1) + '%'}</Cell>
+    );
+}
+
+function StatsColumns() {
+    return [
+        <Column key="executions" width={100} align="center">
+            <HeaderCell>Executions</HeaderCell>
+            <Cell className="log-table__cell" dataKey="executions" />
+        </Column>,
+        <Column key="failures" width={100} align="center">
+            <HeaderCell>Failures</HeaderCell>
+            <Cell className="log-table__cell" dataKey="failures" />
+        </Column>,
+        <Column key="failure_rate" width={120} align="center">
+            <HeaderCell>Failure Rate</HeaderCell>
+            <PercentCell className="log-table__cell" dataKey="failure_rate" />
+        </Column>,
+        <Column key="retries" width={100} align="center">
+            <HeaderCell>Retries</HeaderCell>
+            <Cell className="log-table__cell" dataKey="retries" />
+        </Column>,
+        <Column key="mean_duration_ms" width={160} align="center">
+            <HeaderCell>Mean Duration (ms)</HeaderCell>
+            <Cell className="log-table__cell" dataKey="mean_duration_ms" />
+        </Column>,
+    ];
+}
+
+export default function Analytics() {
+    const [loading, setLoading] = useState<boolean>(false);
+    const [dateRange, setDateRange] = useState<[Date, Date] | null>([
+        new Date(Date.now() - DefaultWindow),
+        new Date(),
+    ]);
+    const [jobs, setJobs] = useState<number>(0);
+    const [steps, setSteps] = useState<StepStats[]>([]);
+    const [targets, setTargets] = useState<TargetStats[]>([]);
+    const { name } = useParams();
+    const toaster = useToaster();
+
+    const showMsg = (type: TypeAttributes.Status, message: string) => {
+        toaster.push(
+            <Message showIcon type={type}>
+                {message}
+            </Message>,
+            { placement: 'topEnd' }
+        );
+    };
+
+    const updateAnalytics = async () => {
+        setLoading(true);
+        try {
+            let result = await getAnalytics(name || '', {
+                since: dateRange?.[0].toJSON(),
+                until: dateRange?.[1].toJSON(),
+            });
+            setJobs(result.jobs);
+            setSteps(result.steps || []);
+            setTargets(result.targets || []);
+        } catch (err) {
+            showMsg('error', err?.message);
+        }
+        setLoading(false);
+    };
+
+    useEffect(() => {
+        updateAnalytics();
+    }, []);
+
+    return (
+        <div>
+            <div>
+                <DateRangePicker
+                    format="yyyy-MM-dd HH:mm:ss"
+                    value={dateRange}
+                    onChange={setDateRange}
+                />
+                <Button appearance="primary" onClick={updateAnalytics}>
+                    Update
+                </Button>
+                <span> {jobs} jobs</span>
+            </div>
+            <h4>Steps</h4>
+            <Table
+                loading={loading}
+                height={350}
+                data={steps}
+                wordWrap="break-word"
+                rowHeight={30}
+            >
+                <Column width={300} align="left" flexGrow={1}>
+                    <HeaderCell>Step Label</HeaderCell>
+                    <Cell
+                        className="log-table__cell"
+                        dataKey="test_step_label"
+                    />
+                </Column>
+                {StatsColumns()}
+            </Table>
+            <h4>Targets</h4>
+            <Table
+                loading={loading}
+                height={350}
+                data={targets}
+                wordWrap="break-word"
+                rowHeight={30}
+            >
+                <Column width={300} align="left" flexGrow={1}>
+                    <HeaderCell>Target ID</HeaderCell>
+                    <Cell className="log-table__cell" dataKey="target_id" />
+                </Column>
+                {StatsColumns()}
+            </Table>
+        </div>
+    );
+}
@@ -0,0 +1,107 @@
+import superagent from 'superagent';
+import { Report } from './tags';
+
+// JobDetails defines the request, state and final report of a job
+export interface JobDetails {
+    job_id: number;
+    name: string;
+    requestor: string;
+    server_id: string;
+    request_time: string;
+    state: string;
+    descriptor: string;
+    extended_descriptor?: string;
+    tags: string[];
+    report?: Report;
+}
+
+// TargetStatus defines the times a target entered and left a step
+export interface TargetStatus {
+    target_id: string;
+    in_time?: string;
+    out_time?: string;
+    error?: string;
+}
+
+export interface StepStatus {
+    test_step_label: string;
+    targets: TargetStatus[];
+}
+
+export interface TestStatus {
+    test_name: string;
+    test_attempt: number;
+    targets: string[];
+    acquire_error?: string;
+    steps: StepStatus[];
+}
+
+export interface RunStatus {
+    run_id: number;
+    tests: TestStatus[];
+}
+
+// FinalStates are the states of jobs which will not change anymore
+export const FinalStates = [
+    'JobStateCompleted',
+    'JobStateFailed',
+    'JobStateCancelled',
+    'JobStateCancellationFailed',
+];
+
+export async function getJob(job_id: number): Promise<JobDetails> {
+    let result: superagent.Response = await superagent.get(`/job/${job_id}`);
+
+    return result.body;
+}
+
+export async function getJobStatus(job_id: number): Promise<RunStatus[]> {
+    let result: superagent.Response = await superagent.get(
+        `/job/${job_id}/status`
+    );
+
+    return result.body;
+}
+
+// Server defines a contest server that jobs can be sent to
+export interface Server {
+    server_id: string;
+    address: string;
+}
+
+// JobAction defines the job resulting from an action
+export interface JobAction {
+    job_id: number;
+    server_id: string;
+}
+
+export type Action = 'stop' | 'pause' | 'retry';
+
+export async function getServers(): Promise<Server[]> {
+    let result: superagent.Response = await superagent.get('/server');
+
+    return result.body;
+}
+
+export async function startJob(
+    descriptor: string,
+    server_id?: string
+): Promise<JobAction> {
+    let result: superagent.Response = await superagent
+        .post('/job')
+        .type('form')
+        .send({ descriptor, server_id: server_id ?? '' });
+
+    return result.body;
+}
+
+export async function jobAction(
+    job_id: number,
+    action: Action
+): Promise<JobAction> {
+    let result: superagent.Response = await superagent.post(
+        `/job/${job_id}/${action}`
+    );
+
+    return result.body;
+}
@@ -20,6 +20,36 @@ export interface Job {
     report?: Report;
 }
 
+export interface Stats {
+    executions: number;
+    failures: number;
+    failure_rate: number;
+    retries: number;
+    mean_duration_ms: number;
+}
+
+export interface StepStats extends Stats {
+    test_step_label: string;
+}
+
+export interface TargetStats extends Stats {
+    target_id: string;
+}
+
+export interface Analytics {
+    tag: string;
+    since: string;
+    until: string;
+    jobs: number;
+    steps: StepStats[];
+    targets: TargetStats[];
+}
+
+export interface AnalyticsQuery {
+    since?: string;
+    until?: string;
+}
+
 export async function getTags(query: TagQuery): Promise<Tag[]> {
     let result: superagent.Response = await superagent.get('/tag').query(query);
 
@@ -33,3 +63,14 @@ export async function getJobs(job_name: string): Promise<Job[]> {
 
     return result.body;
 }
+
+export async function getAnalytics(
+    tag_name: string,
+    query: AnalyticsQuery
+): Promise<Analytics> {
+    let result: superagent.Response = await superagent
+        .get(`/tag/${tag_name}/analytics`)
+        .query(query);
+
+    return result.body;
+}
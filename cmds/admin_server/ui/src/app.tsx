@@ -3,6 +3,9 @@ import { Routes, Route, Link } from 'react-router-dom';
 import SearchTags from './search_tags/search_tags';
 import SearchLogs from './search_logs/search_logs';
 import Jobs from './jobs/jobs';
+import Analytics from './analytics/analytics';
+import Job from './job/job';
+import StartJob from './start_job/start_job';
 import './app.scss';
 
 export default function App() {
@@ -25,6 +28,13 @@ export default function App() {
                     >
                         Tags
                     </Link>
+                    <Link
+                        className="navbar__link"
+                        to="/app/job/start"
+                        title="start a job"
+                    >
+                        Start Job
+                    </Link>
                 </div>
             </div>
             <Routes>
@@ -33,7 +43,13 @@ export default function App() {
                     <Route path="tag">
                         <Route index element={<SearchTags />} />
                         <Route path=":name" element={<Jobs />} />
+                        <Route
+                            path=":name/analytics"
+                            element={<Analytics />}
+                        />
                     </Route>
+                    <Route path="job/start" element={<StartJob />} />
+                    <Route path="job/:id" element={<Job />} />
                 </Route>
             </Routes>
         </>
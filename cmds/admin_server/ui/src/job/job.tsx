@@ -0,0 +1,258 @@
+import React, { useEffect, useState } from 'react';
+import { Link, useNavigate, useParams } from 'react-router-dom';
+import {
+    Table,
+    useToaster,
+    Message,
+    Panel,
+    PanelGroup,
+    Button,
+    ButtonToolbar,
+} from 'rsuite';
+import { Column, Cell, HeaderCell } from 'rsuite-table';
+import { TypeAttributes } from 'rsuite/esm/@types/common';
+import {
+    getJob,
+    getJobStatus,
+    jobAction,
+    Action,
+    FinalStates,
+    JobDetails,
+    RunStatus,
+    TestStatus,
+} from '../api/jobs';
+import DateCell from '../date_cell/date_cell';
+import 'rsuite/dist/rsuite.min.css';
+import './job.scss';
+
+// RefreshInterval is how often the status of a running job is updated
+const RefreshInterval = 5000;
+
+interface TimelineEntry {
+    test_step_label: string;
+    in_time?: string;
+    out_time?: string;
+    error?: string;
+}
+
+// targetTimeline returns the steps a target went through, in order
+function targetTimeline(test: TestStatus, targetID: string): TimelineEntry[] {
+    let entries: TimelineEntry[] = [];
+    for (const step of test.steps) {
+        for (const t of step.targets) {
+            if (t.target_id === targetID) {
+                entries.push({ test_step_label: step.test_step_label, ...t });
+            }
+        }
+    }
+    return entries;
+}
+
+function StepsTable({ test }: { test: TestStatus }) {
+    const [targetID, setTargetID] = useState<string | null>(null);
+    const rows = test.steps.flatMap((step) =>
+        step.targets.map((t) => ({
+            test_step_label: step.test_step_label,
+            ...t,
+        }))
+    );
+
+    return (
+        <>
+            <Table
+                height={300}
+                data={rows}
+                wordWrap="break-word"
+                rowHeight={30}
+                onRowClick={(row) => setTargetID(row.target_id)}
+            >
+                <Column width={200} align="left">
+                    <HeaderCell>Step Label</HeaderCell>
+                    <Cell
+                        className="log-table__cell"
+                        dataKey="test_step_label"
+                    />
+                </Column>
+                <Column width={200} align="left">
+                    <HeaderCell>Target ID</HeaderCell>
+                    <Cell className="log-table__cell" dataKey="target_id" />
+                </Column>
+                <Column width={200} align="center">
+                    <HeaderCell>In</HeaderCell>
+                    <DateCell className="log-table__cell" dataKey="in_time" />
+                </Column>
+                <Column width={200} align="center">
+                    <HeaderCell>Out</HeaderCell>
+                    <DateCell className="log-table__cell" dataKey="out_time" />
+                </Column>
+                <Column width={300} align="left" flexGrow={1}>
+                    <HeaderCell>Error</HeaderCell>
+                    <Cell className="log-table__cell" dataKey="error" />
+                </Column>
+            </Table>
+            {targetID && (
+                <div className="job__timeline">
+                    <h5>Timeline of {targetID}</h5>
+                    <ol>
+                        {targetTimeline(test, targetID).map((entry) => (
+                            <li key={entry.test_step_label}>
+                                {entry.test_step_label}:{' '}
+                                {entry.in_time &&
+                                    new Date(entry.in_time).toLocaleString()}
+                                {' - '}
+                                {entry.out_time
+                                    ? new Date(entry.out_time).toLocaleString()
+                                    : 'running'}
+                                {entry.error && ` (${entry.error})`}
+                            </li>
+                        ))}
+                    </ol>
+                </div>
+            )}
+        </>
+    );
+}
+
+export default function Job() {
+    const [job, setJob] = useState<JobDetails | null>(null);
+    const [runs, setRuns] = useState<RunStatus[]>([]);
+    const { id } = useParams();
+    const jobID = parseInt(id || '');
+    const toaster = useToaster();
+    const navigate = useNavigate();
+
+    const showMsg = (type: TypeAttributes.Status, message: string) => {
+        toaster.push(
+            <Message showIcon type={type}>
+                {message}
+            </Message>,
+            { placement: 'topEnd' }
+        );
+    };
+
+    const update = async (): Promise<boolean> => {
+        try {
+            let details = await getJob(jobID);
+            setJob(details);
+            setRuns((await getJobStatus(jobID)) || []);
+            return FinalStates.includes(details.state);
+        } catch (err) {
+            showMsg('error', err?.message);
+            return true;
+        }
+    };
+
+    const act = async (action: Action) => {
+        try {
+            let result = await jobAction(jobID, action);
+            showMsg('success', `${action} sent to ${result.server_id}`);
+            if (result.job_id !== jobID) {
+                navigate(`/app/job/${result.job_id}`);
+                return;
+            }
+            update();
+        } catch (err) {
+            showMsg('error', err?.response?.body?.msg ?? err?.message);
+        }
+    };
+
+    useEffect(() => {
+        let timer: ReturnType<typeof setTimeout> | undefined;
+        let cancelled = false;
+        const poll = async () => {
+            const done = await update();
+            if (!done && !cancelled) {
+                timer = setTimeout(poll, RefreshInterval);
+            }
+        };
+        poll();
+        return () => {
+            cancelled = true;
+            clearTimeout(timer);
+        };
+    }, [id]);
+
+    if (!job) {
+        return <div />;
+    }
+
+    return (
+        <div className="job">
+            <div className="job__header">
+                <h4>
+                    Job {job.job_id}: {job.name}
+                </h4>
+                <p>State: {job.state}</p>
+                <p>Requestor: {job.requestor}</p>
+                <p>Server: {job.server_id}</p>
+                <p>
+                    Requested: {new Date(job.request_time).toLocaleString()}
+                </p>
+                <p>
+                    Tags:{' '}
+                    {job.tags.map((tag) => (
+                        <Link key={tag} to={`/app/tag/${tag}`}>
+                            {tag}{' '}
+                        </Link>
+                    ))}
+                </p>
+                {job.report && (
+                    <p>Report success: {String(job.report.success)}</p>
+                )}
+                <Link to={`/app?job_id=${job.job_id}`}>Logs</Link>{' '}
+                <ButtonToolbar>
+                    <Button size="xs" onClick={update}>
+                        Refresh
+                    </Button>
+                    <Button
+                        size="xs"
+                        color="orange"
+                        appearance="primary"
+                        disabled={FinalStates.includes(job.state)}
+                        onClick={() => act('pause')}
+                    >
+                        Pause
+                    </Button>
+                    <Button
+                        size="xs"
+                        color="red"
+                        appearance="primary"
+                        disabled={FinalStates.includes(job.state)}
+                        onClick={() => act('stop')}
+                    >
+                        Stop
+                    </Button>
+                    <Button
+                        size="xs"
+                        color="green"
+                        appearance="primary"
+                        disabled={!FinalStates.includes(job.state)}
+                        onClick={() => act('retry')}
+                    >
+                        Retry
+                    </Button>
+                </ButtonToolbar>
+            </div>
+            <PanelGroup bordered>
+                <Panel header="Descriptor">
+                    <pre>{job.extended_descriptor ?? job.descriptor}</pre>
+                </Panel>
+                {runs.flatMap((run) =>
+                    run.tests.map((test) => (
+                        <Panel
+                            key={`${run.run_id}/${test.test_name}`}
+                            header={`Run ${run.run_id} - ${test.test_name} (attempt ${test.test_attempt})`}
+                            defaultExpanded
+                        >
+                            <p>Targets: {test.targets.join(', ')}</p>
+                            {test.acquire_error && (
+                                <p>Acquire error: {test.acquire_error}</p>
+                            )}
+                            <StepsTable test={test} />
+                        </Panel>
+                    ))
+                )}
+            </PanelGroup>
+        </div>
+    );
+}
@@ -1,5 +1,5 @@
 import React, { useEffect, useState } from 'react';
-import { useParams } from 'react-router-dom';
+import { Link, useParams } from 'react-router-dom';
 import { Table, useToaster, Message } from 'rsuite';
 import { Column, Cell, HeaderCell } from 'rsuite-table';
 import { getJobs, Job } from '../api/tags';
@@ -36,6 +36,9 @@ export default function Jobs() {
 
     return (
         <div>
+            <Link to="analytics" title="flaky steps and targets">
+                Analytics
+            </Link>
             <Table
                 loading={loading}
                 height={700}
@@ -45,7 +48,13 @@ export default function Jobs() {
             >
                 <Column width={80} align="center" fixed>
                     <HeaderCell>Job ID</HeaderCell>
-                    <Cell className="log-table__cell" dataKey="job_id" />
+                    <Cell className="log-table__cell" dataKey="job_id">
+                        {(rowData) => (
+                            <Link to={`/app/job/${rowData.job_id}`}>
+                                {rowData.job_id}
+                            </Link>
+                        )}
+                    </Cell>
                 </Column>
                 <Column width={250} align="center" fixed>
                     <HeaderCell>Report Time</HeaderCell>
@@ -1,12 +1,18 @@
 import React, { useState, useEffect, useMemo, useRef } from 'react';
+import { useSearchParams } from 'react-router-dom';
 import { Input, DateRangePicker, TagPicker, InputNumber } from 'rsuite';
 import LogTable from './log_table/log_table';
 import { Levels } from '../api/logs';
 import './search_logs.scss';
 
 export default function SearchLogs() {
+    const [searchParams] = useSearchParams();
     const [queryText, setQueryText] = useState<string>('');
-    const [jobID, setJobID] = useState<number | null>(null);
+    const [jobID, setJobID] = useState<number | null>(
+        searchParams.has('job_id')
+            ? parseInt(searchParams.get('job_id') || '')
+            : null
+    );
     const [logLevels, setLogLevels] = useState<string[]>([]);
     const [dateRange, setDateRange] = useState<[Date, Date] | null>(null);
 
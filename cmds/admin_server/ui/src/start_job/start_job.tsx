@@ -0,0 +1,91 @@
+import React, { useEffect, useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { Button, Input, Message, SelectPicker, useToaster } from 'rsuite';
+import { TypeAttributes } from 'rsuite/esm/@types/common';
+import { getServers, startJob, Server } from '../api/jobs';
+import './start_job.scss';
+
+export default function StartJob() {
+    const [servers, setServers] = useState<Server[]>([]);
+    const [serverID, setServerID] = useState<string | null>(null);
+    const [descriptor, setDescriptor] = useState<string>('');
+    const [loading, setLoading] = useState<boolean>(false);
+    const navigate = useNavigate();
+    const toaster = useToaster();
+
+    const showMsg = (type: TypeAttributes.Status, message: string) => {
+        toaster.push(
+            <Message showIcon type={type}>
+                {message}
+            </Message>,
+            { placement: 'topEnd' }
+        );
+    };
+
+    useEffect(() => {
+        (async () => {
+            try {
+                setServers((await getServers()) || []);
+            } catch (err) {
+                showMsg('error', err?.response?.body?.msg ?? err?.message);
+            }
+        })();
+    }, []);
+
+    const uploadDescriptor = async (e: React.ChangeEvent<HTMLInputElement>) => {
+        const file = e.target.files?.[0];
+        if (file) {
+            setDescriptor(await file.text());
+        }
+    };
+
+    const start = async () => {
+        setLoading(true);
+        try {
+            let result = await startJob(descriptor, serverID ?? undefined);
+            navigate(`/app/job/${result.job_id}`);
+        } catch (err) {
+            showMsg('error', err?.response?.body?.msg ?? err?.message);
+        }
+        setLoading(false);
+    };
+
+    return (
+        <div className="start-job">
+            <div className="start-job__input-group">
+                <p>Server:</p>
+                <SelectPicker
+                    className="filter-input"
+                    data={servers.map((s) => ({
+                        label: `${s.server_id} (${s.address})`,
+                        value: s.server_id,
+                    }))}
+                    value={serverID}
+                    onChange={setServerID}
+                    placeholder="default server"
+                />
+            </div>
+            <div className="start-job__input-group">
+                <p>Descriptor file:</p>
+                <input type="file" onChange={uploadDescriptor} />
+            </div>
+            <Input
+                as="textarea"
+                rows={25}
+                placeholder="job descriptor"
+                value={descriptor}
+                onChange={setDescriptor}
+            />
+            <Button
+                className="start-job__btn"
+                color="green"
+                appearance="primary"
+                loading={loading}
+                disabled={descriptor === ''}
+                onClick={start}
+            >
+                Start
+            </Button>
+        </div>
+    );
+}
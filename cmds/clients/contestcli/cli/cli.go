@@ -32,6 +32,11 @@ var (
 	flagYAML      *bool
 	flagStates    *[]string
 	flagTags      *[]string
+	flagArchive   *bool
+	flagOutput    *string
+	flagSince     *time.Duration
+	flagRunsAfter *uint64
+	flagMaxRuns   *uint
 )
 
 func initFlags(cmd string) {
@@ -45,6 +50,19 @@ func initFlags(cmd string) {
 	flagStates = flagSet.StringSlice("states", []string{}, "List of job states for the list command. A job must be in any of the specified states to match.")
 	flagTags = flagSet.StringSlice("tags", []string{}, "List of tags for the list command. A job must have all the tags to match.")
 
+	// Flags for the "status" command.
+	flagRunsAfter = flagSet.Uint64("runsAfter", 0, "Only include the statuses of the runs following this run ID in the status command.")
+	flagMaxRuns = flagSet.Uint("maxRuns", 0, "Maximum number of run statuses to include in the status command, 0 means no limit.")
+
+	// Flags for the "purge" command.
+	flagArchive = flagSet.Bool("archive", false, "Archive the job on the server before purging it, requires the server to have an archive directory.")
+
+	// Flags for the "analytics" command.
+	flagSince = flagSet.Duration("since", 7*24*time.Hour, "Time window of the analytics command, ending now.")
+
+	// Flags for the "export" command.
+	flagOutput = flagSet.StringP("output", "o", "", "File to write the job archive to in the export command, defaults to job-<jobID>.json.gz")
+
 	flagSet.Usage = func() {
 		fmt.Fprintf(flagSet.Output(),
 			`Usage:
@@ -57,14 +75,46 @@ Commands:
         or passed via stdin.
         when used with -wait flag, stdout will have two JSON outputs
         for job start and completion status separated with newline
+  validate [file]
+        validate a job description from the specified file or passed via
+        stdin, without starting a job. All the errors found are reported,
+        and the exit status is non-zero if the description is not valid
   stop int
         stop a job by job ID
-  status int
-        get the status of a job by job ID
+  pause int
+        pause a running job by job ID, keeping its targets locked; it stays
+        paused when the server restarts, until it is resumed
+  resume int
+        resume a job paused on the same server by job ID
+  status [--runsAfter int] [--maxRuns int] int
+        get the status of a job by job ID, optionally only for a page of
+        its runs
   retry int
         retry a job by job ID
   list [--states=JobStateStarted,...] [--tags=foo,...]
         list jobs by state and/or tags
+  locks [targetID...]
+        list the target locks with the job holding them, optionally
+        restricted to the given targets
+  unlock targetID [targetID...]
+        forcibly unlock the given targets regardless of the job holding
+        them, the override is recorded as an event of that job
+  purge [--archive] int
+        permanently delete the request, reports and events of a finished
+        job by job ID, optionally archiving them on the server first
+  analytics [--since duration] tag
+        compute the failure rates, retries and mean durations per test step
+        and per target of the jobs with the given tag, over a time window
+  diff int int
+        compare the statuses of two jobs by job ID, reporting per step the
+        targets whose result or error changed, the new and missing targets
+        and the change of duration
+  export [--output file] int
+        write the request, reports and events of a job by job ID to a
+        gzipped JSON archive
+  import file
+        import a job archive written by export, or by purge --archive on
+        the server, under a new job ID. Only finished jobs can be imported
   version
         request the API version to the server
 
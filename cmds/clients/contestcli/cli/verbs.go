@@ -7,6 +7,7 @@ package cli
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -31,38 +32,12 @@ func run(requestor string, transport transport.Transport, stdout io.Writer) erro
 		return fmt.Errorf("Missing verb, see --help")
 	}
 	var resp interface{}
-	var err error
+	var err, verbErr error
 	switch verb {
 	case "start":
-		var jobDesc []byte
-		if flagSet.Arg(1) == "" {
-			fmt.Fprintf(os.Stderr, "Reading from stdin...\n")
-			jd, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				return fmt.Errorf("failed to read job descriptor: %w", err)
-			}
-			jobDesc = jd
-		} else {
-			jd, err := os.ReadFile(flagSet.Arg(1))
-			if err != nil {
-				return fmt.Errorf("failed to read job descriptor: %w", err)
-			}
-			jobDesc = jd
-		}
-
-		jobDescFormat := config.JobDescFormatJSON
-		if *flagYAML {
-			jobDescFormat = config.JobDescFormatYAML
-		}
-		jobDescJSON, err := config.ParseJobDescriptor(jobDesc, jobDescFormat)
-		if err != nil {
-			return fmt.Errorf("failed to parse job descriptor: %w", err)
-		}
-
-		// Add the version field if it does not exist
-		jobDescJSON, err = addVersion(jobDescJSON)
+		jobDescJSON, err := readJobDescriptor(flagSet.Arg(1))
 		if err != nil {
-			return fmt.Errorf("failed to add version to descriptor: %w", err)
+			return err
 		}
 
 		startResp, err := transport.Start(context.Background(), requestor, string(jobDescJSON))
@@ -91,6 +66,23 @@ func run(requestor string, transport transport.Transport, stdout io.Writer) erro
 				return err
 			}
 		}
+	case "validate":
+		jobDescJSON, err := readJobDescriptor(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		validateResp, err := transport.Validate(context.Background(), requestor, string(jobDescJSON))
+		if err != nil {
+			return err
+		}
+		resp = validateResp
+		// the response is printed anyway, but the exit status must reflect
+		// the validation result.
+		if validateResp.Err != nil {
+			verbErr = fmt.Errorf("server responded with an error: %s", validateResp.Err)
+		} else if len(validateResp.Data.Errors) > 0 {
+			verbErr = fmt.Errorf("job descriptor is not valid, found %d error(s)", len(validateResp.Data.Errors))
+		}
 	case "stop":
 		jobID, err := parseJob(flagSet.Arg(1))
 		if err != nil {
@@ -100,12 +92,30 @@ func run(requestor string, transport transport.Transport, stdout io.Writer) erro
 		if err != nil {
 			return err
 		}
+	case "pause":
+		jobID, err := parseJob(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		resp, err = transport.Pause(context.Background(), requestor, jobID)
+		if err != nil {
+			return err
+		}
+	case "resume":
+		jobID, err := parseJob(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		resp, err = transport.Resume(context.Background(), requestor, jobID)
+		if err != nil {
+			return err
+		}
 	case "status":
 		jobID, err := parseJob(flagSet.Arg(1))
 		if err != nil {
 			return err
 		}
-		resp, err = transport.Status(context.Background(), requestor, jobID)
+		resp, err = transport.StatusPage(context.Background(), requestor, jobID, types.RunID(*flagRunsAfter), *flagMaxRuns)
 		if err != nil {
 			return err
 		}
@@ -131,6 +141,85 @@ func run(requestor string, transport transport.Transport, stdout io.Writer) erro
 		if err != nil {
 			return err
 		}
+	case "locks":
+		resp, err = transport.Locks(context.Background(), requestor, flagSet.Args()[1:])
+		if err != nil {
+			return err
+		}
+	case "unlock":
+		targetIDs := flagSet.Args()[1:]
+		if len(targetIDs) == 0 {
+			return errors.New("no target ID specified")
+		}
+		resp, err = transport.Unlock(context.Background(), requestor, targetIDs)
+		if err != nil {
+			return err
+		}
+	case "purge":
+		jobID, err := parseJob(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		resp, err = transport.Purge(context.Background(), requestor, jobID, *flagArchive)
+		if err != nil {
+			return err
+		}
+	case "analytics":
+		if flagSet.Arg(1) == "" {
+			return errors.New("no tag specified")
+		}
+		until := time.Now()
+		resp, err = transport.Analytics(context.Background(), requestor, flagSet.Arg(1), until.Add(-*flagSince), until)
+		if err != nil {
+			return err
+		}
+	case "diff":
+		jobA, err := parseJob(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		jobB, err := parseJob(flagSet.Arg(2))
+		if err != nil {
+			return err
+		}
+		resp, err = transport.Diff(context.Background(), requestor, jobA, jobB)
+		if err != nil {
+			return err
+		}
+	case "export":
+		jobID, err := parseJob(flagSet.Arg(1))
+		if err != nil {
+			return err
+		}
+		exportResp, err := transport.Export(context.Background(), requestor, jobID)
+		if err != nil {
+			return err
+		}
+		if exportResp.Err == nil {
+			output := *flagOutput
+			if output == "" {
+				output = fmt.Sprintf("job-%d.json.gz", jobID)
+			}
+			if err := writeJobArchive(output, exportResp.Data.Archive); err != nil {
+				return fmt.Errorf("cannot write job archive: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Job archive written to %s\n", output)
+		}
+		// the archive is in the output file, don't dump it to stdout as well.
+		exportResp.Data.Archive = nil
+		resp = exportResp
+	case "import":
+		if flagSet.Arg(1) == "" {
+			return errors.New("no job archive specified")
+		}
+		archive, err := os.ReadFile(flagSet.Arg(1))
+		if err != nil {
+			return fmt.Errorf("cannot read job archive: %w", err)
+		}
+		resp, err = transport.Import(context.Background(), requestor, archive)
+		if err != nil {
+			return err
+		}
 	case "version":
 		resp, err = transport.Version(context.Background(), requestor)
 		if err != nil {
@@ -148,7 +237,7 @@ func run(requestor string, transport transport.Transport, stdout io.Writer) erro
 		return fmt.Errorf("cannot re-encode api.Respose object: %v", err)
 	}
 	stdout.Write(buffer.Bytes())
-	return nil
+	return verbErr
 }
 
 func wait(ctx context.Context, jobID types.JobID, jobWaitPoll time.Duration, requestor string, transport transport.Transport) (*api.StatusResponse, error) {
@@ -190,6 +279,43 @@ func parseJob(jobIDStr string) (types.JobID, error) {
 	return jobID, nil
 }
 
+// readJobDescriptor reads a job descriptor from the specified file, or from
+// stdin if no file is specified, and returns it as JSON with the version field
+// set.
+func readJobDescriptor(fileName string) ([]byte, error) {
+	var jobDesc []byte
+	if fileName == "" {
+		fmt.Fprintf(os.Stderr, "Reading from stdin...\n")
+		jd, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job descriptor: %w", err)
+		}
+		jobDesc = jd
+	} else {
+		jd, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job descriptor: %w", err)
+		}
+		jobDesc = jd
+	}
+
+	jobDescFormat := config.JobDescFormatJSON
+	if *flagYAML {
+		jobDescFormat = config.JobDescFormatYAML
+	}
+	jobDescJSON, err := config.ParseJobDescriptor(jobDesc, jobDescFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse job descriptor: %w", err)
+	}
+
+	// Add the version field if it does not exist
+	jobDescJSON, err = addVersion(jobDescJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add version to descriptor: %w", err)
+	}
+	return jobDescJSON, nil
+}
+
 // addVersion adds the version field to the job descriptor if it does not exist
 func addVersion(jobDescJSON []byte) ([]byte, error) {
 	var (
@@ -209,3 +335,21 @@ func addVersion(jobDescJSON []byte) ([]byte, error) {
 	}
 	return jobDescJSON, nil
 }
+
+// writeJobArchive writes the JSON encoded job archive gzipped to path.
+func writeJobArchive(path string, archive []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(archive); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -19,6 +19,9 @@ import (
 // Start a job with the provided job description from a JSON file
 //   ./contestcli start start.json
 //
+// Validate a job description without starting a job
+//   ./contestcli validate start.json
+//
 // Get the status of a job whose ID is 10
 //   ./contestcli status 10
 //
@@ -28,6 +28,7 @@ import (
 	uri "github.com/linuxboot/contest/plugins/testfetchers/uri"
 
 	// the teststep plugins
+	barrier "github.com/linuxboot/contest/plugins/teststeps/barrier"
 	ts_cmd "github.com/linuxboot/contest/plugins/teststeps/cmd"
 	cpucmd "github.com/linuxboot/contest/plugins/teststeps/cpucmd"
 	echo "github.com/linuxboot/contest/plugins/teststeps/echo"
@@ -48,6 +49,7 @@ func getPluginConfig() *server.PluginConfig {
 	pc.TargetManagerLoaders = append(pc.TargetManagerLoaders, targetlist.Load)
 	pc.TestFetcherLoaders = append(pc.TestFetcherLoaders, literal.Load)
 	pc.TestFetcherLoaders = append(pc.TestFetcherLoaders, uri.Load)
+	pc.TestStepLoaders = append(pc.TestStepLoaders, barrier.Load)
 	pc.TestStepLoaders = append(pc.TestStepLoaders, ts_cmd.Load)
 	pc.TestStepLoaders = append(pc.TestStepLoaders, cpucmd.Load)
 	pc.TestStepLoaders = append(pc.TestStepLoaders, echo.Load)
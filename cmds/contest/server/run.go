@@ -0,0 +1,233 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/config"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/jobmanager"
+	"github.com/linuxboot/contest/pkg/pluginregistry"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/listeners/noop"
+	"github.com/linuxboot/contest/plugins/storage/memory"
+	"github.com/linuxboot/contest/plugins/targetlocker/inmemory"
+)
+
+const (
+	localRequestor  = "contest-run"
+	localStatusPoll = time.Second
+)
+
+// runLocal executes the job described in descriptorFile in-process, without
+// API listener and database: the JobManager is wired with in-memory storage
+// and target locker, and exactly one job is run. Progress is printed to stdout
+// while the job runs, followed by the job reports. An error is returned if the
+// job did not complete successfully.
+func runLocal(ctx xcontext.Context, pluginRegistry *pluginregistry.PluginRegistry, clk clock.Clock, descriptorFile string, stdout io.Writer) error {
+	jobDescJSON, err := readLocalJobDescriptor(descriptorFile)
+	if err != nil {
+		return err
+	}
+
+	storageEngineVault := storage.NewSimpleEngineVault()
+	ms, err := memory.New()
+	if err != nil {
+		return fmt.Errorf("could not create storage: %w", err)
+	}
+	defer ms.Close()
+	if err := storageEngineVault.StoreEngine(ms, storage.SyncEngine); err != nil {
+		return fmt.Errorf("could not set storage: %w", err)
+	}
+	if err := storageEngineVault.StoreEngine(ms, storage.AsyncEngine); err != nil {
+		return fmt.Errorf("could not set replica storage: %w", err)
+	}
+
+	target.SetLocker(inmemory.New(clk))
+	defer target.SetLocker(nil)
+
+	var opts []jobmanager.Option
+	if *flagServerID != "" {
+		opts = append(opts, jobmanager.APIOption(api.OptionServerID(*flagServerID)))
+	}
+	if *flagTargetLockDuration != 0 {
+		opts = append(opts, jobmanager.OptionTargetLockDuration(*flagTargetLockDuration))
+	}
+	jm, err := jobmanager.New(noop.New(), pluginRegistry, storageEngineVault, opts...)
+	if err != nil {
+		return err
+	}
+
+	runCtx, runCancel := xcontext.WithCancel(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- jm.Run(runCtx, false)
+	}()
+	// Stop the JobManager once done. It waits for the job to wind down before
+	// returning, the job is canceled if ctx is.
+	defer func() {
+		runCancel()
+		if err := <-errCh; err != nil {
+			ctx.Errorf("JobManager failed: %v", err)
+		}
+	}()
+
+	select {
+	case <-jm.Started():
+	case err := <-errCh:
+		errCh <- err
+		return fmt.Errorf("JobManager failed to start: %w", err)
+	}
+	jobID, err := jm.StartJob(ctx, localRequestor, string(jobDescJSON))
+	if err != nil {
+		return fmt.Errorf("could not start job: %w", err)
+	}
+	fmt.Fprintf(stdout, "Started job %d\n", jobID)
+
+	status, err := waitLocalJob(ctx, jm, jobID, stdout)
+	if err != nil {
+		return err
+	}
+
+	if status.JobReport != nil {
+		buffer := &bytes.Buffer{}
+		encoder := json.NewEncoder(buffer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(status.JobReport); err != nil {
+			return fmt.Errorf("cannot encode job report: %w", err)
+		}
+		fmt.Fprintf(stdout, "Job report:\n%s", buffer.String())
+	}
+
+	if status.State != string(job.EventJobCompleted) {
+		if status.StateErrMsg != "" {
+			return fmt.Errorf("job %d finished in state %s: %s", jobID, status.State, status.StateErrMsg)
+		}
+		return fmt.Errorf("job %d finished in state %s", jobID, status.State)
+	}
+	if status.JobReport != nil {
+		for _, reports := range status.JobReport.RunReports {
+			for _, report := range reports {
+				if !report.Success {
+					return fmt.Errorf("job %d: run %d reported failure by %s", jobID, report.RunID, report.ReporterName)
+				}
+			}
+		}
+		for _, report := range status.JobReport.FinalReports {
+			if !report.Success {
+				return fmt.Errorf("job %d: final report failure by %s", jobID, report.ReporterName)
+			}
+		}
+	}
+	return nil
+}
+
+// waitLocalJob polls the status of the job until it reaches a completion
+// state, printing the progress of targets through the steps as it goes.
+func waitLocalJob(ctx xcontext.Context, jm *jobmanager.JobManager, jobID types.JobID, stdout io.Writer) (*job.Status, error) {
+	var (
+		lastState string
+		printed   = make(map[string]bool)
+		doneCh    = ctx.Done()
+	)
+	for {
+		status, err := jm.JobStatus(ctx, jobID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get job status: %w", err)
+		}
+		for _, line := range progressLines(status) {
+			if !printed[line] {
+				printed[line] = true
+				fmt.Fprintln(stdout, line)
+			}
+		}
+		if status.State != lastState {
+			lastState = status.State
+			fmt.Fprintf(stdout, "Job %d: %s\n", jobID, status.State)
+		}
+		for _, eventName := range job.JobCompletionEvents {
+			if status.State == string(eventName) {
+				return status, nil
+			}
+		}
+		select {
+		case <-doneCh:
+			// The job is being canceled along with ctx, keep polling until
+			// it reaches its final state.
+			doneCh = nil
+		case <-time.After(localStatusPoll):
+		}
+	}
+}
+
+// progressLines describes the targets' progress through the test steps, one
+// line per target transition.
+func progressLines(status *job.Status) []string {
+	var lines []string
+	for _, runStatus := range status.RunStatuses {
+		for _, testStatus := range runStatus.TestStatuses {
+			for _, stepStatus := range testStatus.TestStepStatuses {
+				prefix := fmt.Sprintf("[run %d] %s/%s:", runStatus.RunID, testStatus.TestName, stepStatus.TestStepLabel)
+				for _, targetStatus := range stepStatus.TargetStatuses {
+					if !targetStatus.InTime.IsZero() {
+						lines = append(lines, fmt.Sprintf("%s target %s in", prefix, targetStatus.Target.ID))
+					}
+					if targetStatus.OutTime.IsZero() {
+						continue
+					}
+					if targetStatus.Error != "" {
+						lines = append(lines, fmt.Sprintf("%s target %s failed: %s", prefix, targetStatus.Target.ID, targetStatus.Error))
+					} else {
+						lines = append(lines, fmt.Sprintf("%s target %s out", prefix, targetStatus.Target.ID))
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// readLocalJobDescriptor reads a JSON or YAML (depending on the file extension)
+// job descriptor, and returns it as JSON. The current descriptor version is
+// set if the descriptor does not specify one.
+func readLocalJobDescriptor(fileName string) ([]byte, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job descriptor: %w", err)
+	}
+	jobDescFormat := config.JobDescFormatJSON
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		jobDescFormat = config.JobDescFormatYAML
+	}
+	jobDescJSON, err := config.ParseJobDescriptor(data, jobDescFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse job descriptor: %w", err)
+	}
+	jobDesc := make(map[string]interface{})
+	if err := json.Unmarshal(jobDescJSON, &jobDesc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON job descriptor: %w", err)
+	}
+	if _, ok := jobDesc["Version"]; !ok {
+		jobDesc["Version"] = job.CurrentDescriptorVersion()
+	}
+	return json.MarshalIndent(jobDesc, "", "    ")
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/pluginregistry"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/reporters/targetsuccess"
+	"github.com/linuxboot/contest/plugins/targetmanagers/targetlist"
+	"github.com/linuxboot/contest/plugins/testfetchers/literal"
+	"github.com/linuxboot/contest/tests/plugins/teststeps/fail"
+	"github.com/linuxboot/contest/tests/plugins/teststeps/noop"
+)
+
+const localDescriptor = `
+JobName: local job
+Runs: 1
+TestDescriptors:
+    - TargetManagerName: TargetList
+      TargetManagerAcquireParameters:
+        Targets:
+          - ID: T1
+      TargetManagerReleaseParameters:
+      TestFetcherName: Literal
+      TestFetcherFetchParameters:
+          TestName: local test
+          Steps:
+              - name: Noop
+                label: step1
+                parameters: {}
+              - name: %s
+                label: step2
+                parameters: {}
+Reporting:
+    RunReporters:
+        - name: TargetSuccess
+          parameters:
+              SuccessExpression: ">=100%%"
+`
+
+func newLocalRegistry(t *testing.T) *pluginregistry.PluginRegistry {
+	pr := pluginregistry.NewPluginRegistry(xcontext.Background())
+	require.NoError(t, pr.RegisterTargetManager(targetlist.Load()))
+	require.NoError(t, pr.RegisterTestFetcher(literal.Load()))
+	require.NoError(t, pr.RegisterTestStep(noop.Name, noop.New, noop.Events))
+	require.NoError(t, pr.RegisterTestStep(fail.Name, fail.New, fail.Events))
+	require.NoError(t, pr.RegisterReporter(targetsuccess.Load()))
+	return pr
+}
+
+func runLocalDescriptor(t *testing.T, lastStep string) (string, error) {
+	initFlags("contest")
+	fileName := filepath.Join(t.TempDir(), "job.yaml")
+	require.NoError(t, os.WriteFile(fileName, []byte(fmt.Sprintf(localDescriptor, lastStep)), 0644))
+
+	var stdout bytes.Buffer
+	err := runLocal(xcontext.Background(), newLocalRegistry(t), clock.New(), fileName, &stdout)
+	return stdout.String(), err
+}
+
+func TestRunLocal(t *testing.T) {
+	out, err := runLocalDescriptor(t, "Noop")
+	require.NoError(t, err, out)
+	require.Contains(t, out, "Started job 1\n")
+	require.Contains(t, out, "[run 1] local test/step1: target T1 in\n")
+	require.Contains(t, out, "[run 1] local test/step2: target T1 out\n")
+	require.Contains(t, out, "Job 1: JobStateCompleted\n")
+	require.Contains(t, out, "Job report:\n")
+}
+
+func TestRunLocalFailure(t *testing.T) {
+	out, err := runLocalDescriptor(t, "Fail")
+	require.Error(t, err, out)
+	require.Contains(t, err.Error(), "run 1 reported failure by TargetSuccess")
+	require.Contains(t, out, "[run 1] local test/step1: target T1 out\n")
+	require.Contains(t, out, "[run 1] local test/step2: target T1 failed: ")
+	require.Contains(t, out, "Job 1: JobStateCompleted\n")
+}
+
+func TestRunLocalInvalidDescriptor(t *testing.T) {
+	out, err := runLocalDescriptor(t, "Unknown")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "could not start job")
+	require.NotContains(t, out, "Started job")
+}
+
+func TestProgressLines(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := &job.Status{
+		RunStatuses: []job.RunStatus{{
+			RunCoordinates: job.RunCoordinates{RunID: types.RunID(2)},
+			TestStatuses: []job.TestStatus{{
+				TestCoordinates: job.TestCoordinates{TestName: "test"},
+				TestStepStatuses: []job.TestStepStatus{{
+					TestStepCoordinates: job.TestStepCoordinates{TestStepLabel: "step"},
+					TargetStatuses: []job.TargetStatus{
+						{Target: &target.Target{ID: "T1"}, InTime: start, OutTime: start.Add(time.Second)},
+						{Target: &target.Target{ID: "T2"}, InTime: start, OutTime: start.Add(time.Second), Error: "boom"},
+						{Target: &target.Target{ID: "T3"}, InTime: start},
+					},
+				}},
+			}},
+		}},
+	}
+	require.Equal(t, []string{
+		"[run 2] test/step: target T1 in",
+		"[run 2] test/step: target T1 out",
+		"[run 2] test/step: target T2 in",
+		"[run 2] test/step: target T2 failed: boom",
+		"[run 2] test/step: target T3 in",
+	}, progressLines(status))
+}
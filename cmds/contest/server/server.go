@@ -33,6 +33,7 @@ import (
 	"github.com/linuxboot/contest/pkg/xcontext/logger"
 	"github.com/linuxboot/contest/plugins/storage/memory"
 	"github.com/linuxboot/contest/plugins/storage/rdbms"
+	"github.com/linuxboot/contest/plugins/storage/sqlite"
 	"github.com/linuxboot/contest/plugins/targetlocker/dblocker"
 	"github.com/linuxboot/contest/plugins/targetlocker/inmemory"
 
@@ -52,6 +53,11 @@ var (
 	flagPauseTimeout       *time.Duration
 	flagResumeJobs         *bool
 	flagTargetLockDuration *time.Duration
+	flagHeartbeatInterval  *time.Duration
+	flagDeadServerTimeout  *time.Duration
+	flagRetentionPolicy    *string
+	flagRetentionInterval  *time.Duration
+	flagArchiveDir         *string
 	// http logger parameters
 	flagAdminServerAddr         *string
 	flagHttpLoggerBufferSize    *int
@@ -63,7 +69,7 @@ var (
 
 func initFlags(cmd string) {
 	flagSet = flag.NewFlagSet(cmd, flag.ContinueOnError)
-	flagDBURI = flagSet.String("dbURI", config.DefaultDBURI, "Database URI")
+	flagDBURI = flagSet.String("dbURI", config.DefaultDBURI, "Database URI, a MySQL DSN, a postgres:// URI or sqlite:///path/to/file.db")
 	flagListenAddr = flagSet.String("listenAddr", ":8080", "Listen address and port")
 	flagAdminServerAddr = flagSet.String("adminServerAddr", "", "Addr of the admin server to connect to")
 	flagHttpLoggerBufferSize = flagSet.Int("loggerBufferSize", loggerhook.DefaultBufferSize, "buffer size for the http logger hook")
@@ -81,6 +87,32 @@ func initFlags(cmd string) {
 	flagTargetLockDuration = flagSet.Duration("targetLockDuration", config.DefaultTargetLockDuration,
 		"The amount of time target lock is extended by while the job is running. "+
 			"This is the maximum amount of time a job can stay paused safely.")
+	flagHeartbeatInterval = flagSet.Duration("heartbeatInterval", 0,
+		"Interval at which the server records its liveness in storage and looks for dead servers with the same instance tag to take over their jobs; 0 - disabled")
+	flagDeadServerTimeout = flagSet.Duration("deadServerTimeout", config.DefaultDeadServerTimeout,
+		"The amount of time without heartbeat after which a server is considered dead and its jobs are taken over. "+
+			"Should be shorter than targetLockDuration, so that locks of paused jobs do not expire before takeover.")
+	flagRetentionPolicy = flagSet.String("retentionPolicy", "",
+		"Comma-separated retentions of finished jobs, either a duration for all jobs or tag=duration for jobs with the tag, e.g. \"720h,nightly=168h,release=0\". "+
+			"A job with several tags is kept for the longest retention; 0 - keep forever. Expired jobs are purged from storage.")
+	flagRetentionInterval = flagSet.Duration("retentionInterval", config.DefaultRetentionInterval,
+		"Interval at which the retention policy is enforced")
+	flagArchiveDir = flagSet.String("archiveDir", "",
+		"Directory where jobs are archived as compressed JSON before being purged; if unset, expired jobs are not archived")
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(),
+			`Usage:
+
+  %[1]s [flags]
+        run the ConTest server
+  %[1]s [flags] run descriptor.json
+        run a single job in-process, without API listener and database,
+        and exit non-zero if the job does not complete successfully
+
+Flags:
+`, cmd)
+		flagSet.PrintDefaults()
+	}
 }
 
 var userFunctions = []map[string]interface{}{
@@ -192,6 +224,22 @@ func Main(pluginConfig *PluginConfig, cmd string, args []string, sigs <-chan os.
 		return fmt.Errorf("failed to register plugins: %w", err)
 	}
 
+	// single-shot mode, see runLocal.
+	if flagSet.NArg() > 0 {
+		if flagSet.Arg(0) != "run" || flagSet.NArg() != 2 {
+			return fmt.Errorf("invalid arguments %q, expected: run <descriptor file>", flagSet.Args())
+		}
+		go func() {
+			for sig := range sigs {
+				if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+					log.Infof("Signal %q, canceling", sig)
+					cancel()
+				}
+			}
+		}()
+		return runLocal(ctx, pluginRegistry, clk, flagSet.Arg(1), os.Stdout)
+	}
+
 	var storageInstances []storage.Storage
 	defer func() {
 		for i, s := range storageInstances {
@@ -202,7 +250,27 @@ func Main(pluginConfig *PluginConfig, cmd string, args []string, sigs <-chan os.
 	}()
 
 	// primary storage initialization
-	if *flagDBURI != "" {
+	if sqlite.IsURI(*flagDBURI) {
+		log.Infof("Using SQLite database for storage: %s", *flagDBURI)
+		s, err := sqlite.New(*flagDBURI)
+		if err != nil {
+			log.Fatalf("Could not initialize database: %v", err)
+		}
+		storageInstances = append(storageInstances, s)
+		// There are no replicas of a local database, the same instance serves
+		// both engines.
+		if err := storageEngineVault.StoreEngine(s, storage.SyncEngine); err != nil {
+			log.Fatalf("Could not set storage: %v", err)
+		}
+		if err := storageEngineVault.StoreEngine(s, storage.AsyncEngine); err != nil {
+			log.Fatalf("Could not set replica storage: %v", err)
+		}
+		if dbVer, err := s.Version(); err != nil {
+			log.Warnf("Could not determine storage version: %v", err)
+		} else {
+			log.Infof("Storage version: %d", dbVer)
+		}
+	} else if *flagDBURI != "" {
 		primaryDBURI := *flagDBURI
 		log.Infof("Using database URI for primary storage: %s", primaryDBURI)
 		s, err := rdbms.New(primaryDBURI)
@@ -261,7 +329,9 @@ func Main(pluginConfig *PluginConfig, cmd string, args []string, sigs <-chan os.
 
 	// set Locker engine
 	if *flagTargetLocker == "auto" {
-		if *flagDBURI != "" {
+		// dblocker works with MySQL and PostgreSQL, a local SQLite database
+		// implies a single server, so the in-memory locker is enough.
+		if *flagDBURI != "" && !sqlite.IsURI(*flagDBURI) {
 			*flagTargetLocker = dblocker.Name
 		} else {
 			*flagTargetLocker = inmemory.Name
@@ -296,6 +366,23 @@ func Main(pluginConfig *PluginConfig, cmd string, args []string, sigs <-chan os.
 	if *flagTargetLockDuration != 0 {
 		opts = append(opts, jobmanager.OptionTargetLockDuration(*flagTargetLockDuration))
 	}
+	if *flagHeartbeatInterval != 0 {
+		opts = append(opts, jobmanager.OptionHeartbeatInterval(*flagHeartbeatInterval))
+	}
+	if *flagDeadServerTimeout != 0 {
+		opts = append(opts, jobmanager.OptionDeadServerTimeout(*flagDeadServerTimeout))
+	}
+	retentionPolicy, err := jobmanager.ParseRetentionPolicy(*flagRetentionPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid -retentionPolicy: %w", err)
+	}
+	opts = append(opts, jobmanager.OptionRetentionPolicy(retentionPolicy))
+	if *flagRetentionInterval != 0 {
+		opts = append(opts, jobmanager.OptionRetentionInterval(*flagRetentionInterval))
+	}
+	if *flagArchiveDir != "" {
+		opts = append(opts, jobmanager.OptionArchiveDir(*flagArchiveDir))
+	}
 
 	jm, err := jobmanager.New(listener, pluginRegistry, storageEngineVault, opts...)
 	if err != nil {
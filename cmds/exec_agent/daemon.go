@@ -0,0 +1,197 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/remote"
+)
+
+// daemonStartTimeout is how long to wait for a newly spawned daemon to accept connections
+const daemonStartTimeout = 5 * time.Second
+
+func runDaemon(verb string) error {
+	c := remote.NewDaemonClient(*flagSocket)
+
+	switch verb {
+	case "serve":
+		return serve()
+
+	case "start":
+		bin := flagSet.Arg(1)
+		if bin == "" {
+			return fmt.Errorf("missing binary argument, see --help")
+		}
+
+		var args []string
+		if flagSet.NArg() > 2 {
+			args = flagSet.Args()[2:]
+		}
+
+		if err := ensureDaemon(c); err != nil {
+			return err
+		}
+
+		sid, err := c.Start(bin, args, remote.SessionLimits{
+			TimeQuota: *flagTimeQuota,
+			MaxOutput: *flagMaxOutput,
+		})
+		if err != nil {
+			return err
+		}
+		return remote.SendResponse(&remote.StartMessage{SessionID: sid})
+
+	case "poll":
+		sid := flagSet.Arg(1)
+		if sid == "" {
+			return fmt.Errorf("missing session id, see --help")
+		}
+
+		var offsets [2]int64
+		for i := range offsets {
+			if arg := flagSet.Arg(2 + i); arg != "" {
+				var err error
+				if offsets[i], err = strconv.ParseInt(arg, 10, 64); err != nil {
+					return fmt.Errorf("failed to parse output offset: %w", err)
+				}
+			}
+		}
+
+		msg, err := c.Poll(sid, offsets[0], offsets[1])
+		if err != nil {
+			// connection errors also means that the daemon might have died
+			var e *remote.ErrCantConnect
+			if errors.As(err, &e) {
+				return remote.SendResponse(&remote.PollMessage{
+					Error: "agent daemon is dead",
+				})
+			}
+
+			return fmt.Errorf("failed to call daemon: %w", err)
+		}
+		return remote.SendResponse(msg)
+
+	case "kill":
+		return c.Kill(flagSet.Arg(1))
+
+	case "reap":
+		return c.Reap(flagSet.Arg(1))
+
+	case "list":
+		sessions, err := c.List()
+		if err != nil {
+			return err
+		}
+		if sessions == nil {
+			sessions = []remote.SessionInfo{}
+		}
+		return remote.SendResponse(&remote.ListMessage{Sessions: sessions})
+
+	default:
+		return fmt.Errorf("invalid verb: %s", verb)
+	}
+}
+
+// ensureDaemon spawns a detached daemon if none is accepting connections on the socket
+func ensureDaemon(c *remote.DaemonClient) error {
+	if _, err := c.List(); err == nil {
+		return nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find agent executable: %w", err)
+	}
+
+	cmd := exec.Command(self,
+		"--daemon",
+		fmt.Sprintf("--socket=%s", *flagSocket),
+		fmt.Sprintf("--spool-dir=%s", *flagSpoolDir),
+		fmt.Sprintf("--idle-timeout=%s", *flagIdleTimeout),
+		fmt.Sprintf("--debug=%t", *flagDebug),
+		"serve",
+	)
+	// detach the daemon from the session of the caller, eg. the ssh session
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	log.Printf("starting daemon: %s", cmd.String())
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	if err := cmd.Process.Release(); err != nil {
+		return fmt.Errorf("failed to release daemon: %w", err)
+	}
+
+	for start := time.Now(); time.Since(start) < daemonStartTimeout; time.Sleep(50 * time.Millisecond) {
+		if _, err = c.List(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("daemon did not start: %w", err)
+}
+
+func serve() error {
+	// don't steal the socket of a running daemon
+	if _, err := remote.NewDaemonClient(*flagSocket).List(); err == nil {
+		log.Printf("daemon already running at: %s", *flagSocket)
+		return nil
+	}
+
+	d, err := remote.NewDaemon(*flagSpoolDir)
+	if err != nil {
+		return err
+	}
+
+	server := remote.NewDaemonServer(d, *flagSocket)
+	serverDone := make(chan error, 1)
+	go func() {
+		serverDone <- server.Serve()
+	}()
+
+	// catch termination signals
+	sigs := make(chan os.Signal, 1)
+	defer close(sigs)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	// the spooled outputs of abandoned sessions are released periodically
+	expire := time.NewTicker(time.Minute)
+	defer expire.Stop()
+
+	for {
+		select {
+		case err := <-serverDone:
+			return err
+
+		case sig := <-sigs:
+			if err := server.Shutdown(); err != nil {
+				log.Printf("failed to shutdown daemon server: %v", err)
+			}
+			for _, s := range d.List() {
+				if err := d.Kill(s.SessionID); err != nil {
+					log.Printf("failed to kill process of session %s: %v", s.SessionID, err)
+				}
+			}
+			return fmt.Errorf("signal caught: %v", sig)
+
+		case <-expire.C:
+			d.Expire(*flagIdleTimeout)
+
+			if d.Idle() > *flagIdleTimeout {
+				log.Printf("no sessions for %s, exiting", *flagIdleTimeout)
+				return server.Shutdown()
+			}
+		}
+	}
+}
@@ -13,18 +13,30 @@ import (
 	"path"
 	"syscall"
 	"time"
+
+	"github.com/linuxboot/contest/pkg/remote"
 )
 
 var (
-	flagSet       *flag.FlagSet
-	flagTimeQuota *time.Duration
-	flagDebug     *bool
+	flagSet         *flag.FlagSet
+	flagTimeQuota   *time.Duration
+	flagDebug       *bool
+	flagDaemon      *bool
+	flagSocket      *string
+	flagSpoolDir    *string
+	flagMaxOutput   *int64
+	flagIdleTimeout *time.Duration
 )
 
 func initFlags(cmd string) {
 	flagSet = flag.NewFlagSet(cmd, flag.ContinueOnError)
 	flagTimeQuota = flagSet.Duration("time-quota", 0, "Time quota until the process self-destructs; 0 means infinite")
 	flagDebug = flagSet.Bool("debug", false, "Output logs and errors in foreground, otherwise close stderr")
+	flagDaemon = flagSet.Bool("daemon", false, "Run the processes in sessions of a persistent daemon, which is started if needed")
+	flagSocket = flagSet.String("socket", remote.DefaultDaemonSocket(), "Unix socket of the daemon")
+	flagSpoolDir = flagSet.String("spool-dir", remote.DefaultSpoolDir(), "Directory where the daemon spools the process outputs")
+	flagMaxOutput = flagSet.Int64("max-output", 0, "Maximum bytes spooled per output stream of a daemon session, the rest is discarded; 0 means no limit")
+	flagIdleTimeout = flagSet.Duration("idle-timeout", time.Hour, "Time after which the daemon reaps exited sessions that are not polled, and exits when it has no sessions")
 
 	flagSet.Usage = func() {
 		fmt.Fprintf(flagSet.Output(),
@@ -35,6 +47,16 @@ func initFlags(cmd string) {
 Commands:
   start /path/to/binary <args>
         start a new binary and detach from the controlling TTY if any
+  poll <sid> [stdout_offset stderr_offset]
+        get the new output and the exit code of a process; offsets are only used in daemon mode
+  kill <sid>
+        kill a process
+  reap <sid>
+        release the resources of a process after it exited
+  list
+        list the daemon sessions (daemon mode only)
+  serve
+        run the daemon in the foreground (daemon mode only)
 
 Flags:
 `, path.Base(cmd))
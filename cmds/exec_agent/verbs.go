@@ -26,6 +26,10 @@ func run() error {
 		return fmt.Errorf("missing verb, see --help")
 	}
 
+	if *flagDaemon {
+		return runDaemon(verb)
+	}
+
 	switch verb {
 	case "start":
 		bin := flagSet.Arg(1)
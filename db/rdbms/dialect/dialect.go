@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package dialect abstracts the differences between the SQL databases
+// supported by the rdbms storage engine, the DBLocker and the migrations.
+// Queries are written once, with "?" placeholders and the SQL subset which
+// is common to all the dialects, and adapted to the actual database here.
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	// importing the drivers also registers them
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Dialect is a supported SQL dialect.
+type Dialect int
+
+// Supported dialects.
+const (
+	MySQL Dialect = iota
+	PostgreSQL
+)
+
+// String returns the name of the dialect, which is also the name of the
+// goose dialect.
+func (d Dialect) String() string {
+	switch d {
+	case MySQL:
+		return "mysql"
+	case PostgreSQL:
+		return "postgres"
+	}
+	return fmt.Sprintf("unknown dialect %d", int(d))
+}
+
+// DriverName returns the name of the database/sql driver for the dialect.
+func (d Dialect) DriverName() string {
+	return d.String()
+}
+
+// FromURI returns the dialect of a database URI. PostgreSQL URIs have the
+// postgres:// or postgresql:// scheme, anything else is a MySQL DSN.
+func FromURI(dbURI string) Dialect {
+	if strings.HasPrefix(dbURI, "postgres://") || strings.HasPrefix(dbURI, "postgresql://") {
+		return PostgreSQL
+	}
+	return MySQL
+}
+
+// FromDriverName returns the dialect of a database/sql driver. Unknown
+// drivers are assumed to be MySQL compatible.
+func FromDriverName(driverName string) Dialect {
+	if driverName == PostgreSQL.DriverName() {
+		return PostgreSQL
+	}
+	return MySQL
+}
+
+// Rebind converts the "?" placeholders of a query into the ones of the
+// dialect. Queries must not contain literal question marks.
+func (d Dialect) Rebind(query string) string {
+	if d != PostgreSQL || !strings.Contains(query, "?") {
+		return query
+	}
+	var (
+		sb strings.Builder
+		n  int
+	)
+	sb.Grow(len(query) + 8)
+	for _, c := range query {
+		if c != '?' {
+			sb.WriteRune(c)
+			continue
+		}
+		n++
+		sb.WriteByte('$')
+		sb.WriteString(strconv.Itoa(n))
+	}
+	return sb.String()
+}
+
+// InsertIgnore returns an insert statement prefix which silently skips the
+// rows conflicting with existing ones. The conflict clause returned by
+// OnConflictIgnore must be appended to the statement.
+func (d Dialect) InsertIgnore() string {
+	if d == PostgreSQL {
+		return "INSERT INTO"
+	}
+	return "INSERT IGNORE INTO"
+}
+
+// OnConflictIgnore returns the clause to append to a statement started with
+// InsertIgnore.
+func (d Dialect) OnConflictIgnore() string {
+	if d == PostgreSQL {
+		return " ON CONFLICT DO NOTHING"
+	}
+	return ""
+}
+
+// IsUndefinedTable returns whether err reports a missing table.
+func IsUndefinedTable(err error) bool {
+	switch err := err.(type) {
+	case *mysql.MySQLError:
+		return err.Number == 1146
+	case *pq.Error:
+		return err.Code == "42P01"
+	}
+	return false
+}
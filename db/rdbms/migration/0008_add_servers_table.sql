@@ -0,0 +1,20 @@
+-- Copyright (c) Facebook, Inc. and its affiliates.
+--
+-- This source code is licensed under the MIT license found in the
+-- LICENSE file in the root directory of this source tree.
+
+-- +goose Up
+
+CREATE TABLE servers (
+	server_id VARCHAR(64) NOT NULL,
+	instance_tag VARCHAR(32) NOT NULL DEFAULT '',
+	heartbeat_time TIMESTAMP NOT NULL,
+	lease_holder VARCHAR(64) NULL,
+	lease_expires_at TIMESTAMP NULL,
+	PRIMARY KEY (server_id),
+	KEY (instance_tag)
+);
+
+-- +goose Down
+
+DROP TABLE servers;
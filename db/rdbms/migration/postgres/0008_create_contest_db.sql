@@ -0,0 +1,101 @@
+-- Copyright (c) Facebook, Inc. and its affiliates.
+--
+-- This source code is licensed under the MIT license found in the
+-- LICENSE file in the root directory of this source tree.
+
+-- +goose Up
+
+CREATE TABLE test_events (
+	event_id BIGSERIAL PRIMARY KEY,
+	job_id BIGINT NOT NULL,
+	run_id BIGINT NOT NULL,
+	test_name VARCHAR(32) NULL,
+	test_attempt INTEGER NOT NULL DEFAULT 0,
+	test_step_label VARCHAR(32) NULL,
+	event_name VARCHAR(32) NULL,
+	target_name VARCHAR(64) NULL,
+	target_id VARCHAR(64) NULL,
+	payload TEXT NULL,
+	emit_time TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX test_events_fetch_index_0 ON test_events (job_id, run_id, test_name);
+
+CREATE TABLE framework_events (
+	event_id BIGSERIAL PRIMARY KEY,
+	job_id BIGINT NOT NULL,
+	event_name VARCHAR(32) NULL,
+	payload TEXT NULL,
+	emit_time TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX framework_events_fetch_index_0 ON framework_events (job_id, event_name);
+
+CREATE TABLE run_reports (
+	report_id BIGSERIAL PRIMARY KEY,
+	job_id BIGINT NOT NULL,
+	run_id BIGINT NOT NULL,
+	reporter_name VARCHAR(32) NOT NULL,
+	success BOOLEAN NULL,
+	report_time TIMESTAMPTZ NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX run_reports_job_id_idx ON run_reports (job_id);
+
+CREATE TABLE final_reports (
+	report_id BIGSERIAL PRIMARY KEY,
+	job_id BIGINT NOT NULL,
+	success BOOLEAN NULL,
+	reporter_name VARCHAR(32) NOT NULL,
+	report_time TIMESTAMPTZ NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX final_reports_job_id_idx ON final_reports (job_id);
+
+CREATE TABLE jobs (
+	job_id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL,
+	requestor VARCHAR(32) NOT NULL,
+	server_id VARCHAR(64) NOT NULL,
+	request_time TIMESTAMPTZ NOT NULL,
+	descriptor TEXT NOT NULL,
+	teststeps TEXT,
+	extended_descriptor TEXT,
+	state SMALLINT DEFAULT 0
+);
+CREATE INDEX job_state ON jobs (state, job_id);
+
+CREATE TABLE job_tags (
+	job_id BIGINT NOT NULL,
+	tag VARCHAR(32),
+	PRIMARY KEY (job_id, tag)
+);
+CREATE INDEX job_tags_tag ON job_tags (tag);
+
+CREATE TABLE locks (
+	target_id VARCHAR(64) NOT NULL,
+	job_id BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	valid BOOLEAN NOT NULL DEFAULT TRUE,
+	PRIMARY KEY (target_id)
+);
+
+CREATE TABLE servers (
+	server_id VARCHAR(64) NOT NULL,
+	instance_tag VARCHAR(32) NOT NULL DEFAULT '',
+	heartbeat_time TIMESTAMPTZ NOT NULL,
+	lease_holder VARCHAR(64) NULL,
+	lease_expires_at TIMESTAMPTZ NULL,
+	PRIMARY KEY (server_id)
+);
+CREATE INDEX servers_instance_tag ON servers (instance_tag);
+
+-- +goose Down
+
+DROP TABLE servers;
+DROP TABLE locks;
+DROP TABLE job_tags;
+DROP TABLE jobs;
+DROP TABLE final_reports;
+DROP TABLE run_reports;
+DROP TABLE framework_events;
+DROP TABLE test_events;
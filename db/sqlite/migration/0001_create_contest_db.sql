@@ -0,0 +1,77 @@
+-- Copyright (c) Facebook, Inc. and its affiliates.
+--
+-- This source code is licensed under the MIT license found in the
+-- LICENSE file in the root directory of this source tree.
+
+CREATE TABLE jobs (
+	job_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	requestor TEXT NOT NULL,
+	server_id TEXT NOT NULL,
+	request_time TIMESTAMP NOT NULL,
+	descriptor TEXT NOT NULL,
+	extended_descriptor TEXT,
+	state INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX jobs_state ON jobs (state, job_id);
+CREATE INDEX jobs_server_id ON jobs (server_id);
+
+CREATE TABLE job_tags (
+	job_id INTEGER NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (job_id, tag)
+);
+CREATE INDEX job_tags_tag ON job_tags (tag);
+
+CREATE TABLE run_reports (
+	report_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INTEGER NOT NULL,
+	run_id INTEGER NOT NULL,
+	reporter_name TEXT NOT NULL,
+	success BOOLEAN NULL,
+	report_time TIMESTAMP NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX run_reports_job_id ON run_reports (job_id);
+
+CREATE TABLE final_reports (
+	report_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INTEGER NOT NULL,
+	reporter_name TEXT NOT NULL,
+	success BOOLEAN NULL,
+	report_time TIMESTAMP NOT NULL,
+	data TEXT NOT NULL
+);
+CREATE INDEX final_reports_job_id ON final_reports (job_id);
+
+CREATE TABLE test_events (
+	event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INTEGER NOT NULL,
+	run_id INTEGER NOT NULL,
+	test_name TEXT NULL,
+	test_attempt INTEGER NOT NULL DEFAULT 0,
+	test_step_label TEXT NULL,
+	event_name TEXT NULL,
+	target_id TEXT NULL,
+	payload TEXT NULL,
+	emit_time TIMESTAMP NOT NULL
+);
+CREATE INDEX test_events_job_id ON test_events (job_id, run_id, test_name);
+
+CREATE TABLE framework_events (
+	event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INTEGER NOT NULL,
+	event_name TEXT NULL,
+	payload TEXT NULL,
+	emit_time TIMESTAMP NOT NULL
+);
+CREATE INDEX framework_events_job_id ON framework_events (job_id, event_name);
+
+CREATE TABLE servers (
+	server_id TEXT PRIMARY KEY,
+	instance_tag TEXT NOT NULL DEFAULT '',
+	heartbeat_time TIMESTAMP NOT NULL,
+	lease_holder TEXT NULL,
+	lease_expires_at TIMESTAMP NULL
+);
+CREATE INDEX servers_instance_tag ON servers (instance_tag);
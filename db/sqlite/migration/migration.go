@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package migration contains the schema migrations of the SQLite storage
+// engine. Migrations are embedded in the binary and applied when the database
+// is opened, the current schema version is kept in the user_version pragma.
+package migration
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration is a single schema migration.
+type Migration struct {
+	Version uint64
+	Name    string
+	SQL     string
+}
+
+// Migrations returns all the available migrations sorted by version.
+func Migrations() ([]Migration, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var res []Migration
+	for _, name := range names {
+		idx := strings.Index(name, "_")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.ParseUint(name[:idx], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q: %w", name, err)
+		}
+		data, err := files.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %q: %w", name, err)
+		}
+		res = append(res, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	for i := 1; i < len(res); i++ {
+		if res[i].Version == res[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", res[i].Version)
+		}
+	}
+	return res, nil
+}
+
+// Version returns the current schema version of the database.
+func Version(db *sql.DB) (uint64, error) {
+	var version uint64
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("could not retrieve db version: %w", err)
+	}
+	return version, nil
+}
+
+// Up applies all the migrations newer than the current schema version.
+func Up(db *sql.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	current, err := Version(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("could not apply migration %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+func apply(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(m.SQL); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	// Pragmas do not accept bind parameters, version is a number.
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.Version)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
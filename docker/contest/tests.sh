@@ -26,8 +26,8 @@ for d in $(go list ./... | grep -v vendor); do
     i=$(($i + 1))
 done
 
-# MySQL should be up by now.
-if ! mysqladmin -h dbstorage -P 3306 -u contest --protocol tcp --password=contest ping; then
+# MySQL should be up by now. PostgreSQL readiness is checked by docker-compose.
+if [ "${CONTEST_DB_DIALECT:-mysql}" = "mysql" ] && ! mysqladmin -h dbstorage -P 3306 -u contest --protocol tcp --password=contest ping; then
     echo "MySQL is not ready!"
     exit 1
 fi
@@ -0,0 +1,8 @@
+-- Copyright (c) Facebook, Inc. and its affiliates.
+--
+-- This source code is licensed under the MIT license found in the
+-- LICENSE file in the root directory of this source tree.
+
+CREATE USER contest WITH PASSWORD 'contest';
+CREATE DATABASE contest OWNER contest;
+CREATE DATABASE contest_integ OWNER contest;
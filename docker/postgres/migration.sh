@@ -0,0 +1,13 @@
+#!/bin/bash
+#
+# Copyright (c) Facebook, Inc. and its affiliates.
+#
+# This source code is licensed under the MIT license found in the
+# LICENSE file in the root directory of this source tree.
+
+set -eo pipefail
+echo "Running database migrations..."
+cd /home/postgres/contest
+# Migrate main db and integration tests db
+go run tools/migration/rdbms/main.go -dbURI "postgres://contest:contest@/contest?host=/var/run/postgresql&sslmode=disable" -dir db/rdbms/migration/postgres up
+go run tools/migration/rdbms/main.go -dbURI "postgres://contest:contest@/contest_integ?host=/var/run/postgresql&sslmode=disable" -dir db/rdbms/migration/postgres up
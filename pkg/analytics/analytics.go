@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package analytics aggregates the test events of many jobs into statistics
+// per test step and per target, to find flaky steps and targets across a
+// fleet rather than within a single job.
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+// EventNames are the names of the test events used to compute statistics,
+// callers only need to fetch these.
+var EventNames = []event.Name{
+	target.EventTargetIn,
+	target.EventTargetOut,
+	target.EventTargetErr,
+}
+
+// Stats are the statistics of the executions of a test step or of a target.
+// An execution is a target going through a test step once. Executions of
+// retried tests count as retries.
+type Stats struct {
+	Executions  uint
+	Failures    uint
+	FailureRate float64
+	Retries     uint
+	// MeanDuration is the mean duration of the executions which completed.
+	MeanDuration time.Duration
+
+	totalDuration time.Duration
+	completed     uint
+}
+
+// StepStats are the statistics of a test step, identified by its label.
+type StepStats struct {
+	TestStepLabel string
+	Stats
+}
+
+// TargetStats are the statistics of a target, across all test steps.
+type TargetStats struct {
+	TargetID string
+	Stats
+}
+
+// Report contains the statistics of a set of jobs, sorted by decreasing
+// failure rate.
+type Report struct {
+	Jobs    uint
+	Steps   []StepStats
+	Targets []TargetStats
+}
+
+type executionKey struct {
+	jobID         types.JobID
+	runID         types.RunID
+	testName      string
+	testAttempt   uint32
+	testStepLabel string
+	targetID      string
+}
+
+type execution struct {
+	in, out time.Time
+	failed  bool
+}
+
+// Aggregator computes statistics from test events. Events of a job must be
+// added in the order they were emitted, but jobs can be added in any order,
+// so that the events of many jobs never need to be in memory at once.
+type Aggregator struct {
+	jobs    map[types.JobID]struct{}
+	steps   map[string]*Stats
+	targets map[string]*Stats
+}
+
+// NewAggregator creates an empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		jobs:    make(map[types.JobID]struct{}),
+		steps:   make(map[string]*Stats),
+		targets: make(map[string]*Stats),
+	}
+}
+
+// Add aggregates the given events. All the events of an execution must be
+// part of the same call. Events without a target are ignored.
+func (a *Aggregator) Add(events []testevent.Event) {
+	var keys []executionKey
+	executions := make(map[executionKey]*execution)
+	for _, ev := range events {
+		if ev.Header == nil || ev.Data == nil || ev.Data.Target == nil {
+			continue
+		}
+		a.jobs[ev.Header.JobID] = struct{}{}
+		key := executionKey{
+			jobID:         ev.Header.JobID,
+			runID:         ev.Header.RunID,
+			testName:      ev.Header.TestName,
+			testAttempt:   ev.Header.TestAttempt,
+			testStepLabel: ev.Header.TestStepLabel,
+			targetID:      ev.Data.Target.ID,
+		}
+		exec := executions[key]
+		if exec == nil {
+			exec = &execution{}
+			executions[key] = exec
+			keys = append(keys, key)
+		}
+		switch ev.Data.EventName {
+		case target.EventTargetIn:
+			exec.in = ev.EmitTime
+		case target.EventTargetOut:
+			exec.out = ev.EmitTime
+		case target.EventTargetErr:
+			exec.out = ev.EmitTime
+			exec.failed = true
+		}
+	}
+	for _, key := range keys {
+		exec := executions[key]
+		for _, stats := range []*Stats{
+			getStats(a.steps, key.testStepLabel),
+			getStats(a.targets, key.targetID),
+		} {
+			stats.add(key.testAttempt, exec)
+		}
+	}
+}
+
+func getStats(m map[string]*Stats, key string) *Stats {
+	stats := m[key]
+	if stats == nil {
+		stats = &Stats{}
+		m[key] = stats
+	}
+	return stats
+}
+
+func (s *Stats) add(testAttempt uint32, exec *execution) {
+	s.Executions++
+	if exec.failed {
+		s.Failures++
+	}
+	if testAttempt > 0 {
+		s.Retries++
+	}
+	if !exec.in.IsZero() && !exec.out.IsZero() && !exec.out.Before(exec.in) {
+		s.totalDuration += exec.out.Sub(exec.in)
+		s.completed++
+	}
+}
+
+func (s Stats) finalize() Stats {
+	if s.Executions > 0 {
+		s.FailureRate = float64(s.Failures) / float64(s.Executions)
+	}
+	if s.completed > 0 {
+		s.MeanDuration = s.totalDuration / time.Duration(s.completed)
+	}
+	return s
+}
+
+// Report returns the statistics of all the events added so far.
+func (a *Aggregator) Report() *Report {
+	report := &Report{Jobs: uint(len(a.jobs))}
+	for label, stats := range a.steps {
+		report.Steps = append(report.Steps, StepStats{TestStepLabel: label, Stats: stats.finalize()})
+	}
+	sort.Slice(report.Steps, func(i, j int) bool {
+		if report.Steps[i].FailureRate != report.Steps[j].FailureRate {
+			return report.Steps[i].FailureRate > report.Steps[j].FailureRate
+		}
+		return report.Steps[i].TestStepLabel < report.Steps[j].TestStepLabel
+	})
+	for id, stats := range a.targets {
+		report.Targets = append(report.Targets, TargetStats{TargetID: id, Stats: stats.finalize()})
+	}
+	sort.Slice(report.Targets, func(i, j int) bool {
+		if report.Targets[i].FailureRate != report.Targets[j].FailureRate {
+			return report.Targets[i].FailureRate > report.Targets[j].FailureRate
+		}
+		return report.Targets[i].TargetID < report.Targets[j].TargetID
+	})
+	return report
+}
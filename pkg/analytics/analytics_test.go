@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// executionEvents returns the events of a target going through a step,
+// failing if failed is true.
+func executionEvents(jobID types.JobID, attempt uint32, label, targetID string, duration time.Duration, failed bool) []testevent.Event {
+	header := &testevent.Header{JobID: jobID, RunID: 1, TestName: "test", TestAttempt: attempt, TestStepLabel: label}
+	tgt := &target.Target{ID: targetID}
+	outEvent := target.EventTargetOut
+	if failed {
+		outEvent = target.EventTargetErr
+	}
+	return []testevent.Event{
+		{Header: header, EmitTime: start, Data: &testevent.Data{EventName: target.EventTargetIn, Target: tgt}},
+		{Header: header, EmitTime: start, Data: &testevent.Data{EventName: event.Name("StepLog"), Target: tgt}},
+		{Header: header, EmitTime: start.Add(duration), Data: &testevent.Data{EventName: outEvent, Target: tgt}},
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	a := NewAggregator()
+	var job1 []testevent.Event
+	job1 = append(job1, executionEvents(1, 0, "flash", "t1", time.Minute, true)...)
+	job1 = append(job1, executionEvents(1, 0, "flash", "t2", 3*time.Minute, false)...)
+	job1 = append(job1, executionEvents(1, 1, "flash", "t1", 2*time.Minute, false)...)
+	job1 = append(job1, executionEvents(1, 0, "boot", "t2", time.Second, false)...)
+	a.Add(job1)
+	job2 := executionEvents(2, 0, "boot", "t1", 3*time.Second, true)
+	// A step without target is ignored.
+	job2 = append(job2, testevent.Event{
+		Header: &testevent.Header{JobID: 2, TestStepLabel: "boot"},
+		Data:   &testevent.Data{EventName: event.Name("StepLog")},
+	})
+	a.Add(job2)
+
+	report := a.Report()
+	require.Equal(t, uint(2), report.Jobs)
+	require.Len(t, report.Steps, 2)
+	require.Equal(t, "boot", report.Steps[0].TestStepLabel)
+	require.Equal(t, uint(2), report.Steps[0].Executions)
+	require.Equal(t, uint(1), report.Steps[0].Failures)
+	require.Equal(t, 0.5, report.Steps[0].FailureRate)
+	require.Equal(t, 2*time.Second, report.Steps[0].MeanDuration)
+
+	flash := report.Steps[1]
+	require.Equal(t, "flash", flash.TestStepLabel)
+	require.Equal(t, uint(3), flash.Executions)
+	require.Equal(t, uint(1), flash.Failures)
+	require.Equal(t, uint(1), flash.Retries)
+	require.InDelta(t, 1.0/3, flash.FailureRate, 1e-9)
+	require.Equal(t, 2*time.Minute, flash.MeanDuration)
+
+	require.Len(t, report.Targets, 2)
+	require.Equal(t, "t1", report.Targets[0].TargetID)
+	require.Equal(t, uint(3), report.Targets[0].Executions)
+	require.Equal(t, uint(2), report.Targets[0].Failures)
+	require.Equal(t, uint(1), report.Targets[0].Retries)
+	require.Equal(t, "t2", report.Targets[1].TargetID)
+	require.Zero(t, report.Targets[1].Failures)
+}
+
+func TestAggregatorEmpty(t *testing.T) {
+	report := NewAggregator().Report()
+	require.Zero(t, report.Jobs)
+	require.Empty(t, report.Steps)
+	require.Empty(t, report.Targets)
+}
@@ -209,9 +209,245 @@ func (a *API) Stop(ctx xcontext.Context, requestor EventRequestor, jobID types.J
 	return resp, nil
 }
 
+// Pause requests a running job to pause by the given job ID. The job keeps
+// its targets locked while paused, and can be resumed with Resume.
+func (a *API) Pause(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID) (Response, error) {
+	resp := a.newResponse(ResponseTypePause)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "pause"),
+		Type:     EventTypePause,
+		ServerID: resp.ServerID,
+		Msg: EventPauseMsg{
+			requestor: requestor,
+			JobID:     jobID,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataPause{}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Resume resumes a paused job by the given job ID. Only jobs paused by this
+// server can be resumed.
+func (a *API) Resume(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID) (Response, error) {
+	resp := a.newResponse(ResponseTypeResume)
+	ev := &Event{
+		// As with Start, the resumed job must outlive the request.
+		Context:  xcontext.WithResetSignalers(ctx).WithField("api_method", "resume"),
+		Type:     EventTypeResume,
+		ServerID: resp.ServerID,
+		Msg: EventResumeMsg{
+			requestor: requestor,
+			JobID:     jobID,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataResume{}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Locks lists the target locks, optionally restricted to the given target IDs.
+func (a *API) Locks(ctx xcontext.Context, requestor EventRequestor, targetIDs []string) (Response, error) {
+	resp := a.newResponse(ResponseTypeLocks)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "locks"),
+		Type:     EventTypeLocks,
+		ServerID: resp.ServerID,
+		Msg: EventLocksMsg{
+			requestor: requestor,
+			TargetIDs: targetIDs,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataLocks{
+		Locks: respEv.Locks,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Unlock forcibly removes the locks on the given targets, regardless of the
+// job holding them. This is meant for operators to release stuck targets.
+func (a *API) Unlock(ctx xcontext.Context, requestor EventRequestor, targetIDs []string) (Response, error) {
+	resp := a.newResponse(ResponseTypeUnlock)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "unlock"),
+		Type:     EventTypeUnlock,
+		ServerID: resp.ServerID,
+		Msg: EventUnlockMsg{
+			requestor: requestor,
+			TargetIDs: targetIDs,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataUnlock{
+		Unlocked: respEv.Locks,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Purge permanently deletes the request, reports and events of a finished job,
+// optionally archiving them on the server first.
+func (a *API) Purge(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID, archive bool) (Response, error) {
+	resp := a.newResponse(ResponseTypePurge)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "purge"),
+		Type:     EventTypePurge,
+		ServerID: resp.ServerID,
+		Msg: EventPurgeMsg{
+			requestor: requestor,
+			JobID:     jobID,
+			Archive:   archive,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataPurge{
+		JobID:       jobID,
+		ArchivePath: respEv.ArchivePath,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Export returns the request, reports and events of a job as a single
+// archive, which can be imported into another server.
+func (a *API) Export(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID) (Response, error) {
+	resp := a.newResponse(ResponseTypeExport)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "export"),
+		Type:     EventTypeExport,
+		ServerID: resp.ServerID,
+		Msg: EventExportMsg{
+			requestor: requestor,
+			JobID:     jobID,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataExport{
+		JobID:   jobID,
+		Archive: respEv.Archive,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Import stores a job archive returned by Export under a new job ID.
+func (a *API) Import(ctx xcontext.Context, requestor EventRequestor, archive []byte) (Response, error) {
+	resp := a.newResponse(ResponseTypeImport)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "import"),
+		Type:     EventTypeImport,
+		ServerID: resp.ServerID,
+		Msg: EventImportMsg{
+			requestor: requestor,
+			Archive:   archive,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataImport{
+		JobID: respEv.JobID,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Diff compares the statuses of two jobs, reporting the changes of the
+// results of their targets and of the durations of their test steps.
+func (a *API) Diff(ctx xcontext.Context, requestor EventRequestor, jobA, jobB types.JobID) (Response, error) {
+	resp := a.newResponse(ResponseTypeDiff)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "diff"),
+		Type:     EventTypeDiff,
+		ServerID: resp.ServerID,
+		Msg: EventDiffMsg{
+			requestor: requestor,
+			JobA:      jobA,
+			JobB:      jobB,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataDiff{
+		Diff: respEv.Diff,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
+// Analytics aggregates the target events of the jobs with a tag, emitted in
+// the given time window, into failure rates, retries and durations per test
+// step and per target.
+func (a *API) Analytics(ctx xcontext.Context, requestor EventRequestor, tag string, since, until time.Time) (Response, error) {
+	resp := a.newResponse(ResponseTypeAnalytics)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "analytics"),
+		Type:     EventTypeAnalytics,
+		ServerID: resp.ServerID,
+		Msg: EventAnalyticsMsg{
+			requestor: requestor,
+			Tag:       tag,
+			Since:     since,
+			Until:     until,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataAnalytics{
+		Tag:    tag,
+		Since:  since,
+		Until:  until,
+		Report: respEv.Analytics,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
+
 // Status polls the status of a job by its ID, and returns a contest.Status
 //object
 func (a *API) Status(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID) (Response, error) {
+	return a.StatusPage(ctx, requestor, jobID, 0, 0)
+}
+
+// StatusPage is like Status, but only includes the statuses of at most
+// maxRuns runs following the run runsAfter. A maxRuns of 0 means no limit.
+func (a *API) StatusPage(ctx xcontext.Context, requestor EventRequestor, jobID types.JobID, runsAfter types.RunID, maxRuns uint) (Response, error) {
 	resp := a.newResponse(ResponseTypeStatus)
 	ev := &Event{
 		Context:  ctx.WithField("api_method", "status"),
@@ -220,6 +456,8 @@ func (a *API) Status(ctx xcontext.Context, requestor EventRequestor, jobID types
 		Msg: EventStatusMsg{
 			requestor: requestor,
 			JobID:     jobID,
+			RunsAfter: runsAfter,
+			MaxRuns:   maxRuns,
 		},
 		RespCh: make(chan *EventResponse, 1),
 	}
@@ -285,3 +523,29 @@ func (a *API) List(ctx xcontext.Context, requestor EventRequestor, query *storag
 	resp.Err = respEv.Err
 	return resp, nil
 }
+
+// Validate checks a job descriptor the same way Start does, including test
+// fetching and the validation of all the plugin parameters, but without
+// creating or running the job. All the problems found are returned at once.
+func (a *API) Validate(ctx xcontext.Context, requestor EventRequestor, jobDescriptor string) (Response, error) {
+	resp := a.newResponse(ResponseTypeValidate)
+	ev := &Event{
+		Context:  ctx.WithField("api_method", "validate"),
+		Type:     EventTypeValidate,
+		ServerID: resp.ServerID,
+		Msg: EventValidateMsg{
+			requestor:     requestor,
+			JobDescriptor: jobDescriptor,
+		},
+		RespCh: make(chan *EventResponse, 1),
+	}
+	respEv, err := a.SendReceiveEvent(ev, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = ResponseDataValidate{
+		Errors: respEv.ValidationErrors,
+	}
+	resp.Err = respEv.Err
+	return resp, nil
+}
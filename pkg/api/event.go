@@ -6,8 +6,12 @@
 package api
 
 import (
+	"time"
+
+	"github.com/linuxboot/contest/pkg/analytics"
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/target"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
@@ -29,12 +33,22 @@ func (e EventType) String() string {
 }
 
 var eventTypeNames = map[EventType]string{
-	EventTypeStart:  "event_type_start",
-	EventTypeStatus: "event_type_status",
-	EventTypeStop:   "event_type_stop",
-	EventTypeRetry:  "event_type_retry",
-	EventTypeError:  "event_type_error",
-	EventTypeList:   "event_type_list",
+	EventTypeStart:     "event_type_start",
+	EventTypeStatus:    "event_type_status",
+	EventTypeStop:      "event_type_stop",
+	EventTypeRetry:     "event_type_retry",
+	EventTypeError:     "event_type_error",
+	EventTypeList:      "event_type_list",
+	EventTypeValidate:  "event_type_validate",
+	EventTypePause:     "event_type_pause",
+	EventTypeResume:    "event_type_resume",
+	EventTypeLocks:     "event_type_locks",
+	EventTypeUnlock:    "event_type_unlock",
+	EventTypePurge:     "event_type_purge",
+	EventTypeExport:    "event_type_export",
+	EventTypeImport:    "event_type_import",
+	EventTypeDiff:      "event_type_diff",
+	EventTypeAnalytics: "event_type_analytics",
 }
 
 // list of existing API event types.
@@ -45,6 +59,16 @@ const (
 	EventTypeRetry
 	EventTypeError
 	EventTypeList
+	EventTypeValidate
+	EventTypePause
+	EventTypeResume
+	EventTypeLocks
+	EventTypeUnlock
+	EventTypePurge
+	EventTypeExport
+	EventTypeImport
+	EventTypeDiff
+	EventTypeAnalytics
 )
 
 // Event represents an event that the API can generate. This is used by the API
@@ -83,6 +107,10 @@ func (e EventStartMsg) Requestor() EventRequestor { return e.requestor }
 type EventStatusMsg struct {
 	requestor EventRequestor
 	JobID     types.JobID
+	// RunsAfter and MaxRuns select the page of run statuses to build, the
+	// zero values select all the runs.
+	RunsAfter types.RunID
+	MaxRuns   uint
 }
 
 // Requestor returns the requestor of the API call as reported by the client.
@@ -106,13 +134,117 @@ type EventRetryMsg struct {
 // Requestor returns the requestor of the API call as reported by the client.
 func (e EventRetryMsg) Requestor() EventRequestor { return e.requestor }
 
+// EventValidateMsg contains the arguments for an event of type Validate.
+type EventValidateMsg struct {
+	requestor     EventRequestor
+	JobDescriptor string
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventValidateMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventPauseMsg contains the arguments for an event of type Pause.
+type EventPauseMsg struct {
+	requestor EventRequestor
+	JobID     types.JobID
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventPauseMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventResumeMsg contains the arguments for an event of type Resume.
+type EventResumeMsg struct {
+	requestor EventRequestor
+	JobID     types.JobID
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventResumeMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventLocksMsg contains the arguments for an event of type Locks.
+type EventLocksMsg struct {
+	requestor EventRequestor
+	// TargetIDs restricts the query to the given targets, all the locks are
+	// returned if empty.
+	TargetIDs []string
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventLocksMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventUnlockMsg contains the arguments for an event of type Unlock.
+type EventUnlockMsg struct {
+	requestor EventRequestor
+	TargetIDs []string
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventUnlockMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventPurgeMsg contains the arguments for an event of type Purge.
+type EventPurgeMsg struct {
+	requestor EventRequestor
+	JobID     types.JobID
+	// Archive requests the job to be archived before being purged.
+	Archive bool
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventPurgeMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventExportMsg contains the arguments for an event of type Export.
+type EventExportMsg struct {
+	requestor EventRequestor
+	JobID     types.JobID
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventExportMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventImportMsg contains the arguments for an event of type Import.
+type EventImportMsg struct {
+	requestor EventRequestor
+	// Archive is the JSON encoded archive of the job, as returned by Export.
+	Archive []byte
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventImportMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventDiffMsg contains the arguments for an event of type Diff.
+type EventDiffMsg struct {
+	requestor EventRequestor
+	JobA      types.JobID
+	JobB      types.JobID
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventDiffMsg) Requestor() EventRequestor { return e.requestor }
+
+// EventAnalyticsMsg contains the arguments for an event of type Analytics.
+type EventAnalyticsMsg struct {
+	requestor EventRequestor
+	Tag       string
+	Since     time.Time
+	Until     time.Time
+}
+
+// Requestor returns the requestor of the API call as reported by the client.
+func (e EventAnalyticsMsg) Requestor() EventRequestor { return e.requestor }
+
 // EventResponse is a response to an EventMsg.
 type EventResponse struct {
-	Requestor EventRequestor
-	JobID     types.JobID
-	Err       error
-	Status    *job.Status
-	JobIDs    []types.JobID
+	Requestor        EventRequestor
+	JobID            types.JobID
+	Err              error
+	Status           *job.Status
+	JobIDs           []types.JobID
+	ValidationErrors []job.ValidationError
+	Locks            []target.LockInfo
+	ArchivePath      string
+	Archive          []byte
+	Diff             *job.StatusDiff
+	Analytics        *analytics.Report
 }
 
 // EventListMsg contains the arguments for an event of type List.
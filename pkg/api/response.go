@@ -6,7 +6,12 @@
 package api
 
 import (
+	"encoding/json"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/analytics"
 	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/target"
 	"github.com/linuxboot/contest/pkg/types"
 
 	"github.com/insomniacslk/xjson"
@@ -23,16 +28,36 @@ const (
 	ResponseTypeRetry
 	ResponseTypeVersion
 	ResponseTypeList
+	ResponseTypeValidate
+	ResponseTypePause
+	ResponseTypeResume
+	ResponseTypeLocks
+	ResponseTypeUnlock
+	ResponseTypePurge
+	ResponseTypeExport
+	ResponseTypeImport
+	ResponseTypeDiff
+	ResponseTypeAnalytics
 )
 
 // ResponseTypeToName maps response types to their names.
 var ResponseTypeToName = map[ResponseType]string{
-	ResponseTypeStart:   "ResponseTypeStart",
-	ResponseTypeStop:    "ResponseTypeStop",
-	ResponseTypeStatus:  "ResponseTypeStatus",
-	ResponseTypeRetry:   "ResponseTypeRetry",
-	ResponseTypeVersion: "ResponseTypeVersion",
-	ResponseTypeList:    "ResponseTypeList",
+	ResponseTypeStart:     "ResponseTypeStart",
+	ResponseTypeStop:      "ResponseTypeStop",
+	ResponseTypeStatus:    "ResponseTypeStatus",
+	ResponseTypeRetry:     "ResponseTypeRetry",
+	ResponseTypeVersion:   "ResponseTypeVersion",
+	ResponseTypeList:      "ResponseTypeList",
+	ResponseTypeValidate:  "ResponseTypeValidate",
+	ResponseTypePause:     "ResponseTypePause",
+	ResponseTypeResume:    "ResponseTypeResume",
+	ResponseTypeLocks:     "ResponseTypeLocks",
+	ResponseTypeUnlock:    "ResponseTypeUnlock",
+	ResponseTypePurge:     "ResponseTypePurge",
+	ResponseTypeExport:    "ResponseTypeExport",
+	ResponseTypeImport:    "ResponseTypeImport",
+	ResponseTypeDiff:      "ResponseTypeDiff",
+	ResponseTypeAnalytics: "ResponseTypeAnalytics",
 }
 
 // Response is the type returned to any API request.
@@ -67,6 +92,24 @@ func (r ResponseDataStop) Type() ResponseType {
 	return ResponseTypeStop
 }
 
+// ResponseDataPause is the response type for a Pause request.
+type ResponseDataPause struct {
+}
+
+// Type returns the response type.
+func (r ResponseDataPause) Type() ResponseType {
+	return ResponseTypePause
+}
+
+// ResponseDataResume is the response type for a Resume request.
+type ResponseDataResume struct {
+}
+
+// Type returns the response type.
+func (r ResponseDataResume) Type() ResponseType {
+	return ResponseTypeResume
+}
+
 // ResponseDataStatus is the response type for a Status request.
 type ResponseDataStatus struct {
 	Status *job.Status
@@ -98,6 +141,96 @@ func (r ResponseDataList) Type() ResponseType {
 	return ResponseTypeList
 }
 
+// ResponseDataValidate is the response type for a Validate request. An empty
+// list of errors means that the job descriptor is valid.
+type ResponseDataValidate struct {
+	Errors []job.ValidationError
+}
+
+// Type returns the response type.
+func (r ResponseDataValidate) Type() ResponseType {
+	return ResponseTypeValidate
+}
+
+// ResponseDataLocks is the response type for a Locks request.
+type ResponseDataLocks struct {
+	Locks []target.LockInfo
+}
+
+// Type returns the response type.
+func (r ResponseDataLocks) Type() ResponseType {
+	return ResponseTypeLocks
+}
+
+// ResponseDataUnlock is the response type for an Unlock request. It contains
+// the locks that were removed.
+type ResponseDataUnlock struct {
+	Unlocked []target.LockInfo
+}
+
+// Type returns the response type.
+func (r ResponseDataUnlock) Type() ResponseType {
+	return ResponseTypeUnlock
+}
+
+// ResponseDataPurge is the response type for a Purge request. ArchivePath is
+// the path of the archive on the server, if the job was archived.
+type ResponseDataPurge struct {
+	JobID       types.JobID
+	ArchivePath string
+}
+
+// Type returns the response type.
+func (r ResponseDataPurge) Type() ResponseType {
+	return ResponseTypePurge
+}
+
+// ResponseDataExport is the response type for an Export request. Archive is
+// the JSON encoded archive of the job, which can be passed to Import.
+type ResponseDataExport struct {
+	JobID   types.JobID
+	Archive json.RawMessage
+}
+
+// Type returns the response type.
+func (r ResponseDataExport) Type() ResponseType {
+	return ResponseTypeExport
+}
+
+// ResponseDataImport is the response type for an Import request. JobID is the
+// new ID of the imported job.
+type ResponseDataImport struct {
+	JobID types.JobID
+}
+
+// Type returns the response type.
+func (r ResponseDataImport) Type() ResponseType {
+	return ResponseTypeImport
+}
+
+// ResponseDataDiff is the response type for a Diff request.
+type ResponseDataDiff struct {
+	Diff *job.StatusDiff
+}
+
+// Type returns the response type.
+func (r ResponseDataDiff) Type() ResponseType {
+	return ResponseTypeDiff
+}
+
+// ResponseDataAnalytics is the response type for an Analytics request.
+type ResponseDataAnalytics struct {
+	Tag    string
+	Since  time.Time
+	Until  time.Time
+	Report *analytics.Report
+}
+
+// Type returns the response type.
+func (r ResponseDataAnalytics) Type() ResponseType {
+	return ResponseTypeAnalytics
+}
+
 // ResponseDataVersion is the response type for a Version request.
 type ResponseDataVersion struct {
 	Version uint32
@@ -132,6 +265,20 @@ type StopResponse struct {
 	Err      *xjson.Error
 }
 
+// PauseResponse is a typesafe version of Response with a Pause payload
+type PauseResponse struct {
+	ServerID string
+	Data     ResponseDataPause
+	Err      *xjson.Error
+}
+
+// ResumeResponse is a typesafe version of Response with a Resume payload
+type ResumeResponse struct {
+	ServerID string
+	Data     ResponseDataResume
+	Err      *xjson.Error
+}
+
 // RetryResponse is a typesafe version of Response with a Status payload
 type RetryResponse struct {
 	ServerID string
@@ -146,6 +293,62 @@ type ListResponse struct {
 	Err      *xjson.Error
 }
 
+// ValidateResponse is a typesafe version of Response with a Validate payload
+type ValidateResponse struct {
+	ServerID string
+	Data     ResponseDataValidate
+	Err      *xjson.Error
+}
+
+// LocksResponse is a typesafe version of Response with a Locks payload
+type LocksResponse struct {
+	ServerID string
+	Data     ResponseDataLocks
+	Err      *xjson.Error
+}
+
+// UnlockResponse is a typesafe version of Response with an Unlock payload
+type UnlockResponse struct {
+	ServerID string
+	Data     ResponseDataUnlock
+	Err      *xjson.Error
+}
+
+// PurgeResponse is a typesafe version of Response with a Purge payload
+type PurgeResponse struct {
+	ServerID string
+	Data     ResponseDataPurge
+	Err      *xjson.Error
+}
+
+// ExportResponse is a typesafe version of Response with an Export payload
+type ExportResponse struct {
+	ServerID string
+	Data     ResponseDataExport
+	Err      *xjson.Error
+}
+
+// ImportResponse is a typesafe version of Response with an Import payload
+type ImportResponse struct {
+	ServerID string
+	Data     ResponseDataImport
+	Err      *xjson.Error
+}
+
+// DiffResponse is a typesafe version of Response with a Diff payload
+type DiffResponse struct {
+	ServerID string
+	Data     ResponseDataDiff
+	Err      *xjson.Error
+}
+
+// AnalyticsResponse is a typesafe version of Response with an Analytics payload
+type AnalyticsResponse struct {
+	ServerID string
+	Data     ResponseDataAnalytics
+	Err      *xjson.Error
+}
+
 // VersionResponse is a typesafe version of Response with a Status payload
 type VersionResponse struct {
 	ServerID string
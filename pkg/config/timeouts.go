@@ -44,3 +44,13 @@ var TestRunnerStepShutdownTimeout = 5 * time.Second
 // DefaultTargetLockDuration is the default value for -targetLockDuration.
 // It is the amount of time target lock is extended by while the job is running.
 const DefaultTargetLockDuration = 10 * time.Minute
+
+// DefaultDeadServerTimeout is the default value for -deadServerTimeout.
+// It is the amount of time after the last heartbeat of a server after which the
+// server is considered dead, and its jobs can be taken over by other servers.
+const DefaultDeadServerTimeout = 2 * time.Minute
+
+// DefaultRetentionInterval is the default value for -retentionInterval.
+// It is the interval between two runs of the janitor which purges the jobs
+// that have exceeded the retention policy.
+const DefaultRetentionInterval = time.Hour
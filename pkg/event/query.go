@@ -11,12 +11,27 @@ import (
 	"github.com/linuxboot/contest/pkg/types"
 )
 
+// Order is the order of the events returned by a query, by sequence ID.
+type Order int
+
+// Supported orders. The zero value is OrderAscending.
+const (
+	OrderAscending Order = iota
+	OrderDescending
+)
+
 // Query wraps information that are used to build event queries for all type of event objects
 type Query struct {
 	JobID            types.JobID
 	EventNames       []Name
 	EmittedStartTime time.Time
 	EmittedEndTime   time.Time
+	// SequenceIDAfter restricts the query to the events with a greater
+	// sequence ID, which allows fetching events incrementally.
+	SequenceIDAfter uint64
+	// Limit is the maximum number of events returned, 0 means no limit.
+	Limit uint
+	Order Order
 }
 
 type QueryField interface{}
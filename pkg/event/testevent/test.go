@@ -88,6 +88,9 @@ type queryFieldJobID types.JobID
 type queryFieldEventNames []event.Name
 type queryFieldEmittedStartTime time.Time
 type queryFieldEmittedEndTime time.Time
+type queryFieldSequenceIDAfter uint64
+type queryFieldLimit uint
+type queryFieldOrder event.Order
 type queryFieldTestName string
 type queryFieldTestStepLabel string
 type queryFieldRunID types.RunID
@@ -121,6 +124,27 @@ func (value queryFieldEmittedEndTime) queryFieldPointer(query *Query) interface{
 	return &query.EmittedEndTime
 }
 
+// QuerySequenceIDAfter sets the SequenceIDAfter field of the Query object
+func QuerySequenceIDAfter(sequenceID uint64) QueryField {
+	return queryFieldSequenceIDAfter(sequenceID)
+}
+func (value queryFieldSequenceIDAfter) queryFieldPointer(query *Query) interface{} {
+	return &query.SequenceIDAfter
+}
+
+// QueryLimit sets the Limit field of the Query object
+func QueryLimit(limit uint) QueryField { return queryFieldLimit(limit) }
+func (value queryFieldLimit) queryFieldPointer(query *Query) interface{} {
+	return &query.Limit
+}
+
+// QueryOrder sets the Order field of the Query object. Events are returned
+// in ascending order by default, so only OrderDescending needs to be set.
+func QueryOrder(order event.Order) QueryField { return queryFieldOrder(order) }
+func (value queryFieldOrder) queryFieldPointer(query *Query) interface{} {
+	return &query.Order
+}
+
 // QueryTestName sets the TestName field of the Query object
 func QueryTestName(testName string) QueryField {
 	return queryFieldTestName(testName)
@@ -44,3 +44,16 @@ func TestBuildQuery_NoZeroValues(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.As(err, &event.ErrQueryFieldHasZeroValue{}))
 }
+
+func TestBuildQuery_Pagination(t *testing.T) {
+	query, err := QueryFields{
+		QueryJobID(1),
+		QuerySequenceIDAfter(10),
+		QueryLimit(100),
+		QueryOrder(event.OrderDescending),
+	}.BuildQuery()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), query.SequenceIDAfter)
+	assert.Equal(t, uint(100), query.Limit)
+	assert.Equal(t, event.OrderDescending, query.Order)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package job
+
+import (
+	"time"
+
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+// TargetResult is the outcome of a target in a test step.
+type TargetResult string
+
+// The possible outcomes of a target in a test step.
+const (
+	// TargetResultPass means that the target left the step without error.
+	TargetResultPass TargetResult = "pass"
+	// TargetResultFail means that the step returned an error for the target.
+	TargetResultFail TargetResult = "fail"
+	// TargetResultIncomplete means that the target entered the step but never
+	// left it, e.g. because the job was cancelled.
+	TargetResultIncomplete TargetResult = "incomplete"
+)
+
+// Result returns the outcome of the target in the test step.
+func (ts *TargetStatus) Result() TargetResult {
+	switch {
+	case ts.Error != "":
+		return TargetResultFail
+	case !ts.OutTime.IsZero():
+		return TargetResultPass
+	default:
+		return TargetResultIncomplete
+	}
+}
+
+// DiffChange describes how an item of the status of a job differs in the
+// status of another job.
+type DiffChange string
+
+// The possible changes between two jobs.
+const (
+	// DiffChangeNone means that the item is in both jobs. For a target, it
+	// also means that its result and error are the same.
+	DiffChangeNone DiffChange = ""
+	// DiffChangeNew means that the item is only in the second job.
+	DiffChangeNew DiffChange = "new"
+	// DiffChangeMissing means that the item is only in the first job.
+	DiffChangeMissing DiffChange = "missing"
+	// DiffChangeResult means that the result of a target changed.
+	DiffChangeResult DiffChange = "result"
+	// DiffChangeError means that the result of a target is the same, but its
+	// error message changed.
+	DiffChangeError DiffChange = "error"
+)
+
+// TargetDiff is the difference between the statuses of a target within the
+// same test step of two jobs.
+type TargetDiff struct {
+	TargetID string
+	Change   DiffChange
+	ResultA  TargetResult `json:",omitempty"`
+	ResultB  TargetResult `json:",omitempty"`
+	ErrorA   string       `json:",omitempty"`
+	ErrorB   string       `json:",omitempty"`
+}
+
+// TestStepDiff is the difference between the same test step in two jobs.
+// Durations span from the first target entering the step to the last target
+// leaving it. Only the targets that changed are listed.
+type TestStepDiff struct {
+	TestName      string
+	TestStepLabel string
+	Change        DiffChange
+	DurationA     time.Duration
+	DurationB     time.Duration
+	DurationDelta time.Duration
+	Targets       []TargetDiff `json:",omitempty"`
+}
+
+// RunDiff is the difference between the runs with the same ID of two jobs.
+type RunDiff struct {
+	RunID  types.RunID
+	Change DiffChange
+	Steps  []TestStepDiff `json:",omitempty"`
+}
+
+// StatusDiff is the difference between the statuses of two jobs, aligned by
+// run ID, test name, test step label and target ID.
+type StatusDiff struct {
+	JobA types.JobID
+	JobB types.JobID
+	Runs []RunDiff
+	// Regressions is the number of targets which passed a step in the first
+	// job and failed it in the second one, Fixes the other way round.
+	Regressions int
+	Fixes       int
+}
+
+// DiffStatuses compares the statuses of two jobs.
+func DiffStatuses(jobA types.JobID, a *Status, jobB types.JobID, b *Status) *StatusDiff {
+	diff := &StatusDiff{JobA: jobA, JobB: jobB}
+	runsB := make(map[types.RunID]*RunStatus, len(b.RunStatuses))
+	for i := range b.RunStatuses {
+		runsB[b.RunStatuses[i].RunID] = &b.RunStatuses[i]
+	}
+	seen := make(map[types.RunID]bool, len(a.RunStatuses))
+	for i := range a.RunStatuses {
+		runA := &a.RunStatuses[i]
+		seen[runA.RunID] = true
+		runDiff := RunDiff{RunID: runA.RunID}
+		runB, ok := runsB[runA.RunID]
+		if !ok {
+			runDiff.Change = DiffChangeMissing
+		} else {
+			runDiff.Steps = diff.diffSteps(runA, runB)
+		}
+		diff.Runs = append(diff.Runs, runDiff)
+	}
+	for _, runB := range b.RunStatuses {
+		if !seen[runB.RunID] {
+			diff.Runs = append(diff.Runs, RunDiff{RunID: runB.RunID, Change: DiffChangeNew})
+		}
+	}
+	return diff
+}
+
+type stepKey struct {
+	testName, testStepLabel string
+}
+
+func runSteps(run *RunStatus) ([]stepKey, map[stepKey]*TestStepStatus) {
+	var keys []stepKey
+	steps := make(map[stepKey]*TestStepStatus)
+	for i := range run.TestStatuses {
+		testStatus := &run.TestStatuses[i]
+		for j := range testStatus.TestStepStatuses {
+			step := &testStatus.TestStepStatuses[j]
+			key := stepKey{testName: testStatus.TestName, testStepLabel: step.TestStepLabel}
+			if _, ok := steps[key]; !ok {
+				keys = append(keys, key)
+			}
+			steps[key] = step
+		}
+	}
+	return keys, steps
+}
+
+func (diff *StatusDiff) diffSteps(runA, runB *RunStatus) []TestStepDiff {
+	keysA, stepsA := runSteps(runA)
+	keysB, stepsB := runSteps(runB)
+	var stepDiffs []TestStepDiff
+	for _, key := range keysA {
+		stepDiff := TestStepDiff{TestName: key.testName, TestStepLabel: key.testStepLabel}
+		stepA := stepsA[key]
+		stepDiff.DurationA = stepDuration(stepA)
+		if stepB, ok := stepsB[key]; ok {
+			stepDiff.DurationB = stepDuration(stepB)
+			stepDiff.DurationDelta = stepDiff.DurationB - stepDiff.DurationA
+			stepDiff.Targets = diff.diffTargets(stepA, stepB)
+		} else {
+			stepDiff.Change = DiffChangeMissing
+		}
+		stepDiffs = append(stepDiffs, stepDiff)
+	}
+	for _, key := range keysB {
+		if _, ok := stepsA[key]; ok {
+			continue
+		}
+		stepDiffs = append(stepDiffs, TestStepDiff{
+			TestName:      key.testName,
+			TestStepLabel: key.testStepLabel,
+			Change:        DiffChangeNew,
+			DurationB:     stepDuration(stepsB[key]),
+		})
+	}
+	return stepDiffs
+}
+
+func (diff *StatusDiff) diffTargets(stepA, stepB *TestStepStatus) []TargetDiff {
+	targetsB := make(map[string]*TargetStatus, len(stepB.TargetStatuses))
+	for i := range stepB.TargetStatuses {
+		if t := stepB.TargetStatuses[i].Target; t != nil {
+			targetsB[t.ID] = &stepB.TargetStatuses[i]
+		}
+	}
+	var targetDiffs []TargetDiff
+	seen := make(map[string]bool, len(stepA.TargetStatuses))
+	for i := range stepA.TargetStatuses {
+		targetA := &stepA.TargetStatuses[i]
+		if targetA.Target == nil {
+			continue
+		}
+		seen[targetA.Target.ID] = true
+		targetDiff := TargetDiff{
+			TargetID: targetA.Target.ID,
+			ResultA:  targetA.Result(),
+			ErrorA:   targetA.Error,
+		}
+		targetB, ok := targetsB[targetA.Target.ID]
+		if !ok {
+			targetDiff.Change = DiffChangeMissing
+			targetDiffs = append(targetDiffs, targetDiff)
+			continue
+		}
+		targetDiff.ResultB = targetB.Result()
+		targetDiff.ErrorB = targetB.Error
+		switch {
+		case targetDiff.ResultA != targetDiff.ResultB:
+			targetDiff.Change = DiffChangeResult
+			if targetDiff.ResultA == TargetResultPass && targetDiff.ResultB == TargetResultFail {
+				diff.Regressions++
+			} else if targetDiff.ResultA == TargetResultFail && targetDiff.ResultB == TargetResultPass {
+				diff.Fixes++
+			}
+		case targetDiff.ErrorA != targetDiff.ErrorB:
+			targetDiff.Change = DiffChangeError
+		default:
+			continue
+		}
+		targetDiffs = append(targetDiffs, targetDiff)
+	}
+	for i := range stepB.TargetStatuses {
+		targetB := &stepB.TargetStatuses[i]
+		if targetB.Target == nil || seen[targetB.Target.ID] {
+			continue
+		}
+		targetDiffs = append(targetDiffs, TargetDiff{
+			TargetID: targetB.Target.ID,
+			Change:   DiffChangeNew,
+			ResultB:  targetB.Result(),
+			ErrorB:   targetB.Error,
+		})
+	}
+	return targetDiffs
+}
+
+// stepDuration returns the time from the first target entering the step to
+// the last target leaving it, or 0 if no target left it.
+func stepDuration(step *TestStepStatus) time.Duration {
+	var in, out time.Time
+	for _, ts := range step.TargetStatuses {
+		if !ts.InTime.IsZero() && (in.IsZero() || ts.InTime.Before(in)) {
+			in = ts.InTime
+		}
+		if ts.OutTime.After(out) {
+			out = ts.OutTime
+		}
+	}
+	if in.IsZero() || out.Before(in) {
+		return 0
+	}
+	return out.Sub(in)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+var diffStart = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func targetStatus(id string, duration time.Duration, errMsg string) TargetStatus {
+	ts := TargetStatus{Target: &target.Target{ID: id}, InTime: diffStart, Error: errMsg}
+	if duration > 0 {
+		ts.OutTime = diffStart.Add(duration)
+	}
+	return ts
+}
+
+func runStatus(runID types.RunID, steps ...TestStepStatus) RunStatus {
+	return RunStatus{
+		RunCoordinates: RunCoordinates{RunID: runID},
+		TestStatuses: []TestStatus{{
+			TestCoordinates:  TestCoordinates{TestName: "test"},
+			TestStepStatuses: steps,
+		}},
+	}
+}
+
+func stepStatus(label string, targets ...TargetStatus) TestStepStatus {
+	return TestStepStatus{
+		TestStepCoordinates: TestStepCoordinates{TestStepLabel: label},
+		TargetStatuses:      targets,
+	}
+}
+
+func TestDiffStatuses(t *testing.T) {
+	a := &Status{RunStatuses: []RunStatus{
+		runStatus(1,
+			stepStatus("flash",
+				targetStatus("t1", time.Minute, ""),
+				targetStatus("t2", time.Minute, "timeout"),
+				targetStatus("t3", time.Minute, "timeout"),
+				targetStatus("t4", time.Minute, ""),
+				targetStatus("t5", time.Minute, ""),
+			),
+			stepStatus("removed", targetStatus("t1", time.Second, "")),
+		),
+		runStatus(2),
+	}}
+	b := &Status{RunStatuses: []RunStatus{
+		runStatus(1,
+			stepStatus("flash",
+				targetStatus("t1", 2*time.Minute, "boot failed"),
+				targetStatus("t2", time.Minute, ""),
+				targetStatus("t3", time.Minute, "no ping"),
+				targetStatus("t4", 0, ""),
+				targetStatus("t6", time.Minute, ""),
+			),
+			stepStatus("added", targetStatus("t1", time.Second, "")),
+		),
+		runStatus(3),
+	}}
+
+	require.Equal(t, &StatusDiff{
+		JobA: 1,
+		JobB: 2,
+		Runs: []RunDiff{
+			{
+				RunID: 1,
+				Steps: []TestStepDiff{
+					{
+						TestName:      "test",
+						TestStepLabel: "flash",
+						DurationA:     time.Minute,
+						DurationB:     2 * time.Minute,
+						DurationDelta: time.Minute,
+						Targets: []TargetDiff{
+							{TargetID: "t1", Change: DiffChangeResult, ResultA: TargetResultPass, ResultB: TargetResultFail, ErrorB: "boot failed"},
+							{TargetID: "t2", Change: DiffChangeResult, ResultA: TargetResultFail, ResultB: TargetResultPass, ErrorA: "timeout"},
+							{TargetID: "t3", Change: DiffChangeError, ResultA: TargetResultFail, ResultB: TargetResultFail, ErrorA: "timeout", ErrorB: "no ping"},
+							{TargetID: "t4", Change: DiffChangeResult, ResultA: TargetResultPass, ResultB: TargetResultIncomplete},
+							{TargetID: "t5", Change: DiffChangeMissing, ResultA: TargetResultPass},
+							{TargetID: "t6", Change: DiffChangeNew, ResultB: TargetResultPass},
+						},
+					},
+					{TestName: "test", TestStepLabel: "removed", Change: DiffChangeMissing, DurationA: time.Second},
+					{TestName: "test", TestStepLabel: "added", Change: DiffChangeNew, DurationB: time.Second},
+				},
+			},
+			{RunID: 2, Change: DiffChangeMissing},
+			{RunID: 3, Change: DiffChangeNew},
+		},
+		Regressions: 1,
+		Fixes:       1,
+	}, DiffStatuses(1, a, 2, b))
+}
+
+func TestDiffStatusesIdentical(t *testing.T) {
+	a := &Status{RunStatuses: []RunStatus{
+		runStatus(1, stepStatus("flash", targetStatus("t1", time.Minute, "timeout"))),
+	}}
+	diff := DiffStatuses(1, a, 1, a)
+	require.Len(t, diff.Runs, 1)
+	require.Len(t, diff.Runs[0].Steps, 1)
+	require.Empty(t, diff.Runs[0].Steps[0].Targets)
+	require.Zero(t, diff.Regressions)
+}
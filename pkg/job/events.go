@@ -40,6 +40,10 @@ var EventJobCancelled = event.Name("JobStateCancelled")
 // EventJobCancellationFailed indicates that the cancellation was not completed correctly
 var EventJobCancellationFailed = event.Name("JobStateCancellationFailed")
 
+// EventJobImported indicates that a Job was imported from an archive, it is
+// emitted after the events of the archive
+var EventJobImported = event.Name("JobImported")
+
 // JobCompletionEvents gathers all event names that mark the end of a job
 var JobCompletionEvents = []event.Name{
 	EventJobCompleted,
@@ -81,6 +85,12 @@ func EventNameToJobState(ev event.Name) (State, error) {
 	return JobStateUnknown, fmt.Errorf("invalid job state %q", ev)
 }
 
+// ImportEventPayload is the payload of the JobImported event.
+type ImportEventPayload struct {
+	// JobID is the ID of the job in the archive
+	JobID types.JobID
+}
+
 // PauseEventPayload is the payload of the JobStatePaused event.
 // It is persisted in the database and used to resume jobs.
 type PauseEventPayload struct {
@@ -95,6 +105,9 @@ type PauseEventPayload struct {
 	// Otherwise, if test execution is in progress targets and runner state will be populated.
 	Targets         []*target.Target `json:"TT,omitempty"`
 	TestRunnerState json.RawMessage  `json:"TRS,omitempty"`
+	// ByUser is set when the job was paused via the API, it is then resumed
+	// via the API only, and not when the server starts.
+	ByUser bool `json:"U,omitempty"`
 }
 
 func (pp *PauseEventPayload) String() string {
@@ -106,8 +119,8 @@ func (pp *PauseEventPayload) String() string {
 	if pp.NextTestAttempt != nil {
 		nta = pp.NextTestAttempt.Unix()
 	}
-	return fmt.Sprintf("[V:%d J:%d R:%d T:%d TR:%d NTA: %d ST:%d TT:%v TRS:%s]",
-		pp.Version, pp.JobID, pp.RunID, pp.TestID, pp.TestAttempt, nta, sts, pp.Targets, pp.TestRunnerState,
+	return fmt.Sprintf("[V:%d J:%d R:%d T:%d TR:%d NTA: %d ST:%d TT:%v TRS:%s U:%t]",
+		pp.Version, pp.JobID, pp.RunID, pp.TestID, pp.TestAttempt, nta, sts, pp.Targets, pp.TestRunnerState, pp.ByUser,
 	)
 }
 
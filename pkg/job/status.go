@@ -96,9 +96,14 @@ type Status struct {
 	// RunStatus [deprecated since 28jun2021] is the status of the last run of the job, if it exists
 	RunStatus *RunStatus
 
-	// RunStatuses represents the status of all runs for a given job
+	// RunStatuses represents the status of all runs for a given job, or of
+	// the requested page of runs
 	RunStatuses []RunStatus
 
+	// NumRuns is the number of runs which were started for the job. It
+	// allows clients to request the statuses of the runs in pages.
+	NumRuns types.RunID
+
 	// Job report information
 	JobReport *JobReport
 }
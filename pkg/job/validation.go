@@ -0,0 +1,45 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package job
+
+import (
+	"fmt"
+)
+
+// ValidationError describes a single problem found while validating a job
+// descriptor. TestName and StepLabel are set when the problem can be
+// attributed to a specific test or test step, and are empty otherwise.
+type ValidationError struct {
+	TestName  string `json:",omitempty"`
+	StepLabel string `json:",omitempty"`
+	Msg       string
+
+	// err is the error Msg comes from, if any
+	err error
+}
+
+// NewValidationError returns the ValidationError for err.
+func NewValidationError(testName, stepLabel string, err error) ValidationError {
+	return ValidationError{TestName: testName, StepLabel: stepLabel, Msg: err.Error(), err: err}
+}
+
+// Unwrap returns the error the ValidationError was created from, if any.
+func (e ValidationError) Unwrap() error {
+	return e.err
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	switch {
+	case e.TestName != "" && e.StepLabel != "":
+		return fmt.Sprintf("test %q, step %q: %s", e.TestName, e.StepLabel, e.Msg)
+	case e.TestName != "":
+		return fmt.Sprintf("test %q: %s", e.TestName, e.Msg)
+	case e.StepLabel != "":
+		return fmt.Sprintf("step %q: %s", e.StepLabel, e.Msg)
+	}
+	return e.Msg
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/analytics"
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+func (jm *JobManager) analytics(ev *api.Event) *api.EventResponse {
+	ctx := storage.WithConsistencyModel(ev.Context, storage.ConsistentEventually)
+
+	msg := ev.Msg.(api.EventAnalyticsMsg)
+	evResp := &api.EventResponse{
+		Requestor: ev.Msg.Requestor(),
+	}
+	evResp.Analytics, evResp.Err = jm.Analytics(ctx, msg.Tag, msg.Since, msg.Until)
+	return evResp
+}
+
+// Analytics computes the failure rates, retries and durations per test step
+// and per target, from the target events of the jobs with the given tag
+// emitted between since and until. A zero since means from the beginning.
+func (jm *JobManager) Analytics(ctx xcontext.Context, tag string, since, until time.Time) (*analytics.Report, error) {
+	if err := job.CheckTags([]string{tag}, false); err != nil {
+		return nil, err
+	}
+	if until.Before(since) {
+		return nil, fmt.Errorf("invalid time window: %v is before %v", until, since)
+	}
+	tags := []string{tag}
+	if jm.config.instanceTag != "" {
+		tags = append(tags, jm.config.instanceTag)
+	}
+	// Jobs requested after the end of the window cannot have events in it.
+	query, err := storage.BuildJobQuery(storage.QueryJobTags(tags...), storage.QueryJobRequestedBefore(until))
+	if err != nil {
+		return nil, err
+	}
+	jobIDs, err := jm.jsm.ListJobs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs with tag %q: %w", tag, err)
+	}
+
+	aggregator := analytics.NewAggregator()
+	for _, jobID := range jobIDs {
+		queryFields := []testevent.QueryField{
+			testevent.QueryJobID(jobID),
+			testevent.QueryEventNames(analytics.EventNames),
+			testevent.QueryEmittedEndTime(until),
+		}
+		if !since.IsZero() {
+			queryFields = append(queryFields, testevent.QueryEmittedStartTime(since))
+		}
+		events, err := jm.testEvManager.Fetch(ctx, queryFields...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch events of job %d: %w", jobID, err)
+		}
+		aggregator.Add(events)
+	}
+	return aggregator.Report(), nil
+}
@@ -6,99 +6,138 @@
 package jobmanager
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/pluginregistry"
 	"github.com/linuxboot/contest/pkg/storage/limits"
+	"github.com/linuxboot/contest/pkg/target"
 	"github.com/linuxboot/contest/pkg/test"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
 
-// newReportingBundles returns the bundles for the run report and the final report
-func newReportingBundles(registry *pluginregistry.PluginRegistry, jobDescriptor *job.Descriptor) ([]*job.ReporterBundle, []*job.ReporterBundle, error) {
+// The helpers below are shared by job creation, which stops at the first
+// error, and ValidateJobDescriptor, which reports all of them. They don't
+// stop at the first problem and return every error found.
+
+// checkJobDescriptor checks the job-level fields of the descriptor
+func checkJobDescriptor(jobDescriptor *job.Descriptor) []job.ValidationError {
+	var errs []job.ValidationError
+	if err := limits.NewValidator().ValidateJobName(jobDescriptor.JobName); err != nil {
+		errs = append(errs, job.NewValidationError("", "", err))
+	}
+	if err := jobDescriptor.Validate(); err != nil {
+		errs = append(errs, job.NewValidationError("", "", fmt.Errorf("could not validate job descriptor: %w", err)))
+	}
+	return errs
+}
 
+// newReportingBundles returns the bundles for the run report and the final report
+func newReportingBundles(registry *pluginregistry.PluginRegistry, jobDescriptor *job.Descriptor) ([]*job.ReporterBundle, []*job.ReporterBundle, []job.ValidationError) {
 	var (
 		runReporterBundles   []*job.ReporterBundle
 		finalReporterBundles []*job.ReporterBundle
+		errs                 []job.ValidationError
 	)
 
 	for _, reporter := range jobDescriptor.Reporting.RunReporters {
-		if strings.TrimSpace(reporter.Name) == "" {
-			return nil, nil, errors.New("invalid empty or all-whitespace run reporter name")
-		}
-		if err := limits.NewValidator().ValidateReporterName(reporter.Name); err != nil {
-			return nil, nil, err
+		if err := validateReporterName(reporter.Name); err != nil {
+			errs = append(errs, job.NewValidationError("", "", fmt.Errorf("run reporter: %w", err)))
+			continue
 		}
 		bundle, err := registry.NewRunReporterBundle(reporter.Name, reporter.Parameters)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create bundle for run reporter '%s': %v", reporter.Name, err)
+			errs = append(errs, job.NewValidationError("", "", fmt.Errorf("failed to create bundle for run reporter '%s': %w", reporter.Name, err)))
+			continue
 		}
 		runReporterBundles = append(runReporterBundles, bundle)
 	}
 
 	for _, reporter := range jobDescriptor.Reporting.FinalReporters {
-		if strings.TrimSpace(reporter.Name) == "" {
-			return nil, nil, errors.New("invalid empty or all-whitespace final reporter name")
-		}
-		if err := limits.NewValidator().ValidateReporterName(reporter.Name); err != nil {
-			return nil, nil, err
+		if err := validateReporterName(reporter.Name); err != nil {
+			errs = append(errs, job.NewValidationError("", "", fmt.Errorf("final reporter: %w", err)))
+			continue
 		}
 		bundle, err := registry.NewFinalReporterBundle(reporter.Name, reporter.Parameters)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create bundle for final reporter '%s': %v", reporter.Name, err)
+			errs = append(errs, job.NewValidationError("", "", fmt.Errorf("failed to create bundle for final reporter '%s': %w", reporter.Name, err)))
+			continue
 		}
 		finalReporterBundles = append(finalReporterBundles, bundle)
 	}
 
-	return runReporterBundles, finalReporterBundles, nil
+	return runReporterBundles, finalReporterBundles, errs
+}
+
+func validateReporterName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid empty or all-whitespace reporter name")
+	}
+	return limits.NewValidator().ValidateReporterName(name)
 }
 
-func newBundlesFromSteps(ctx xcontext.Context, descriptors []*test.TestStepDescriptor, registry *pluginregistry.PluginRegistry) ([]test.TestStepBundle, error) {
+// newTestBundles returns the target manager and test fetcher bundles of the
+// test descriptor at index idx. The test name is not known before fetching,
+// so the errors refer to the index instead.
+func newTestBundles(ctx xcontext.Context, registry *pluginregistry.PluginRegistry, idx int, td *test.TestDescriptor) (*target.TargetManagerBundle, *test.TestFetcherBundle, []job.ValidationError) {
+	var errs []job.ValidationError
+	if td == nil {
+		return nil, nil, append(errs, job.ValidationError{Msg: fmt.Sprintf("test descriptor #%d is null", idx)})
+	}
+	if err := td.Validate(); err != nil {
+		return nil, nil, append(errs, job.NewValidationError("", "", fmt.Errorf("could not validate test descriptor #%d: %w", idx, err)))
+	}
+	bundleTargetManager, err := registry.NewTargetManagerBundle(td)
+	if err != nil {
+		errs = append(errs, job.NewValidationError("", "", fmt.Errorf("test descriptor #%d: %w", idx, err)))
+	}
+	bundleTestFetcher, err := registry.NewTestFetcherBundle(ctx, td)
+	if err != nil {
+		errs = append(errs, job.NewValidationError("", "", fmt.Errorf("test descriptor #%d: %w", idx, err)))
+	}
+	return bundleTargetManager, bundleTestFetcher, errs
+}
+
+// newStepBundles creates the step bundles of a test, after expanding the step
+// loops. It also checks the test name and that the step labels are unique.
+func newStepBundles(ctx xcontext.Context, descriptors test.TestStepsDescriptors, registry *pluginregistry.PluginRegistry) ([]test.TestStepBundle, []job.ValidationError) {
+	testName := descriptors.TestName
+	var errs []job.ValidationError
+	if err := limits.NewValidator().ValidateTestName(testName); err != nil {
+		errs = append(errs, job.NewValidationError(testName, "", err))
+	}
+	if len(descriptors.TestSteps) == 0 {
+		return nil, append(errs, job.ValidationError{TestName: testName, Msg: "at least one test step is required per test"})
+	}
+	stepDescriptors, err := test.ExpandStepLoops(descriptors.TestSteps)
+	if err != nil {
+		return nil, append(errs, job.NewValidationError(testName, "", err))
+	}
 
 	// look up test step plugins in the plugin registry
 	var stepBundles []test.TestStepBundle
-
-	for idx, descriptor := range descriptors {
+	labels := make(map[string]bool)
+	for idx, descriptor := range stepDescriptors {
 		if descriptor == nil {
-			return nil, fmt.Errorf("test step description is null")
+			errs = append(errs, job.ValidationError{TestName: testName, Msg: fmt.Sprintf("test step description is null at index %d", idx)})
+			continue
 		}
 		if err := limits.NewValidator().ValidateTestStepLabel(descriptor.Label); err != nil {
-			return nil, err
+			errs = append(errs, job.NewValidationError(testName, descriptor.Label, err))
+			continue
 		}
+		if labels[descriptor.Label] {
+			errs = append(errs, job.ValidationError{TestName: testName, StepLabel: descriptor.Label, Msg: "found duplicated labels"})
+		}
+		labels[descriptor.Label] = true
 		tsb, err := registry.NewTestStepBundle(ctx, *descriptor)
 		if err != nil {
-			return nil, fmt.Errorf("NewStepBundle for test step '%s' with index %d failed: %w", descriptor.Name, idx, err)
+			errs = append(errs, job.NewValidationError(testName, descriptor.Label, fmt.Errorf("could not create bundle for test step '%s': %w", descriptor.Name, err)))
+			continue
 		}
 		stepBundles = append(stepBundles, *tsb)
 	}
-
-	if len(stepBundles) == 0 {
-		return nil, fmt.Errorf("at least one test step is required per test")
-	}
-
-	return stepBundles, nil
-
-}
-
-// newStepBundles creates bundles for the test
-func newStepBundles(ctx xcontext.Context, descriptors test.TestStepsDescriptors, registry *pluginregistry.PluginRegistry) ([]test.TestStepBundle, error) {
-
-	testStepBundles, err := newBundlesFromSteps(ctx, descriptors.TestSteps, registry)
-	if err != nil {
-		return nil, fmt.Errorf("could not create test steps bundle: %w", err)
-	}
-
-	// verify that there are not duplicated labels
-	labels := make(map[string]bool)
-	for _, bundle := range testStepBundles {
-		if _, ok := labels[bundle.TestStepLabel]; ok {
-			return nil, fmt.Errorf("found duplicated labels: %s", bundle.TestStepLabel)
-		}
-		labels[bundle.TestStepLabel] = true
-	}
 	// TODO: verify that test variables refer to existing steps
-	return testStepBundles, nil
+	return stepBundles, errs
 }
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+func (jm *JobManager) export(ev *api.Event) *api.EventResponse {
+	msg := ev.Msg.(api.EventExportMsg)
+	evResp := &api.EventResponse{
+		JobID:     msg.JobID,
+		Requestor: ev.Msg.Requestor(),
+	}
+	archive, err := jm.ExportJob(ev.Context, msg.JobID)
+	if err != nil {
+		evResp.Err = err
+		return evResp
+	}
+	if evResp.Archive, err = json.Marshal(archive); err != nil {
+		evResp.Err = fmt.Errorf("failed to encode archive of job %d: %w", msg.JobID, err)
+	}
+	return evResp
+}
+
+func (jm *JobManager) importJob(ev *api.Event) *api.EventResponse {
+	msg := ev.Msg.(api.EventImportMsg)
+	evResp := &api.EventResponse{
+		Requestor: ev.Msg.Requestor(),
+	}
+	var archive JobArchive
+	if err := json.Unmarshal(msg.Archive, &archive); err != nil {
+		evResp.Err = fmt.Errorf("invalid job archive: %w", err)
+		return evResp
+	}
+	evResp.JobID, evResp.Err = jm.ImportJob(ev.Context, &archive)
+	return evResp
+}
+
+// ExportJob returns the request, reports and events of a job, in the same
+// format as the archives written when purging jobs.
+func (jm *JobManager) ExportJob(ctx xcontext.Context, jobID types.JobID) (*JobArchive, error) {
+	ctx = storage.WithConsistencyModel(ctx, storage.ConsistentReadAfterWrite)
+	return jm.fetchJobArchive(ctx, jobID)
+}
+
+// ImportJob stores the data of an exported or archived job under a new job
+// ID, which is returned. The request keeps its request time, events their
+// order and emit time, and reports their report time. A JobImported event is
+// added, from which the retention policy counts. Only jobs which reached a
+// final state can be imported, so that imported jobs are never resumed.
+func (jm *JobManager) ImportJob(ctx xcontext.Context, archive *JobArchive) (types.JobID, error) {
+	if archive.Request == nil || archive.Request.ExtendedDescriptor == nil {
+		return 0, fmt.Errorf("invalid job archive: missing job request")
+	}
+	if state := lastJobState(archive.FrameworkEvents); !isFinalState(state) {
+		return 0, fmt.Errorf("job %d is in state %s, only finished jobs can be imported", archive.Request.JobID, state)
+	}
+
+	req := *archive.Request
+	req.JobID = 0
+	extendedDescriptor := *req.ExtendedDescriptor
+	req.ExtendedDescriptor = &extendedDescriptor
+	// Add instance tag, if specified, so that the job is visible to this instance.
+	if jm.config.instanceTag != "" {
+		var jd job.Descriptor
+		if err := json.Unmarshal([]byte(req.JobDescriptor), &jd); err != nil {
+			return 0, fmt.Errorf("invalid job descriptor: %w", err)
+		}
+		jd.Tags = job.AddTags(jd.Tags, jm.config.instanceTag)
+		jdJSON, err := json.MarshalIndent(&jd, "", "    ")
+		if err != nil {
+			return 0, err
+		}
+		req.JobDescriptor = string(jdJSON)
+		extendedDescriptor.Tags = job.AddTags(extendedDescriptor.Tags, jm.config.instanceTag)
+	}
+	jobID, err := jm.jsm.StoreJobRequest(ctx, &req)
+	if err != nil {
+		return 0, fmt.Errorf("could not create job request: %w", err)
+	}
+
+	if err := jm.importJobData(ctx, jobID, archive); err != nil {
+		// Do not leave a partially imported job behind.
+		if purgeErr := jm.psm.PurgeJob(ctx, jobID); purgeErr != nil {
+			ctx.Errorf("Failed to purge partially imported job %d: %v", jobID, purgeErr)
+		}
+		return 0, fmt.Errorf("failed to import job %d: %w", archive.Request.JobID, err)
+	}
+	ctx.Infof("Imported job %d as job %d", archive.Request.JobID, jobID)
+	return jobID, nil
+}
+
+func (jm *JobManager) importJobData(ctx xcontext.Context, jobID types.JobID, archive *JobArchive) error {
+	if archive.Report != nil {
+		var reports []*job.Report
+		for _, runReports := range archive.Report.RunReports {
+			reports = append(reports, runReports...)
+		}
+		reports = append(reports, archive.Report.FinalReports...)
+		for _, report := range reports {
+			r := *report
+			r.JobID = jobID
+			if err := jm.jsm.StoreReport(ctx, &r); err != nil {
+				return err
+			}
+		}
+	}
+
+	frameworkEvents := make([]frameworkevent.Event, len(archive.FrameworkEvents))
+	copy(frameworkEvents, archive.FrameworkEvents)
+	sort.SliceStable(frameworkEvents, func(i, j int) bool {
+		return frameworkEvents[i].SequenceID < frameworkEvents[j].SequenceID
+	})
+	for i := range frameworkEvents {
+		frameworkEvents[i].JobID = jobID
+	}
+	payload, err := json.Marshal(job.ImportEventPayload{JobID: archive.Request.JobID})
+	if err != nil {
+		return err
+	}
+	frameworkEvents = append(frameworkEvents, frameworkevent.Event{
+		JobID:     jobID,
+		EventName: job.EventJobImported,
+		Payload:   (*json.RawMessage)(&payload),
+		EmitTime:  jm.config.clock.Now(),
+	})
+	if err := jm.ism.StoreFrameworkEvents(ctx, frameworkEvents); err != nil {
+		return err
+	}
+
+	testEvents := make([]testevent.Event, len(archive.TestEvents))
+	copy(testEvents, archive.TestEvents)
+	sort.SliceStable(testEvents, func(i, j int) bool {
+		return testEvents[i].SequenceID < testEvents[j].SequenceID
+	})
+	for i := range testEvents {
+		if testEvents[i].Header == nil {
+			return fmt.Errorf("test event %d has no header", testEvents[i].SequenceID)
+		}
+		header := *testEvents[i].Header
+		header.JobID = jobID
+		testEvents[i].Header = &header
+	}
+	return jm.ism.StoreTestEvents(ctx, testEvents)
+}
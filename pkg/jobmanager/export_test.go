@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+)
+
+func TestExportImportJob(t *testing.T) {
+	src := newStorageFixture(t)
+	dst := newStorageFixture(t, OptionInstanceTag("_instance"), OptionRetentionPolicy(RetentionPolicy{MaxAge: time.Minute}))
+	// Make sure the imported job gets a different ID.
+	dst.storeJob(t, time.Hour, job.EventJobCompleted)
+
+	jobID := src.storeJob(t, time.Hour, job.EventJobCompleted, "nightly")
+	for i, name := range []string{"start", "end"} {
+		require.NoError(t, src.storage.StoreTestEvent(src.ctx, testevent.Event{
+			EmitTime: src.now.Add(time.Duration(i) * time.Minute),
+			Header:   &testevent.Header{JobID: jobID, RunID: 1, TestName: "test"},
+			Data:     &testevent.Data{EventName: event.Name(name)},
+		}))
+	}
+	require.NoError(t, src.storage.StoreReport(src.ctx, &job.Report{JobID: jobID, RunID: 1, ReporterName: "r", ReportTime: src.now, Success: true}))
+	require.NoError(t, src.storage.StoreReport(src.ctx, &job.Report{JobID: jobID, ReporterName: "r", ReportTime: src.now}))
+
+	archive, err := src.jm.ExportJob(src.ctx, jobID)
+	require.NoError(t, err)
+	archive.Request.ExtendedDescriptor = &job.ExtendedDescriptor{}
+	// Go through JSON, the way archives are transferred.
+	data, err := json.Marshal(archive)
+	require.NoError(t, err)
+	var decoded JobArchive
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	newJobID, err := dst.jm.ImportJob(dst.ctx, &decoded)
+	require.NoError(t, err)
+	require.NotEqual(t, jobID, newJobID)
+
+	ctx := storage.WithConsistencyModel(dst.ctx, storage.ConsistentReadAfterWrite)
+	imported, err := dst.jm.ExportJob(ctx, newJobID)
+	require.NoError(t, err)
+	require.Equal(t, newJobID, imported.Request.JobID)
+	require.Equal(t, archive.Request.RequestTime.UTC(), imported.Request.RequestTime.UTC())
+	var jd job.Descriptor
+	require.NoError(t, json.Unmarshal([]byte(imported.Request.JobDescriptor), &jd))
+	require.Equal(t, []string{"nightly", "_instance"}, jd.Tags)
+
+	// The import is recorded after the events of the archive.
+	require.Len(t, imported.FrameworkEvents, len(archive.FrameworkEvents)+1)
+	importEvent := imported.FrameworkEvents[len(archive.FrameworkEvents)]
+	require.Equal(t, job.EventJobImported, importEvent.EventName)
+	require.True(t, dst.now.Equal(importEvent.EmitTime))
+	var importPayload job.ImportEventPayload
+	require.NoError(t, json.Unmarshal(*importEvent.Payload, &importPayload))
+	require.Equal(t, jobID, importPayload.JobID)
+	for i, ev := range imported.FrameworkEvents[:len(archive.FrameworkEvents)] {
+		require.Equal(t, newJobID, ev.JobID)
+		require.Equal(t, archive.FrameworkEvents[i].EventName, ev.EventName)
+		require.True(t, archive.FrameworkEvents[i].EmitTime.Equal(ev.EmitTime))
+	}
+	require.Len(t, imported.TestEvents, len(archive.TestEvents))
+	for i, ev := range imported.TestEvents {
+		require.Equal(t, newJobID, ev.Header.JobID)
+		require.True(t, archive.TestEvents[i].EmitTime.Equal(ev.EmitTime))
+	}
+	require.Len(t, imported.Report.RunReports, 1)
+	require.Len(t, imported.Report.FinalReports, 1)
+	require.Equal(t, newJobID, imported.Report.FinalReports[0].JobID)
+	require.True(t, imported.Report.RunReports[0][0].Success)
+
+	require.Equal(t, "end", string(imported.TestEvents[1].Data.EventName))
+
+	// The retention of the imported job counts from the import, not from its request.
+	expired, err := dst.jm.expiredJobs(ctx, dst.now)
+	require.NoError(t, err)
+	require.NotContains(t, expired, newJobID)
+	expired, err = dst.jm.expiredJobs(ctx, dst.now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Contains(t, expired, newJobID)
+
+	// The imported archive is left untouched.
+	require.Equal(t, jobID, decoded.TestEvents[0].Header.JobID)
+}
+
+func TestImportUnfinishedJob(t *testing.T) {
+	f := newStorageFixture(t)
+	_, err := f.jm.ImportJob(f.ctx, &JobArchive{
+		Request: &job.Request{JobDescriptor: "{}", ExtendedDescriptor: &job.ExtendedDescriptor{}},
+		FrameworkEvents: []frameworkevent.Event{
+			{SequenceID: 1, EventName: job.EventJobStarted},
+		},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/pluginregistry"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/storage/memory"
+)
+
+// storageFixture is a JobManager on top of a memory storage, with a mock clock.
+type storageFixture struct {
+	ctx     xcontext.Context
+	storage storage.Storage
+	vault   *storage.SimpleEngineVault
+	clock   *clock.Mock
+	jm      *JobManager
+	now     time.Time
+}
+
+func newStorageFixture(t *testing.T, opts ...Option) *storageFixture {
+	ctx := xcontext.Background()
+	s, err := memory.New()
+	require.NoError(t, err)
+	vault := storage.NewSimpleEngineVault()
+	require.NoError(t, vault.StoreEngine(s, storage.SyncEngine))
+	require.NoError(t, vault.StoreEngine(s, storage.AsyncEngine))
+	clk := clock.NewMock()
+	clk.Set(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	f := &storageFixture{ctx: ctx, storage: s, vault: vault, clock: clk, now: clk.Now()}
+	f.jm = f.newJobManager(t, opts...)
+	return f
+}
+
+// newJobManager returns another JobManager sharing the storage and the clock
+// of the fixture, like a server sharing the database with the others.
+func (f *storageFixture) newJobManager(t *testing.T, opts ...Option) *JobManager {
+	jm, err := New(nil, pluginregistry.NewPluginRegistry(f.ctx), f.vault, append(opts, OptionClock(f.clock))...)
+	require.NoError(t, err)
+	return jm
+}
+
+// storeJob stores a job requested age ago, in the state set by the given event.
+func (f *storageFixture) storeJob(t *testing.T, age time.Duration, stateEvent event.Name, tags ...string) types.JobID {
+	desc, err := json.Marshal(job.Descriptor{JobName: "test", Tags: tags})
+	require.NoError(t, err)
+	jobID, err := f.storage.StoreJobRequest(f.ctx, &job.Request{
+		JobName:       "test",
+		JobDescriptor: string(desc),
+		RequestTime:   f.now.Add(-age),
+	})
+	require.NoError(t, err)
+	for i, ev := range []event.Name{job.EventJobStarted, stateEvent} {
+		emitTime := f.now.Add(-age + time.Duration(i)*time.Second)
+		require.NoError(t, f.storage.StoreFrameworkEvent(f.ctx, frameworkevent.Event{JobID: jobID, EventName: ev, EmitTime: emitTime}))
+	}
+	return jobID
+}
@@ -14,7 +14,6 @@ import (
 	pkg_config "github.com/linuxboot/contest/pkg/config"
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/pluginregistry"
-	"github.com/linuxboot/contest/pkg/storage/limits"
 	"github.com/linuxboot/contest/pkg/test"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
@@ -28,20 +27,13 @@ func newJob(ctx xcontext.Context, registry *pluginregistry.PluginRegistry, jobDe
 	if jobDescriptor == nil {
 		return nil, errors.New("JobDescriptor cannot be nil")
 	}
-	jobName := jobDescriptor.JobName
-	if err := limits.NewValidator().ValidateJobName(jobName); err != nil {
-		return nil, err
-	}
-	if err := jobDescriptor.Validate(); err != nil {
-		return nil, fmt.Errorf("could not validate job descriptor: %w", err)
-	}
-	if len(jobDescriptor.TestDescriptors) == 0 {
-		return nil, fmt.Errorf("cannot create job with zero tests")
+	if errs := checkJobDescriptor(jobDescriptor); len(errs) > 0 {
+		return nil, errs[0]
 	}
 
-	runReportersBundle, finalReportersBundle, err := newReportingBundles(registry, jobDescriptor)
-	if err != nil {
-		return nil, fmt.Errorf("error while building reporters bundles: %w", err)
+	runReportersBundle, finalReportersBundle, errs := newReportingBundles(registry, jobDescriptor)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error while building reporters bundles: %w", errs[0])
 	}
 
 	tests := make([]*test.Test, 0, len(jobDescriptor.TestDescriptors))
@@ -54,33 +46,19 @@ func newJob(ctx xcontext.Context, registry *pluginregistry.PluginRegistry, jobDe
 	}
 
 	for index, td := range jobDescriptor.TestDescriptors {
-		thisTestStepsDescriptors := stepsDescriptors[index]
-
-		if err := td.Validate(); err != nil {
-			return nil, fmt.Errorf("could not validate test descriptor: %w", err)
-		}
-		bundleTargetManager, err := registry.NewTargetManagerBundle(td)
-		if err != nil {
-			return nil, err
-		}
-		bundleTestFetcher, err := registry.NewTestFetcherBundle(ctx, td)
-		if err != nil {
-			return nil, err
-		}
-
-		bundleTest, err := newStepBundles(ctx, thisTestStepsDescriptors, registry)
-		if err != nil {
-			return nil, fmt.Errorf("could not create step bundles: %w", err)
+		bundleTargetManager, bundleTestFetcher, errs := newTestBundles(ctx, registry, index, td)
+		if len(errs) > 0 {
+			return nil, errs[0]
 		}
-		testName := thisTestStepsDescriptors.TestName
-		if err := limits.NewValidator().ValidateTestName(testName); err != nil {
-			return nil, err
+		bundleTest, errs := newStepBundles(ctx, stepsDescriptors[index], registry)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("could not create step bundles: %w", errs[0])
 		}
 		if td.Disabled {
 			continue
 		}
 		test := test.Test{
-			Name:                testName,
+			Name:                stepsDescriptors[index].TestName,
 			TargetManagerBundle: bundleTargetManager,
 			TestFetcherBundle:   bundleTestFetcher,
 			TestStepsBundles:    bundleTest,
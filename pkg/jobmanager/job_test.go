@@ -156,3 +156,130 @@ func TestNewJobNoTestSteps(t *testing.T) {
 	_, err := NewJobFromDescriptor(xcontext.Background(), pr, &jd)
 	require.Error(t, err)
 }
+
+func TestValidateJobDescriptorReportsAllErrors(t *testing.T) {
+	pr := pluginregistry.NewPluginRegistry(xcontext.Background())
+	require.NoError(t, pr.RegisterTestStep(echo.Load()))
+	require.NoError(t, pr.RegisterTargetManager(targetlist.Load()))
+	require.NoError(t, pr.RegisterTestFetcher(literal.Load()))
+	require.NoError(t, pr.RegisterReporter(noop.Load()))
+
+	testFetcherParams := `{
+	    "TestName": "TestInvalid",
+		"Steps": [
+			{
+				"name": "echo",
+				"label": "echo1",
+				"parameters": {}
+			},
+			{
+				"name": "echo",
+				"label": "echo2",
+				"parameters": {
+					"text": ["Some text"]
+				}
+			},
+			{
+				"name": "echo",
+				"label": "echo2",
+				"parameters": {}
+			}
+		]
+	}`
+
+	testDescriptors := []*test.TestDescriptor{
+		{
+			TargetManagerName:              "targetList",
+			TargetManagerAcquireParameters: []byte(`{"Targets": [{"ID": "id1"}]}`),
+			TargetManagerReleaseParameters: []byte("{}"),
+			TestFetcherName:                "literal",
+			TestFetcherFetchParameters:     []byte(testFetcherParams),
+		},
+	}
+	jd := job.Descriptor{
+		TestDescriptors: testDescriptors,
+		JobName:         "Test",
+		Reporting: job.Reporting{
+			RunReporters: []job.ReporterConfig{
+				{Name: "noop"},
+				{Name: "nonexistent"},
+			},
+		},
+	}
+
+	errs := ValidateJobDescriptor(xcontext.Background(), pr, &jd)
+	// missing version, unknown reporter, missing parameter in echo1,
+	// duplicated label and missing parameter in the second echo2.
+	require.Len(t, errs, 5, "%v", errs)
+	require.Empty(t, errs[0].TestName)
+	require.Empty(t, errs[1].TestName)
+	require.Equal(t, "TestInvalid", errs[2].TestName)
+	require.Equal(t, "echo1", errs[2].StepLabel)
+	require.Equal(t, "echo2", errs[3].StepLabel)
+	require.Equal(t, "echo2", errs[4].StepLabel)
+
+	jd.Version = job.CurrentDescriptorVersion()
+	jd.Reporting.RunReporters = jd.Reporting.RunReporters[:1]
+	jd.TestDescriptors[0].TestFetcherFetchParameters = []byte(`{
+		"TestName": "TestValid",
+		"Steps": [{"name": "echo", "label": "echo1", "parameters": {"text": ["Some text"]}}]
+	}`)
+	require.Empty(t, ValidateJobDescriptor(xcontext.Background(), pr, &jd))
+}
+
+func TestNewJobStepLoops(t *testing.T) {
+	pr := pluginregistry.NewPluginRegistry(xcontext.Background())
+	require.NoError(t, pr.RegisterTestStep(echo.Load()))
+	require.NoError(t, pr.RegisterTargetManager(targetlist.Load()))
+	require.NoError(t, pr.RegisterTestFetcher(literal.Load()))
+	require.NoError(t, pr.RegisterReporter(noop.Load()))
+
+	testFetcherParams := `{
+	    "TestName": "TestLoops",
+		"Steps": [
+			{
+				"name": "echo",
+				"label": "echo",
+				"repeat": 3,
+				"parameters": {
+					"text": ["Iteration {{ LoopIndex }}"]
+				}
+			}
+		]
+	}`
+
+	testDescriptors := []*test.TestDescriptor{
+		{
+			TargetManagerName:              "targetList",
+			TargetManagerAcquireParameters: []byte(`{"Targets": [{"ID": "id1"}]}`),
+			TargetManagerReleaseParameters: []byte("{}"),
+			TestFetcherName:                "literal",
+			TestFetcherFetchParameters:     []byte(testFetcherParams),
+		},
+	}
+	jd := job.Descriptor{
+		TestDescriptors: testDescriptors,
+		JobName:         "Test",
+		Reporting: job.Reporting{
+			RunReporters: []job.ReporterConfig{
+				{Name: "noop"},
+			},
+		},
+	}
+
+	result, err := NewJobFromDescriptor(xcontext.Background(), pr, &jd)
+	require.NoError(t, err)
+	require.Len(t, result.Tests, 1)
+
+	var labels []string
+	for _, bundle := range result.Tests[0].TestStepsBundles {
+		labels = append(labels, bundle.TestStepLabel)
+	}
+	require.Equal(t, []string{"echo_0", "echo_1", "echo_2"}, labels)
+	require.Equal(t, 2, result.Tests[0].TestStepsBundles[2].Loop.Index)
+
+	// the stored descriptor keeps the loop, so the job is expanded the same way on resume
+	result, err = NewJobFromExtendedDescriptor(xcontext.Background(), pr, result.ExtendedDescriptor)
+	require.NoError(t, err)
+	require.Equal(t, "echo_2", result.Tests[0].TestStepsBundles[2].TestStepLabel)
+}
@@ -49,6 +49,9 @@ type JobManager struct {
 	jobsMu sync.Mutex
 
 	jsm storage.JobStorageManager
+	ssm storage.ServerStorageManager
+	psm storage.PurgeStorageManager
+	ism storage.ImportStorageManager
 
 	frameworkEvManager frameworkevent.EmitterFetcher
 	testEvManager      testevent.Fetcher
@@ -57,6 +60,13 @@ type JobManager struct {
 	pluginRegistry *pluginregistry.PluginRegistry
 
 	apiCancel xcontext.CancelFunc
+	// startedCh is closed once Run has dealt with zombie and paused jobs,
+	// and is ready to handle requests.
+	startedCh chan struct{}
+	// takeoverMu is held while taking over the jobs of a dead server.
+	takeoverMu sync.Mutex
+	// resumeMu serializes resume requests.
+	resumeMu sync.Mutex
 
 	msgCounter int
 }
@@ -64,6 +74,8 @@ type JobManager struct {
 type jobInfo struct {
 	job           *job.Job
 	pause, cancel func()
+	// pausedByUser is set when the job is paused via the API
+	pausedByUser bool
 }
 
 // New initializes and returns a new JobManager with the given API listener.
@@ -86,14 +98,27 @@ func New(l api.Listener, pr *pluginregistry.PluginRegistry, storageEngineVault s
 		}
 	}
 
+	// Obtain the server ID once, so that the API responses, the job requests
+	// and the heartbeats all use the same one.
+	a, err := api.New(cfg.apiOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain server ID: %w", err)
+	}
+	cfg.serverID = a.ServerID()
+	cfg.apiOptions = append(cfg.apiOptions, api.OptionServerID(cfg.serverID))
+
 	jm := JobManager{
 		config:             cfg,
 		apiListener:        l,
 		pluginRegistry:     pr,
 		jobs:               make(map[types.JobID]*jobInfo),
 		jsm:                jsm,
+		ssm:                storage.NewServerStorageManager(storageEngineVault),
+		psm:                storage.NewPurgeStorageManager(storageEngineVault),
+		ism:                storage.NewImportStorageManager(storageEngineVault),
 		frameworkEvManager: frameworkEvManager,
 		testEvManager:      testEvManager,
+		startedCh:          make(chan struct{}),
 	}
 	jm.jobRunner = runner.NewJobRunner(jsm, storageEngineVault, cfg.clock, cfg.targetLockDuration)
 	return &jm, nil
@@ -113,6 +138,26 @@ func (jm *JobManager) handleEvent(ev *api.Event) {
 		resp = jm.retry(ev)
 	case api.EventTypeList:
 		resp = jm.list(ev)
+	case api.EventTypeValidate:
+		resp = jm.validate(ev)
+	case api.EventTypePause:
+		resp = jm.pause(ev)
+	case api.EventTypeResume:
+		resp = jm.resume(ev)
+	case api.EventTypeLocks:
+		resp = jm.locks(ev)
+	case api.EventTypeUnlock:
+		resp = jm.unlock(ev)
+	case api.EventTypePurge:
+		resp = jm.purge(ev)
+	case api.EventTypeExport:
+		resp = jm.export(ev)
+	case api.EventTypeImport:
+		resp = jm.importJob(ev)
+	case api.EventTypeDiff:
+		resp = jm.diff(ev)
+	case api.EventTypeAnalytics:
+		resp = jm.analytics(ev)
 	default:
 		resp = &api.EventResponse{
 			Requestor: ev.Msg.Requestor(),
@@ -144,20 +189,51 @@ func (jm *JobManager) Run(ctx xcontext.Context, resumeJobs bool) error {
 		return fmt.Errorf("Cannot start API: %w", err)
 	}
 
-	// Deal with zombieed jobs (fail them).
-	if err := jm.failZombieJobs(ctx, a.ServerID()); err != nil {
-		ctx.Errorf("failed to fail jobs: %v", err)
+	// With heartbeats enabled, another server may be taking over the jobs of
+	// a previous instance of this server, in which case we leave them alone.
+	ownJobs := true
+	if jm.config.heartbeatInterval > 0 {
+		ownJobs, err = jm.claimOwnJobs(ctx, jm.config.serverID)
+		if err != nil {
+			return fmt.Errorf("failed to claim jobs: %w", err)
+		}
+		if !ownJobs {
+			ctx.Warnf("Jobs of %s are being taken over by another server", jm.config.serverID)
+		}
 	}
 
-	// First, resume paused jobs.
-	if resumeJobs {
-		if err := jm.resumeJobs(ctx, a.ServerID()); err != nil {
-			return fmt.Errorf("failed to resume jobs: %w", err)
+	if ownJobs {
+		// Deal with zombieed jobs (fail them).
+		if err := jm.failZombieJobs(ctx, jm.config.serverID); err != nil {
+			ctx.Errorf("failed to fail jobs: %v", err)
+		}
+
+		// First, resume paused jobs.
+		if resumeJobs {
+			if err := jm.resumeJobs(ctx, jm.config.serverID); err != nil {
+				return fmt.Errorf("failed to resume jobs: %w", err)
+			}
 		}
 	}
 
 	apiCtx, apiCancel := xcontext.WithCancel(ctx)
 	jm.apiCancel = apiCancel
+	close(jm.startedCh)
+
+	if jm.config.heartbeatInterval > 0 {
+		heartbeatStopCh, heartbeatDoneCh := make(chan struct{}), make(chan struct{})
+		go jm.heartbeatLoop(ctx, apiCtx, jm.config.serverID, heartbeatStopCh, heartbeatDoneCh)
+		defer func() {
+			close(heartbeatStopCh)
+			<-heartbeatDoneCh
+		}()
+	}
+
+	if jm.config.retentionPolicy.Enabled() {
+		retentionDoneCh := make(chan struct{})
+		go jm.retentionLoop(apiCtx, retentionDoneCh)
+		defer func() { <-retentionDoneCh }()
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -198,6 +274,9 @@ loop:
 	<-errCh
 	// Wait for event handler completion
 	handlerWg.Wait()
+	// Wait for an ongoing takeover to complete, no more will be started.
+	jm.takeoverMu.Lock()
+	jm.takeoverMu.Unlock()
 	// Wait for jobs to complete or for cancellation signal.
 	doneCh := ctx.Done()
 	pausedCh := ctx.Until(xcontext.ErrPaused)
@@ -220,6 +299,13 @@ loop:
 	return nil
 }
 
+// Started returns a channel which is closed once Run is ready to handle
+// requests. Jobs started in-process (see StartJob) before that could be
+// mistaken for zombie jobs of a previous instance.
+func (jm *JobManager) Started() <-chan struct{} {
+	return jm.startedCh
+}
+
 func (jm *JobManager) failZombieJobs(ctx xcontext.Context, serverID string) error {
 	zombieJobs, err := jm.listMyJobs(ctx, serverID, job.JobStateStarted)
 	if err != nil {
@@ -0,0 +1,59 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/target"
+)
+
+func targetsFromIDs(targetIDs []string) []*target.Target {
+	targets := make([]*target.Target, 0, len(targetIDs))
+	for _, id := range targetIDs {
+		targets = append(targets, &target.Target{ID: id})
+	}
+	return targets
+}
+
+func (jm *JobManager) locks(ev *api.Event) *api.EventResponse {
+	msg := ev.Msg.(api.EventLocksMsg)
+	locks, err := target.GetLocker().ListLocks(ev.Context, targetsFromIDs(msg.TargetIDs))
+	if err != nil {
+		return &api.EventResponse{Requestor: ev.Msg.Requestor(), Err: fmt.Errorf("could not list locks: %w", err)}
+	}
+	return &api.EventResponse{
+		Requestor: ev.Msg.Requestor(),
+		Locks:     locks,
+	}
+}
+
+func (jm *JobManager) unlock(ev *api.Event) *api.EventResponse {
+	ctx := ev.Context
+	msg := ev.Msg.(api.EventUnlockMsg)
+	if len(msg.TargetIDs) == 0 {
+		return &api.EventResponse{Requestor: ev.Msg.Requestor(), Err: errors.New("no targets to unlock")}
+	}
+	unlocked, err := target.GetLocker().ForceUnlock(ctx, targetsFromIDs(msg.TargetIDs))
+	if err != nil {
+		return &api.EventResponse{Requestor: ev.Msg.Requestor(), Err: fmt.Errorf("could not unlock targets: %w", err)}
+	}
+	// Record the override in the events of the jobs that held the locks. If
+	// the job is still running, it will fail to refresh its locks and abort.
+	for _, lock := range unlocked {
+		ctx.Warnf("Target %s forcibly unlocked by %s, was held by job %d", lock.TargetID, ev.Msg.Requestor(), lock.JobID)
+		payload := target.ForceUnlockPayload{Lock: lock, Requestor: string(ev.Msg.Requestor())}
+		if err := jm.emitEventPayload(ctx, lock.JobID, target.EventTargetLockForceUnlocked, payload); err != nil {
+			ctx.Errorf("Failed to emit event: %v", err)
+		}
+	}
+	return &api.EventResponse{
+		Requestor: ev.Msg.Requestor(),
+		Locks:     unlocked,
+	}
+}
@@ -22,8 +22,14 @@ type Option interface {
 
 type config struct {
 	apiOptions         []api.Option
+	serverID           string
 	instanceTag        string
 	targetLockDuration time.Duration
+	heartbeatInterval  time.Duration
+	deadServerTimeout  time.Duration
+	retentionPolicy    RetentionPolicy
+	retentionInterval  time.Duration
+	archiveDir         string
 	clock              clock.Clock
 }
 
@@ -57,6 +63,48 @@ func (opt OptionTargetLockDuration) apply(config *config) {
 	config.targetLockDuration = time.Duration(opt)
 }
 
+// OptionHeartbeatInterval wraps time.Duration to be used as an option. If set,
+// the server periodically records a heartbeat in storage and takes over the
+// jobs of dead servers with the same instance tag.
+type OptionHeartbeatInterval time.Duration
+
+func (opt OptionHeartbeatInterval) apply(config *config) {
+	config.heartbeatInterval = time.Duration(opt)
+}
+
+// OptionDeadServerTimeout wraps time.Duration to be used as an option. It is
+// the time after the last heartbeat after which a server is considered dead.
+type OptionDeadServerTimeout time.Duration
+
+func (opt OptionDeadServerTimeout) apply(config *config) {
+	config.deadServerTimeout = time.Duration(opt)
+}
+
+// OptionRetentionPolicy wraps RetentionPolicy to be used as an option. If
+// any job can expire under the policy, the server periodically purges the
+// expired jobs from storage.
+type OptionRetentionPolicy RetentionPolicy
+
+func (opt OptionRetentionPolicy) apply(config *config) {
+	config.retentionPolicy = RetentionPolicy(opt)
+}
+
+// OptionRetentionInterval wraps time.Duration to be used as an option. It is
+// the interval between two enforcements of the retention policy.
+type OptionRetentionInterval time.Duration
+
+func (opt OptionRetentionInterval) apply(config *config) {
+	config.retentionInterval = time.Duration(opt)
+}
+
+// OptionArchiveDir wraps a string to be used as an option. It is the directory
+// where jobs are archived before being purged.
+type OptionArchiveDir string
+
+func (opt OptionArchiveDir) apply(config *config) {
+	config.archiveDir = string(opt)
+}
+
 type optionClock struct {
 	clock clock.Clock
 }
@@ -74,6 +122,8 @@ func OptionClock(clk clock.Clock) Option {
 func getConfig(opts ...Option) config {
 	result := config{
 		targetLockDuration: configPkg.DefaultTargetLockDuration,
+		deadServerTimeout:  configPkg.DefaultDeadServerTimeout,
+		retentionInterval:  configPkg.DefaultRetentionInterval,
 		clock:              clock.New(),
 	}
 	for _, opt := range opts {
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+func (jm *JobManager) pause(ev *api.Event) *api.EventResponse {
+	ctx := ev.Context
+	msg := ev.Msg.(api.EventPauseMsg)
+	// PauseJob is asynchronous: the job stops at the next safe point and emits
+	// a JobStatePaused event with its resume state, or JobStatePauseFailed.
+	if err := jm.PauseJob(msg.JobID); err != nil {
+		ctx.Errorf("Cannot pause job: %v", err)
+		return &api.EventResponse{Requestor: ev.Msg.Requestor(), Err: fmt.Errorf("could not pause job: %w", err)}
+	}
+	return &api.EventResponse{
+		JobID:     msg.JobID,
+		Requestor: ev.Msg.Requestor(),
+	}
+}
+
+func (jm *JobManager) resume(ev *api.Event) *api.EventResponse {
+	ctx := ev.Context
+	msg := ev.Msg.(api.EventResumeMsg)
+	if err := jm.ResumeJob(ctx, ev.ServerID, msg.JobID); err != nil {
+		ctx.Errorf("Cannot resume job: %v", err)
+		return &api.EventResponse{Requestor: ev.Msg.Requestor(), Err: fmt.Errorf("could not resume job: %w", err)}
+	}
+	return &api.EventResponse{
+		JobID:     msg.JobID,
+		Requestor: ev.Msg.Requestor(),
+	}
+}
+
+// PauseJob requests a running job to pause. Targets stay locked while the job
+// is paused, locks are refreshed until the server exits. The job is not
+// resumed when the server starts, only by ResumeJob.
+func (jm *JobManager) PauseJob(jobID types.JobID) error {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
+	// Only jobs that we are actively handling can be paused.
+	ji, ok := jm.jobs[jobID]
+	if !ok {
+		return fmt.Errorf("unknown job ID: %d", jobID)
+	}
+	ji.pausedByUser = true
+	ji.pause()
+	return nil
+}
+
+// ResumeJob resumes a job which was paused by the server with the given ID,
+// from its last pause state.
+func (jm *JobManager) ResumeJob(ctx xcontext.Context, serverID string, jobID types.JobID) error {
+	// Serialize resume requests, so that a job is not resumed twice.
+	jm.resumeMu.Lock()
+	defer jm.resumeMu.Unlock()
+
+	jm.jobsMu.Lock()
+	_, running := jm.jobs[jobID]
+	jm.jobsMu.Unlock()
+	if running {
+		return fmt.Errorf("job %d is running", jobID)
+	}
+	pausedJobs, err := jm.listMyJobs(ctx, serverID, job.JobStatePaused)
+	if err != nil {
+		return fmt.Errorf("failed to list paused jobs: %w", err)
+	}
+	for _, pausedJobID := range pausedJobs {
+		if pausedJobID == jobID {
+			return jm.resumeJob(ctx, jobID)
+		}
+	}
+	return fmt.Errorf("job %d is not paused on server %s", jobID, serverID)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// JobArchive contains all the stored data of a job. It is written as gzipped
+// JSON before purging a job, if archiving is enabled.
+type JobArchive struct {
+	Request         *job.Request
+	Report          *job.JobReport
+	FrameworkEvents []frameworkevent.Event
+	TestEvents      []testevent.Event
+}
+
+// ArchivePath returns the path of the archive of a job in archiveDir.
+func ArchivePath(archiveDir string, jobID types.JobID) string {
+	return filepath.Join(archiveDir, fmt.Sprintf("job-%d.json.gz", jobID))
+}
+
+func (jm *JobManager) purge(ev *api.Event) *api.EventResponse {
+	msg := ev.Msg.(api.EventPurgeMsg)
+	evResp := &api.EventResponse{
+		JobID:     msg.JobID,
+		Requestor: ev.Msg.Requestor(),
+	}
+	evResp.ArchivePath, evResp.Err = jm.PurgeJob(ev.Context, msg.JobID, msg.Archive)
+	return evResp
+}
+
+// PurgeJob permanently deletes the request, reports and events of a job which
+// has reached a final state. If archive is true, the data is first written to
+// the archive directory, and the path of the archive is returned.
+func (jm *JobManager) PurgeJob(ctx xcontext.Context, jobID types.JobID, archive bool) (string, error) {
+	if archive && jm.config.archiveDir == "" {
+		return "", fmt.Errorf("cannot archive job %d: no archive directory configured", jobID)
+	}
+	jm.jobsMu.Lock()
+	_, running := jm.jobs[jobID]
+	jm.jobsMu.Unlock()
+	if running {
+		return "", fmt.Errorf("job %d is running", jobID)
+	}
+
+	ctx = storage.WithConsistencyModel(ctx, storage.ConsistentReadAfterWrite)
+	stateEvents, err := jm.frameworkEvManager.Fetch(ctx, frameworkevent.QueryJobID(jobID), frameworkevent.QueryEventNames(job.JobStateEvents))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch state events for job %d: %w", jobID, err)
+	}
+	if state := lastJobState(stateEvents); !isFinalState(state) {
+		return "", fmt.Errorf("job %d is in state %s, only finished jobs can be purged", jobID, state)
+	}
+
+	var archivePath string
+	if archive {
+		data, err := jm.fetchJobArchive(ctx, jobID)
+		if err != nil {
+			return "", err
+		}
+		archivePath = ArchivePath(jm.config.archiveDir, jobID)
+		if err := writeJobArchive(archivePath, data); err != nil {
+			return "", fmt.Errorf("failed to archive job %d: %w", jobID, err)
+		}
+	}
+	if err := jm.psm.PurgeJob(ctx, jobID); err != nil {
+		return "", fmt.Errorf("failed to purge job %d: %w", jobID, err)
+	}
+	ctx.Infof("Purged job %d", jobID)
+	return archivePath, nil
+}
+
+func (jm *JobManager) fetchJobArchive(ctx xcontext.Context, jobID types.JobID) (*JobArchive, error) {
+	req, err := jm.jsm.GetJobRequest(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch request for job %d: %w", jobID, err)
+	}
+	report, err := jm.jsm.GetJobReport(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report for job %d: %w", jobID, err)
+	}
+	frameworkEvents, err := jm.frameworkEvManager.Fetch(ctx, frameworkevent.QueryJobID(jobID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch framework events for job %d: %w", jobID, err)
+	}
+	testEvents, err := jm.testEvManager.Fetch(ctx, testevent.QueryJobID(jobID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch test events for job %d: %w", jobID, err)
+	}
+	return &JobArchive{
+		Request:         req,
+		Report:          report,
+		FrameworkEvents: frameworkEvents,
+		TestEvents:      testEvents,
+	}, nil
+}
+
+// lastJobState returns the state set by the latest job state event.
+func lastJobState(events []frameworkevent.Event) job.State {
+	state := job.JobStateUnknown
+	var lastSequenceID uint64
+	for _, ev := range events {
+		st, err := job.EventNameToJobState(ev.EventName)
+		if err != nil || ev.SequenceID < lastSequenceID {
+			continue
+		}
+		state, lastSequenceID = st, ev.SequenceID
+	}
+	return state
+}
+
+// finalStates returns the states of the jobs that will not run anymore.
+func finalStates() []job.State {
+	states := make([]job.State, 0, len(job.JobCompletionEvents))
+	for _, ev := range job.JobCompletionEvents {
+		st, _ := job.EventNameToJobState(ev)
+		states = append(states, st)
+	}
+	return states
+}
+
+func isFinalState(state job.State) bool {
+	for _, st := range finalStates() {
+		if st == state {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJobArchive writes the archive to a temporary file which is renamed
+// once complete, so that a partial archive is never mistaken for a good one.
+func writeJobArchive(path string, data *JobArchive) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Fails once renamed, which is fine.
+		_ = os.Remove(f.Name())
+	}()
+	zw := gzip.NewWriter(f)
+	if err := json.NewEncoder(zw).Encode(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
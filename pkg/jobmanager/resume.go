@@ -22,17 +22,40 @@ func (jm *JobManager) resumeJobs(ctx xcontext.Context, serverID string) error {
 	}
 	ctx.Infof("Found %d paused jobs for %s/%s", len(pausedJobs), jm.config.instanceTag, serverID)
 	for _, jobID := range pausedJobs {
-		if err := jm.resumeJob(ctx, jobID); err != nil {
-			ctx.Errorf("failed to resume job %d: %v, failing it", jobID, err)
-			if err = jm.emitErrEvent(ctx, jobID, job.EventJobFailed, fmt.Errorf("failed to resume job %d: %w", jobID, err)); err != nil {
-				ctx.Warnf("Failed to emit event for %d: %v", jobID, err)
-			}
-		}
+		jm.autoResumeJob(ctx, jobID)
 	}
 	return nil
 }
 
+// autoResumeJob resumes a job paused by a server, failing it if it cannot be
+// resumed. Jobs paused by the user stay paused until they are resumed via the API.
+func (jm *JobManager) autoResumeJob(ctx xcontext.Context, jobID types.JobID) {
+	resumeState, err := jm.fetchResumeState(ctx, jobID)
+	if err == nil && resumeState.ByUser {
+		ctx.Infof("Job %d was paused by the user, not resuming it", jobID)
+		return
+	}
+	if err == nil {
+		err = jm.startResumedJob(ctx, jobID, resumeState)
+	}
+	if err != nil {
+		ctx.Errorf("failed to resume job %d: %v, failing it", jobID, err)
+		if err = jm.emitErrEvent(ctx, jobID, job.EventJobFailed, fmt.Errorf("failed to resume job %d: %w", jobID, err)); err != nil {
+			ctx.Warnf("Failed to emit event for %d: %v", jobID, err)
+		}
+	}
+}
+
 func (jm *JobManager) resumeJob(ctx xcontext.Context, jobID types.JobID) error {
+	resumeState, err := jm.fetchResumeState(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	return jm.startResumedJob(ctx, jobID, resumeState)
+}
+
+// fetchResumeState returns the state saved when the job was last paused
+func (jm *JobManager) fetchResumeState(ctx xcontext.Context, jobID types.JobID) (*job.PauseEventPayload, error) {
 	ctx.Debugf("attempting to resume job %d", jobID)
 	results, err := jm.frameworkEvManager.Fetch(
 		ctx,
@@ -40,10 +63,10 @@ func (jm *JobManager) resumeJob(ctx xcontext.Context, jobID types.JobID) error {
 		frameworkevent.QueryEventName(job.EventJobPaused),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to query resume state for job %d: %w", jobID, err)
+		return nil, fmt.Errorf("failed to query resume state for job %d: %w", jobID, err)
 	}
 	if len(results) == 0 {
-		return fmt.Errorf("no resume state found for job %d", jobID)
+		return nil, fmt.Errorf("no resume state found for job %d", jobID)
 	}
 
 	// get the latest event by id
@@ -55,15 +78,19 @@ func (jm *JobManager) resumeJob(ctx xcontext.Context, jobID types.JobID) error {
 	}
 	var resumeState job.PauseEventPayload
 	if results[lastEventIdx].Payload == nil {
-		return fmt.Errorf("invald resume state for job %d: %+v", jobID, results[0])
+		return nil, fmt.Errorf("invald resume state for job %d: %+v", jobID, results[0])
 	}
 	if err := json.Unmarshal(*results[lastEventIdx].Payload, &resumeState); err != nil {
-		return fmt.Errorf("invald resume state for job %d: %w", jobID, err)
+		return nil, fmt.Errorf("invald resume state for job %d: %w", jobID, err)
 	}
 	if resumeState.Version != job.CurrentPauseEventPayloadVersion {
-		return fmt.Errorf("incompatible resume state version (want %d, got %d)",
+		return nil, fmt.Errorf("incompatible resume state version (want %d, got %d)",
 			job.CurrentPauseEventPayloadVersion, resumeState.Version)
 	}
+	return &resumeState, nil
+}
+
+func (jm *JobManager) startResumedJob(ctx xcontext.Context, jobID types.JobID, resumeState *job.PauseEventPayload) error {
 	req, err := jm.jsm.GetJobRequest(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve job descriptor for %d: %w", jobID, err)
@@ -74,6 +101,6 @@ func (jm *JobManager) resumeJob(ctx xcontext.Context, jobID types.JobID) error {
 	}
 	j.ID = jobID
 	ctx.Debugf("running resumed job %d", j.ID)
-	jm.startJob(ctx, j, &resumeState)
+	jm.startJob(ctx, j, resumeState)
 	return nil
 }
@@ -0,0 +1,237 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// RetentionPolicy defines for how long finished jobs are kept in storage. A
+// retention of zero means forever. A job carrying tags with a retention is
+// kept for the longest of them, other jobs are kept for MaxAge.
+type RetentionPolicy struct {
+	MaxAge    time.Duration
+	TagMaxAge map[string]time.Duration
+}
+
+// ParseRetentionPolicy parses a comma-separated list of retentions, each
+// either a duration which applies to all jobs, or tag=duration which applies
+// to the jobs with the tag. For example, "720h,nightly=168h,release=0" keeps
+// jobs for 30 days, nightly ones for 7 days and release ones forever.
+func ParseRetentionPolicy(s string) (RetentionPolicy, error) {
+	var policy RetentionPolicy
+	if strings.TrimSpace(s) == "" {
+		return policy, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		tag, durStr := "", item
+		if idx := strings.Index(item, "="); idx >= 0 {
+			tag, durStr = item[:idx], item[idx+1:]
+			if err := job.CheckTags([]string{tag}, true /* allowInternal */); err != nil {
+				return RetentionPolicy{}, fmt.Errorf("invalid retention %q: %w", item, err)
+			}
+		}
+		dur, err := time.ParseDuration(durStr)
+		if err != nil {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention %q: %w", item, err)
+		}
+		if dur < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention %q: negative duration", item)
+		}
+		if tag == "" {
+			policy.MaxAge = dur
+			continue
+		}
+		if policy.TagMaxAge == nil {
+			policy.TagMaxAge = make(map[string]time.Duration)
+		}
+		if _, ok := policy.TagMaxAge[tag]; ok {
+			return RetentionPolicy{}, fmt.Errorf("duplicate retention for tag %q", tag)
+		}
+		policy.TagMaxAge[tag] = dur
+	}
+	return policy, nil
+}
+
+// Enabled returns whether any job can expire under the policy.
+func (p RetentionPolicy) Enabled() bool {
+	if p.MaxAge > 0 {
+		return true
+	}
+	for _, maxAge := range p.TagMaxAge {
+		if maxAge > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// longer returns whether retention a keeps jobs for longer than b.
+func longer(a, b time.Duration) bool {
+	if b == 0 {
+		return false
+	}
+	return a == 0 || a > b
+}
+
+// listFinishedJobs lists the finished jobs of this instance with the given
+// tags, requested before the given time if not zero.
+func (jm *JobManager) listFinishedJobs(ctx xcontext.Context, tags []string, requestedBefore time.Time) (map[types.JobID]bool, error) {
+	if jm.config.instanceTag != "" {
+		tags = append(tags, jm.config.instanceTag)
+	}
+	queryFields := []storage.JobQueryField{storage.QueryJobStates(finalStates()...)}
+	if len(tags) > 0 {
+		queryFields = append(queryFields, storage.QueryJobTags(tags...))
+	}
+	if !requestedBefore.IsZero() {
+		queryFields = append(queryFields, storage.QueryJobRequestedBefore(requestedBefore))
+	}
+	query, err := storage.BuildJobQuery(queryFields...)
+	if err != nil {
+		return nil, err
+	}
+	jobIDs, err := jm.jsm.ListJobs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[types.JobID]bool, len(jobIDs))
+	for _, jobID := range jobIDs {
+		res[jobID] = true
+	}
+	return res, nil
+}
+
+// expiredJobs returns the finished jobs which have exceeded the retention
+// policy as of now, in ascending order.
+func (jm *JobManager) expiredJobs(ctx xcontext.Context, now time.Time) ([]types.JobID, error) {
+	policy := jm.config.retentionPolicy
+	ctx = storage.WithConsistencyModel(ctx, storage.ConsistentReadAfterWrite)
+	// Jobs with each of the tags in the policy, regardless of their age.
+	tagged := make(map[string]map[types.JobID]bool, len(policy.TagMaxAge))
+	for tag := range policy.TagMaxAge {
+		jobIDs, err := jm.listFinishedJobs(ctx, []string{tag}, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs with tag %q: %w", tag, err)
+		}
+		tagged[tag] = jobIDs
+	}
+
+	// The requested before time of each expired job.
+	expired := make(map[types.JobID]time.Time)
+	if policy.MaxAge > 0 {
+		jobIDs, err := jm.listFinishedJobs(ctx, nil, now.Add(-policy.MaxAge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+	defaultLoop:
+		for jobID := range jobIDs {
+			// Tag retentions take precedence over the default one.
+			for _, tagJobIDs := range tagged {
+				if tagJobIDs[jobID] {
+					continue defaultLoop
+				}
+			}
+			expired[jobID] = now.Add(-policy.MaxAge)
+		}
+	}
+	for tag, maxAge := range policy.TagMaxAge {
+		if maxAge == 0 {
+			continue
+		}
+		jobIDs, err := jm.listFinishedJobs(ctx, []string{tag}, now.Add(-maxAge))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs with tag %q: %w", tag, err)
+		}
+	tagLoop:
+		for jobID := range jobIDs {
+			// The job may be kept longer because of another tag.
+			for otherTag, otherMaxAge := range policy.TagMaxAge {
+				if longer(otherMaxAge, maxAge) && tagged[otherTag][jobID] {
+					continue tagLoop
+				}
+			}
+			expired[jobID] = now.Add(-maxAge)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	// The retention of imported jobs counts from their import.
+	oldest := now
+	for _, requestedBefore := range expired {
+		if requestedBefore.Before(oldest) {
+			oldest = requestedBefore
+		}
+	}
+	imports, err := jm.frameworkEvManager.Fetch(ctx,
+		frameworkevent.QueryEventName(job.EventJobImported),
+		frameworkevent.QueryEmittedStartTime(oldest),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported jobs: %w", err)
+	}
+	for _, ev := range imports {
+		if requestedBefore, ok := expired[ev.JobID]; ok && ev.EmitTime.After(requestedBefore) {
+			delete(expired, ev.JobID)
+		}
+	}
+
+	res := make([]types.JobID, 0, len(expired))
+	for jobID := range expired {
+		res = append(res, jobID)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// enforceRetention purges the expired jobs, archiving them first if an
+// archive directory is configured.
+func (jm *JobManager) enforceRetention(ctx xcontext.Context) {
+	jobIDs, err := jm.expiredJobs(ctx, jm.config.clock.Now())
+	if err != nil {
+		ctx.Errorf("Failed to find expired jobs: %v", err)
+		return
+	}
+	if len(jobIDs) == 0 {
+		return
+	}
+	ctx.Infof("Purging %d expired jobs", len(jobIDs))
+	for _, jobID := range jobIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := jm.PurgeJob(ctx, jobID, jm.config.archiveDir != ""); err != nil {
+			ctx.Errorf("Failed to purge expired job %d: %v", jobID, err)
+		}
+	}
+}
+
+// retentionLoop enforces the retention policy periodically until ctx is done.
+func (jm *JobManager) retentionLoop(ctx xcontext.Context, doneCh chan<- struct{}) {
+	defer close(doneCh)
+	ticker := jm.config.clock.Ticker(jm.config.retentionInterval)
+	defer ticker.Stop()
+	for {
+		jm.enforceRetention(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	policy, err := ParseRetentionPolicy("720h, nightly=168h,release=0")
+	require.NoError(t, err)
+	require.Equal(t, RetentionPolicy{
+		MaxAge:    720 * time.Hour,
+		TagMaxAge: map[string]time.Duration{"nightly": 168 * time.Hour, "release": 0},
+	}, policy)
+	require.True(t, policy.Enabled())
+
+	policy, err = ParseRetentionPolicy("")
+	require.NoError(t, err)
+	require.False(t, policy.Enabled())
+	policy, err = ParseRetentionPolicy("release=0")
+	require.NoError(t, err)
+	require.False(t, policy.Enabled())
+
+	for _, s := range []string{"forever", "-1h", "nightly=1h,nightly=2h", "bad tag=1h", "nightly="} {
+		_, err := ParseRetentionPolicy(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestExpiredJobs(t *testing.T) {
+	policy, err := ParseRetentionPolicy("720h,nightly=168h,release=0,weekly=336h")
+	require.NoError(t, err)
+	f := newStorageFixture(t, OptionRetentionPolicy(policy))
+	day := 24 * time.Hour
+
+	oldJob := f.storeJob(t, 40*day, job.EventJobCompleted)
+	f.storeJob(t, 20*day, job.EventJobFailed)
+	f.storeJob(t, 40*day, job.EventJobStarted)
+	oldNightly := f.storeJob(t, 8*day, job.EventJobCancelled, "nightly")
+	f.storeJob(t, 6*day, job.EventJobCompleted, "nightly")
+	f.storeJob(t, 40*day, job.EventJobCompleted, "nightly", "release")
+	f.storeJob(t, 8*day, job.EventJobCompleted, "nightly", "weekly")
+	oldWeekly := f.storeJob(t, 15*day, job.EventJobCompleted, "nightly", "weekly")
+
+	expired, err := f.jm.expiredJobs(f.ctx, f.now)
+	require.NoError(t, err)
+	require.Equal(t, []types.JobID{oldJob, oldNightly, oldWeekly}, expired)
+}
+
+func TestPurgeJobArchive(t *testing.T) {
+	archiveDir := t.TempDir()
+	f := newStorageFixture(t, OptionArchiveDir(archiveDir))
+	jobID := f.storeJob(t, time.Hour, job.EventJobCompleted)
+	require.NoError(t, f.storage.StoreTestEvent(f.ctx, testevent.Event{
+		EmitTime: f.now,
+		Header:   &testevent.Header{JobID: jobID, RunID: 1, TestName: "test"},
+		Data:     &testevent.Data{EventName: "start"},
+	}))
+	runningJobID := f.storeJob(t, time.Hour, job.EventJobPaused)
+
+	_, err := f.jm.PurgeJob(f.ctx, runningJobID, false)
+	require.Error(t, err)
+
+	archivePath, err := f.jm.PurgeJob(f.ctx, jobID, true)
+	require.NoError(t, err)
+	require.Equal(t, ArchivePath(archiveDir, jobID), archivePath)
+	_, err = f.storage.GetJobRequest(f.ctx, jobID)
+	require.Error(t, err)
+
+	file, err := os.Open(archivePath)
+	require.NoError(t, err)
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	var archive JobArchive
+	require.NoError(t, json.NewDecoder(zr).Decode(&archive))
+	require.Equal(t, jobID, archive.Request.JobID)
+	require.Len(t, archive.FrameworkEvents, 2)
+	require.Len(t, archive.TestEvents, 1)
+	require.Equal(t, "start", string(archive.TestEvents[0].Data.EventName))
+
+	// The job is gone, so it cannot be purged again.
+	_, err = f.jm.PurgeJob(f.ctx, jobID, false)
+	require.Error(t, err)
+
+	// Without archive, no archive is written.
+	otherJobID := f.storeJob(t, time.Hour, job.EventJobFailed)
+	archivePath, err = f.jm.PurgeJob(f.ctx, otherJobID, false)
+	require.NoError(t, err)
+	require.Empty(t, archivePath)
+	_, err = os.Stat(ArchivePath(archiveDir, otherJobID))
+	require.True(t, os.IsNotExist(err))
+	_, err = f.storage.GetJobRequest(f.ctx, otherJobID)
+	require.Error(t, err)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/linuxboot/contest/pkg/api"
 	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
 	"github.com/linuxboot/contest/pkg/xcontext/metrics/perf"
 )
@@ -19,28 +20,60 @@ import (
 func (jm *JobManager) start(ev *api.Event) *api.EventResponse {
 	msg := ev.Msg.(api.EventStartMsg)
 
+	j, err := jm.startFromDescriptor(ev.Context, ev.Msg.Requestor(), ev.ServerID, msg.JobDescriptor)
+	if err != nil {
+		return &api.EventResponse{
+			Requestor: ev.Msg.Requestor(),
+			Err:       err,
+		}
+	}
+
+	return &api.EventResponse{
+		JobID:     j.ID,
+		Requestor: ev.Msg.Requestor(),
+		Err:       nil,
+		Status: &job.Status{
+			Name:      j.Name,
+			State:     string(job.EventJobStarted),
+			StartTime: time.Now(),
+		},
+	}
+}
+
+// StartJob creates and starts a new job from a JSON job descriptor, the same
+// way the Start API method does, but without going through the API listener.
+// It returns the ID of the new job.
+func (jm *JobManager) StartJob(ctx xcontext.Context, requestor api.EventRequestor, jobDescriptor string) (types.JobID, error) {
+	j, err := jm.startFromDescriptor(ctx, requestor, jm.config.serverID, jobDescriptor)
+	if err != nil {
+		return 0, err
+	}
+	return j.ID, nil
+}
+
+func (jm *JobManager) startFromDescriptor(ctx xcontext.Context, requestor api.EventRequestor, serverID string, jobDescriptor string) (*job.Job, error) {
 	var jd job.Descriptor
-	if err := json.Unmarshal([]byte(msg.JobDescriptor), &jd); err != nil {
-		return &api.EventResponse{Err: err}
+	if err := json.Unmarshal([]byte(jobDescriptor), &jd); err != nil {
+		return nil, err
 	}
 	// Check the compatibility of the JobDescriptor
 	if err := jd.CheckVersion(); err != nil {
-		return &api.EventResponse{Err: err}
+		return nil, err
 	}
 	if err := job.CheckTags(jd.Tags, false /* allowInternal */); err != nil {
-		return &api.EventResponse{Err: err}
+		return nil, err
 	}
 	// Add instance tag, if specified.
 	if jm.config.instanceTag != "" {
 		jd.Tags = job.AddTags(jd.Tags, jm.config.instanceTag)
 	}
-	j, err := NewJobFromDescriptor(ev.Context, jm.pluginRegistry, &jd)
+	j, err := NewJobFromDescriptor(ctx, jm.pluginRegistry, &jd)
 	if err != nil {
-		return &api.EventResponse{Err: err}
+		return nil, err
 	}
 	jdJSON, err := json.MarshalIndent(&jd, "", "    ")
 	if err != nil {
-		return &api.EventResponse{Err: err}
+		return nil, err
 	}
 
 	// The job descriptor has been validated correctly, now use the JobRequestEmitter
@@ -49,31 +82,19 @@ func (jm *JobManager) start(ev *api.Event) *api.EventResponse {
 		JobName:            j.Name,
 		JobDescriptor:      string(jdJSON),
 		ExtendedDescriptor: j.ExtendedDescriptor,
-		Requestor:          string(ev.Msg.Requestor()),
-		ServerID:           ev.ServerID,
+		Requestor:          string(requestor),
+		ServerID:           serverID,
 		RequestTime:        time.Now(),
 	}
-	jobID, err := jm.jsm.StoreJobRequest(ev.Context, &request)
+	jobID, err := jm.jsm.StoreJobRequest(ctx, &request)
 	if err != nil {
-		return &api.EventResponse{
-			Requestor: ev.Msg.Requestor(),
-			Err:       fmt.Errorf("could not create job request: %v", err)}
+		return nil, fmt.Errorf("could not create job request: %v", err)
 	}
 
 	j.ID = jobID
 
-	jm.startJob(ev.Context, j, nil)
-
-	return &api.EventResponse{
-		JobID:     j.ID,
-		Requestor: ev.Msg.Requestor(),
-		Err:       nil,
-		Status: &job.Status{
-			Name:      j.Name,
-			State:     string(job.EventJobStarted),
-			StartTime: time.Now(),
-		},
-	}
+	jm.startJob(ctx, j, nil)
+	return j, nil
 }
 
 func (jm *JobManager) startJob(ctx xcontext.Context, j *job.Job, resumeState *job.PauseEventPayload) {
@@ -115,6 +136,9 @@ func (jm *JobManager) runJob(ctx xcontext.Context, j *job.Job, resumeState *job.
 		_ = jm.emitEvent(ctx, j.ID, job.EventJobCancelled)
 		return
 	case xcontext.ErrPaused:
+		jm.jobsMu.Lock()
+		resumeState.ByUser = jm.jobs[j.ID].pausedByUser
+		jm.jobsMu.Unlock()
 		if err := jm.emitEventPayload(ctx, j.ID, job.EventJobPaused, resumeState); err != nil {
 			_ = jm.emitErrEvent(ctx, j.ID, job.EventJobPauseFailed, fmt.Errorf("Job %+v failed pausing: %v", j, err))
 		} else {
@@ -17,6 +17,8 @@ import (
 	"github.com/linuxboot/contest/pkg/event/frameworkevent"
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
 )
 
 func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
@@ -29,20 +31,50 @@ func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
 		Requestor: ev.Msg.Requestor(),
 		Err:       nil,
 	}
+	evResp.Status, evResp.Err = jm.JobStatusPage(ctx, jobID, msg.RunsAfter, msg.MaxRuns)
+	return &evResp
+}
+
+func (jm *JobManager) diff(ev *api.Event) *api.EventResponse {
+	ctx := storage.WithConsistencyModel(ev.Context, storage.ConsistentEventually)
+
+	msg := ev.Msg.(api.EventDiffMsg)
+	evResp := &api.EventResponse{
+		Requestor: ev.Msg.Requestor(),
+	}
+	statusA, err := jm.JobStatus(ctx, msg.JobA)
+	if err != nil {
+		evResp.Err = fmt.Errorf("could not build the status of job %d: %w", msg.JobA, err)
+		return evResp
+	}
+	statusB, err := jm.JobStatus(ctx, msg.JobB)
+	if err != nil {
+		evResp.Err = fmt.Errorf("could not build the status of job %d: %w", msg.JobB, err)
+		return evResp
+	}
+	evResp.Diff = job.DiffStatuses(msg.JobA, statusA, msg.JobB, statusB)
+	return evResp
+}
+
+// JobStatus builds the status of the job with the given ID, the same way the
+// Status API method does, but without going through the API listener.
+func (jm *JobManager) JobStatus(ctx xcontext.Context, jobID types.JobID) (*job.Status, error) {
+	return jm.JobStatusPage(ctx, jobID, 0, 0)
+}
 
+// JobStatusPage is like JobStatus, but only builds the statuses of at most
+// maxRuns runs following the run runsAfter. A maxRuns of 0 means no limit.
+func (jm *JobManager) JobStatusPage(ctx xcontext.Context, jobID types.JobID, runsAfter types.RunID, maxRuns uint) (*job.Status, error) {
 	// Look up job request.
 	req, err := jm.jsm.GetJobRequest(ctx, jobID)
 	if err != nil {
-		evResp.Err = fmt.Errorf("failed to fetch request for job ID %d: %w", jobID, err)
-		return &evResp
+		return nil, fmt.Errorf("failed to fetch request for job ID %d: %w", jobID, err)
 	}
 
 	currentJob, err := NewJobFromExtendedDescriptor(ctx, jm.pluginRegistry, req.ExtendedDescriptor)
 	if err != nil {
-		evResp.Err = fmt.Errorf("failed to build job object from job request: %w", err)
-		return &evResp
+		return nil, fmt.Errorf("failed to build job object from job request: %w", err)
 	}
-
 	// currentJob temporary object is just used as an interface to the job extended descriptor
 	// so populate it with the other necessary fields such as id (currently 0)
 	currentJob.ID = jobID
@@ -57,9 +89,8 @@ func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
 			}
 		}
 		if !found {
-			evResp.Err = fmt.Errorf("job %d belongs to a different instance, this is %q",
+			return nil, fmt.Errorf("job %d belongs to a different instance, this is %q",
 				jobID, jm.config.instanceTag)
-			return &evResp
 		}
 	}
 
@@ -69,8 +100,7 @@ func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
 		frameworkevent.QueryEventNames(job.JobStateEvents),
 	)
 	if err != nil {
-		evResp.Err = fmt.Errorf("could not fetch events associated to job state: %v", err)
-		return &evResp
+		return nil, fmt.Errorf("could not fetch events associated to job state: %v", err)
 	}
 
 	// Lookup job starting time and job termination time based on the events emitted
@@ -125,8 +155,7 @@ func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
 
 	report, err := jm.jsm.GetJobReport(ctx, jobID)
 	if err != nil {
-		evResp.Err = fmt.Errorf("could not fetch job report: %v", err)
-		return &evResp
+		return nil, fmt.Errorf("could not fetch job report: %v", err)
 	}
 
 	jobStatus := job.Status{
@@ -138,18 +167,19 @@ func (jm *JobManager) status(ev *api.Event) *api.EventResponse {
 		JobReport:   report,
 	}
 
-	jobStatus.RunStatuses, err = jm.jobRunner.BuildRunStatuses(ctx, currentJob)
+	jobStatus.NumRuns, err = jm.jobRunner.LastRunID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("could not rebuild the statuses of the job: %v", err)
+	}
+	jobStatus.RunStatuses, err = jm.jobRunner.BuildRunStatusesPage(ctx, currentJob, runsAfter, maxRuns)
 	if err != nil {
-		evResp.Err = fmt.Errorf("could not rebuild the statuses of the job: %v", err)
-		return &evResp
+		return nil, fmt.Errorf("could not rebuild the statuses of the job: %v", err)
 	}
 
-	if len(jobStatus.RunStatuses) > 0 {
+	if n := len(jobStatus.RunStatuses); n > 0 && jobStatus.RunStatuses[n-1].RunID == jobStatus.NumRuns {
 		// NOTE: deprecated, keeping for backwards compat
 		jobStatus.RunStatus = &jobStatus.RunStatuses[len(jobStatus.RunStatuses)-1]
 	}
 
-	evResp.Status = &jobStatus
-	evResp.Err = nil
-	return &evResp
+	return &jobStatus, nil
 }
@@ -0,0 +1,141 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// heartbeat records in storage that this server is alive.
+func (jm *JobManager) heartbeat(ctx xcontext.Context, serverID string) error {
+	return jm.ssm.StoreServerHeartbeat(ctx, storage.ServerInfo{
+		ServerID:    serverID,
+		InstanceTag: jm.config.instanceTag,
+		Heartbeat:   jm.config.clock.Now(),
+	})
+}
+
+// claimOwnJobs is called on startup, before dealing with zombie and paused
+// jobs of a previous instance of this server. It records a heartbeat and
+// acquires the lease on this server's jobs, so that other servers will not
+// take them over concurrently. It returns false if another server holds the
+// lease, i.e. it is taking over the jobs right now.
+func (jm *JobManager) claimOwnJobs(ctx xcontext.Context, serverID string) (bool, error) {
+	if err := jm.heartbeat(ctx, serverID); err != nil {
+		return false, fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	now := jm.config.clock.Now()
+	acquired, err := jm.ssm.AcquireServerLease(ctx, serverID, serverID, now, now.Add(jm.config.deadServerTimeout))
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return acquired, nil
+}
+
+// heartbeatLoop periodically records a heartbeat for this server until stopCh
+// is closed or ctx is done. Heartbeats keep going while jobs are being paused
+// on shutdown, so that other servers do not fail them as zombies. Until
+// takeoverCtx is done, it also looks for dead servers with the same instance
+// tag and takes over their jobs.
+func (jm *JobManager) heartbeatLoop(ctx, takeoverCtx xcontext.Context, serverID string, stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+	ticker := jm.config.clock.Ticker(jm.config.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := jm.heartbeat(ctx, serverID); err != nil {
+			ctx.Errorf("Failed to record heartbeat: %v", err)
+			continue
+		}
+		jm.takeoverMu.Lock()
+		if takeoverCtx.Err() == nil {
+			jm.takeOverDeadServers(ctx, serverID)
+		}
+		jm.takeoverMu.Unlock()
+	}
+}
+
+// takeOverDeadServers looks for servers whose heartbeat is older than the dead
+// server timeout, and takes over their jobs if it can acquire their lease.
+// Once all their jobs are taken over, the dead servers are forgotten, so that
+// they are not looked at again unless they come back.
+func (jm *JobManager) takeOverDeadServers(ctx xcontext.Context, serverID string) {
+	servers, err := jm.ssm.ListServers(ctx, jm.config.instanceTag)
+	if err != nil {
+		ctx.Errorf("Failed to list servers: %v", err)
+		return
+	}
+	now := jm.config.clock.Now()
+	for _, server := range servers {
+		if server.ServerID == serverID || now.Sub(server.Heartbeat) < jm.config.deadServerTimeout {
+			continue
+		}
+		acquired, err := jm.ssm.AcquireServerLease(ctx, server.ServerID, serverID, now, now.Add(jm.config.deadServerTimeout))
+		if err != nil {
+			ctx.Errorf("Failed to acquire lease on server %s: %v", server.ServerID, err)
+			continue
+		}
+		if !acquired {
+			ctx.Debugf("Server %s is dead, but another server holds its lease", server.ServerID)
+			continue
+		}
+		ctx.Infof("Server %s is dead (last heartbeat at %s), taking over its jobs", server.ServerID, server.Heartbeat.Format(time.RFC3339))
+		if err := jm.takeOverJobs(ctx, server.ServerID, serverID); err != nil {
+			ctx.Errorf("Failed to take over jobs of server %s: %v", server.ServerID, err)
+			continue
+		}
+		if err := jm.ssm.RemoveDeadServer(ctx, server.ServerID, serverID, now.Add(-jm.config.deadServerTimeout)); err != nil {
+			ctx.Errorf("Failed to remove dead server %s: %v", server.ServerID, err)
+		}
+	}
+}
+
+// takeOverJobs adopts the jobs of a dead server: paused jobs are reassigned to
+// this server and resumed, unless the user paused them, while jobs which were
+// running cannot be resumed and are marked as failed.
+func (jm *JobManager) takeOverJobs(ctx xcontext.Context, deadServerID, serverID string) error {
+	zombieJobs, err := jm.listMyJobs(ctx, deadServerID, job.JobStateStarted)
+	if err != nil {
+		return fmt.Errorf("failed to list running jobs: %w", err)
+	}
+	for _, jobID := range zombieJobs {
+		jobCtx := ctx.WithField("job_id", jobID)
+		jobCtx.Errorf("Server %s running this job died, failing it", deadServerID)
+		if err := jm.emitErrEvent(ctx, jobID, job.EventJobFailed, fmt.Errorf("Job %d failed because server %s died", jobID, deadServerID)); err != nil {
+			ctx.Errorf("Failed to emit event: %v", err)
+		}
+	}
+
+	pausedJobs, err := jm.listMyJobs(ctx, deadServerID, job.JobStatePaused)
+	if err != nil {
+		return fmt.Errorf("failed to list paused jobs: %w", err)
+	}
+	ctx.Infof("Taking over %d paused jobs of %s/%s", len(pausedJobs), jm.config.instanceTag, deadServerID)
+	var failed int
+	for _, jobID := range pausedJobs {
+		if err := jm.ssm.UpdateJobServerID(ctx, jobID, serverID); err != nil {
+			ctx.Errorf("Failed to take over job %d: %v", jobID, err)
+			failed++
+			continue
+		}
+		jm.autoResumeJob(ctx, jobID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to take over %d of %d paused jobs", failed, len(pausedJobs))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
+)
+
+const testDeadServerTimeout = time.Minute
+
+// storeServerJob stores a job of the given server, in the state set by the given event.
+func (f *storageFixture) storeServerJob(t *testing.T, serverID string, stateEvent event.Name) types.JobID {
+	jobID := f.storeJob(t, time.Hour, stateEvent)
+	require.NoError(t, f.jm.ssm.UpdateJobServerID(f.ctx, jobID, serverID))
+	return jobID
+}
+
+func (f *storageFixture) jobServerID(t *testing.T, jobID types.JobID) string {
+	req, err := f.storage.GetJobRequest(f.ctx, jobID)
+	require.NoError(t, err)
+	return req.ServerID
+}
+
+func (f *storageFixture) serverIDs(t *testing.T) []string {
+	servers, err := f.jm.ssm.ListServers(f.ctx, f.jm.config.instanceTag)
+	require.NoError(t, err)
+	var serverIDs []string
+	for _, server := range servers {
+		serverIDs = append(serverIDs, server.ServerID)
+	}
+	return serverIDs
+}
+
+func (f *storageFixture) countEvents(t *testing.T, jobID types.JobID, name event.Name) int {
+	events, err := f.jm.frameworkEvManager.Fetch(f.ctx, frameworkevent.QueryJobID(jobID), frameworkevent.QueryEventName(name))
+	require.NoError(t, err)
+	return len(events)
+}
+
+func TestTakeOverDeadServer(t *testing.T) {
+	f := newStorageFixture(t, OptionDeadServerTimeout(testDeadServerTimeout))
+	require.NoError(t, f.jm.heartbeat(f.ctx, "dead"))
+	f.clock.Add(2 * testDeadServerTimeout)
+	require.NoError(t, f.jm.heartbeat(f.ctx, "live"))
+	require.NoError(t, f.jm.heartbeat(f.ctx, "me"))
+
+	deadRunning := f.storeServerJob(t, "dead", job.EventJobStarted)
+	deadPaused := f.storeServerJob(t, "dead", job.EventJobPaused)
+	liveRunning := f.storeServerJob(t, "live", job.EventJobStarted)
+	livePaused := f.storeServerJob(t, "live", job.EventJobPaused)
+
+	f.jm.takeOverDeadServers(f.ctx, "me")
+
+	// the running job of the dead server cannot be resumed and is failed
+	require.Equal(t, 1, f.countEvents(t, deadRunning, job.EventJobFailed))
+	require.Equal(t, "dead", f.jobServerID(t, deadRunning))
+	// the paused one is taken over, resuming it fails as it has no resume state
+	require.Equal(t, "me", f.jobServerID(t, deadPaused))
+	require.Equal(t, 1, f.countEvents(t, deadPaused, job.EventJobFailed))
+
+	// the jobs of the live server are left alone
+	for _, jobID := range []types.JobID{liveRunning, livePaused} {
+		require.Equal(t, "live", f.jobServerID(t, jobID))
+		require.Zero(t, f.countEvents(t, jobID, job.EventJobFailed))
+	}
+
+	// the dead server is forgotten once its jobs are taken over
+	require.NotContains(t, f.serverIDs(t), "dead")
+	f.clock.Add(2 * testDeadServerTimeout)
+	require.NoError(t, f.jm.heartbeat(f.ctx, "me"))
+	f.jm.takeOverDeadServers(f.ctx, "me")
+	require.Equal(t, 1, f.countEvents(t, deadRunning, job.EventJobFailed))
+	require.Equal(t, 1, f.countEvents(t, deadPaused, job.EventJobFailed))
+
+	// the dead server coming back claims its lease again, its jobs are gone
+	restarted := f.newJobManager(t, OptionDeadServerTimeout(testDeadServerTimeout))
+	claimed, err := restarted.claimOwnJobs(f.ctx, "dead")
+	require.NoError(t, err)
+	require.True(t, claimed)
+	require.Contains(t, f.serverIDs(t), "dead")
+	require.Equal(t, "me", f.jobServerID(t, deadPaused))
+}
+
+func TestTakeOverRace(t *testing.T) {
+	f := newStorageFixture(t, OptionDeadServerTimeout(testDeadServerTimeout))
+	other := f.newJobManager(t, OptionDeadServerTimeout(testDeadServerTimeout))
+	require.NoError(t, f.jm.heartbeat(f.ctx, "dead"))
+	f.clock.Add(2 * testDeadServerTimeout)
+	require.NoError(t, f.jm.heartbeat(f.ctx, "me"))
+	require.NoError(t, other.heartbeat(f.ctx, "other"))
+
+	deadRunning := f.storeServerJob(t, "dead", job.EventJobStarted)
+	deadPaused := f.storeServerJob(t, "dead", job.EventJobPaused)
+
+	var wg sync.WaitGroup
+	for jm, serverID := range map[*JobManager]string{f.jm: "me", other: "other"} {
+		wg.Add(1)
+		go func(jm *JobManager, serverID string) {
+			defer wg.Done()
+			jm.takeOverDeadServers(f.ctx, serverID)
+		}(jm, serverID)
+	}
+	wg.Wait()
+
+	// only the server holding the lease takes over the jobs
+	require.Equal(t, 1, f.countEvents(t, deadRunning, job.EventJobFailed))
+	require.Equal(t, 1, f.countEvents(t, deadPaused, job.EventJobFailed))
+	winner := f.jobServerID(t, deadPaused)
+	require.Contains(t, []string{"me", "other"}, winner)
+
+	// the winner forgets the dead server, so the loser cannot take them over again
+	require.NotContains(t, f.serverIDs(t), "dead")
+	loser, loserID := other, "other"
+	if winner == "other" {
+		loser, loserID = f.jm, "me"
+	}
+	loser.takeOverDeadServers(f.ctx, loserID)
+	require.Equal(t, 1, f.countEvents(t, deadPaused, job.EventJobFailed))
+	require.Equal(t, winner, f.jobServerID(t, deadPaused))
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jobmanager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/pluginregistry"
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+func (jm *JobManager) validate(ev *api.Event) *api.EventResponse {
+	msg := ev.Msg.(api.EventValidateMsg)
+
+	var jd job.Descriptor
+	if err := json.Unmarshal([]byte(msg.JobDescriptor), &jd); err != nil {
+		return &api.EventResponse{
+			Requestor: ev.Msg.Requestor(),
+			Err:       fmt.Errorf("could not parse job descriptor: %w", err),
+		}
+	}
+	return &api.EventResponse{
+		Requestor:        ev.Msg.Requestor(),
+		ValidationErrors: ValidateJobDescriptor(ev.Context, jm.pluginRegistry, &jd),
+	}
+}
+
+// ValidateJobDescriptor runs the same checks that are performed when a job is
+// started (version check, test fetching, reporter, target manager and test step
+// parameter validation), without storing or running anything. Unlike job
+// creation, it does not stop at the first problem: all the errors found are
+// returned, each one associated with the test and step it belongs to.
+// An empty result means that the descriptor is valid.
+func ValidateJobDescriptor(ctx xcontext.Context, registry *pluginregistry.PluginRegistry, jobDescriptor *job.Descriptor) []job.ValidationError {
+	var errs []job.ValidationError
+	if err := jobDescriptor.CheckVersion(); err != nil {
+		errs = append(errs, job.NewValidationError("", "", err))
+	}
+	if err := job.CheckTags(jobDescriptor.Tags, false /* allowInternal */); err != nil {
+		errs = append(errs, job.NewValidationError("", "", err))
+	}
+	errs = append(errs, checkJobDescriptor(jobDescriptor)...)
+	_, _, reportingErrs := newReportingBundles(registry, jobDescriptor)
+	errs = append(errs, reportingErrs...)
+
+	for idx, td := range jobDescriptor.TestDescriptors {
+		_, bundleTestFetcher, testErrs := newTestBundles(ctx, registry, idx, td)
+		errs = append(errs, testErrs...)
+		if bundleTestFetcher == nil {
+			continue
+		}
+		testName, stepDescriptors, err := bundleTestFetcher.TestFetcher.Fetch(ctx, bundleTestFetcher.FetchParameters)
+		if err != nil {
+			errs = append(errs, job.NewValidationError("", "", fmt.Errorf("test descriptor #%d: could not fetch test: %w", idx, err)))
+			continue
+		}
+		_, stepErrs := newStepBundles(ctx, test.TestStepsDescriptors{TestName: testName, TestSteps: stepDescriptors}, registry)
+		errs = append(errs, stepErrs...)
+	}
+	return errs
+}
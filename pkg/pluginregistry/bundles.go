@@ -34,11 +34,21 @@ func (r *PluginRegistry) NewTestStepBundle(ctx xcontext.Context, testStepDescrip
 		return nil, ErrInvalidStepLabelFormat{InvalidName: label, Err: err}
 	}
 
+	limits := testStepDescriptor.StepLimits()
+	if err := limits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid limits for test step %s: %w", label, err)
+	}
+	if err := test.CheckStepLimits(testStep, limits); err != nil {
+		return nil, fmt.Errorf("invalid limits for test step %s: %w", label, err)
+	}
+
 	testStepBundle := test.TestStepBundle{
 		TestStep:      testStep,
 		TestStepLabel: label,
 		Parameters:    testStepDescriptor.Parameters,
 		AllowedEvents: allowedEvents,
+		Loop:          testStepDescriptor.Loop,
+		Limits:        limits,
 	}
 	return &testStepBundle, nil
 }
@@ -0,0 +1,608 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPollChunk is the maximum number of bytes of each output stream returned by a poll
+const MaxPollChunk = 1 << 20
+
+var ErrUnknownSession = errors.New("unknown session")
+
+// DefaultDaemonSocket returns the unix socket the daemon of the current user listens on
+func DefaultDaemonSocket() string {
+	return fmt.Sprintf("/tmp/exec_agent_%d.sock", os.Getuid())
+}
+
+// DefaultSpoolDir returns the directory where the daemon of the current user spools the output
+func DefaultSpoolDir() string {
+	return fmt.Sprintf("/tmp/exec_agent_%d_spool", os.Getuid())
+}
+
+// SessionLimits are the resource limits applied to a session
+type SessionLimits struct {
+	// TimeQuota is the time after which the process is killed; 0 means infinite
+	TimeQuota time.Duration
+	// MaxOutput is the maximum number of bytes spooled for each output stream,
+	// the rest is discarded; 0 means no limit
+	MaxOutput int64
+}
+
+// spoolFile is an output stream of a process spooled to disk, that can be read
+// from any offset while it is written
+type spoolFile struct {
+	f   *os.File
+	max int64
+
+	size      int64
+	truncated bool
+
+	mu sync.Mutex
+}
+
+func newSpoolFile(path string, max int64) (*spoolFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	return &spoolFile{f: f, max: max}, nil
+}
+
+func (sf *spoolFile) Write(data []byte) (int, error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	// pretend the discarded data was written, otherwise the process output copy fails
+	n := len(data)
+	if sf.max > 0 && sf.size+int64(len(data)) > sf.max {
+		data = data[:sf.max-sf.size]
+		sf.truncated = true
+	}
+
+	written, err := sf.f.Write(data)
+	sf.size += int64(written)
+	if err != nil {
+		return written, err
+	}
+	return n, nil
+}
+
+// ReadAt returns at most max bytes of output, starting at offset
+func (sf *spoolFile) ReadAt(offset int64, max int64) ([]byte, error) {
+	size, _ := sf.Size()
+	if offset >= size {
+		return nil, nil
+	}
+
+	n := size - offset
+	if n > max {
+		n = max
+	}
+	data := make([]byte, n)
+	if _, err := sf.f.ReadAt(data, offset); err != nil {
+		return nil, fmt.Errorf("failed to read spool file: %w", err)
+	}
+	return data, nil
+}
+
+// Size returns the number of bytes written so far and whether some were discarded
+func (sf *spoolFile) Size() (int64, bool) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	return sf.size, sf.truncated
+}
+
+func (sf *spoolFile) Remove() error {
+	if err := sf.f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(sf.f.Name())
+}
+
+type session struct {
+	id      string
+	cmd     *exec.Cmd
+	started time.Time
+
+	stdout *spoolFile
+	stderr *spoolFile
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// these are only valid after done is closed
+	exitCode int
+	err      string
+
+	mu       sync.Mutex
+	lastPoll time.Time
+}
+
+func (s *session) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *session) info() SessionInfo {
+	stdoutSize, stdoutTruncated := s.stdout.Size()
+	stderrSize, stderrTruncated := s.stderr.Size()
+
+	info := SessionInfo{
+		SessionID:  s.id,
+		Cmd:        s.cmd.String(),
+		Pid:        s.cmd.Process.Pid,
+		Started:    s.started,
+		StdoutSize: stdoutSize,
+		StderrSize: stderrSize,
+		Truncated:  stdoutTruncated || stderrTruncated,
+	}
+	if s.exited() {
+		info.ExitCode = s.exitCode
+		info.Error = s.err
+	} else {
+		info.Running = true
+	}
+	return info
+}
+
+// Daemon manages many processes, identified by session ids; the output of the
+// processes is spooled to disk so that it can be polled incrementally
+type Daemon struct {
+	spoolDir string
+
+	sessions map[string]*session
+	// idleSince is when the last session was removed
+	idleSince time.Time
+
+	mu sync.Mutex
+}
+
+func NewDaemon(spoolDir string) (*Daemon, error) {
+	if err := os.MkdirAll(spoolDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir %s: %w", spoolDir, err)
+	}
+
+	return &Daemon{
+		spoolDir:  spoolDir,
+		sessions:  make(map[string]*session),
+		idleSince: time.Now(),
+	}, nil
+}
+
+// Start starts a process in a new session and returns the session id
+func (d *Daemon) Start(bin string, args []string, limits SessionLimits) (string, error) {
+	id := uuid.New().String()
+
+	stdout, err := newSpoolFile(filepath.Join(d.spoolDir, id+".stdout"), limits.MaxOutput)
+	if err != nil {
+		return "", err
+	}
+	stderr, err := newSpoolFile(filepath.Join(d.spoolDir, id+".stderr"), limits.MaxOutput)
+	if err != nil {
+		stdout.Remove()
+		return "", err
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if limits.TimeQuota != 0 {
+		ctx, cancel = context.WithTimeout(ctx, limits.TimeQuota)
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	log.Printf("starting command in session %s: %s", id, cmd.String())
+	if err := cmd.Start(); err != nil {
+		cancel()
+		stdout.Remove()
+		stderr.Remove()
+		return "", fmt.Errorf("failed to start process: %w", err)
+	}
+
+	s := &session{
+		id:       id,
+		cmd:      cmd,
+		started:  time.Now(),
+		stdout:   stdout,
+		stderr:   stderr,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		lastPoll: time.Now(),
+	}
+
+	go func() {
+		defer cancel()
+
+		err := cmd.Wait()
+		if err == nil {
+			log.Printf("session %s: process finished", id)
+		} else {
+			log.Printf("session %s: process exited with err: %v", id, err)
+
+			var ee *exec.ExitError
+			if errors.As(err, &ee) {
+				s.exitCode = ee.ExitCode()
+			} else {
+				s.err = err.Error()
+			}
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.err = "process exceeded time quota"
+		}
+		close(s.done)
+	}()
+
+	d.mu.Lock()
+	d.sessions[id] = s
+	d.mu.Unlock()
+
+	return id, nil
+}
+
+func (d *Daemon) get(id string) (*session, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSession, id)
+	}
+	return s, nil
+}
+
+// Poll returns the output of the session process starting at the given offsets;
+// the exit code is set once the process exited and all its output was returned
+func (d *Daemon) Poll(id string, stdoutOffset, stderrOffset int64) (*PollMessage, error) {
+	s, err := d.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.lastPoll = time.Now()
+	s.mu.Unlock()
+
+	// check before reading, so that all the output is read if the process exited
+	exited := s.exited()
+
+	stdout, err := s.stdout.ReadAt(stdoutOffset, MaxPollChunk)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := s.stderr.ReadAt(stderrOffset, MaxPollChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	stdoutSize, stdoutTruncated := s.stdout.Size()
+	stderrSize, stderrTruncated := s.stderr.Size()
+
+	msg := &PollMessage{
+		Stdout:    string(stdout),
+		Stderr:    string(stderr),
+		Truncated: stdoutTruncated || stderrTruncated,
+	}
+
+	drained := stdoutOffset+int64(len(stdout)) >= stdoutSize && stderrOffset+int64(len(stderr)) >= stderrSize
+	if exited && drained {
+		if s.err != "" {
+			msg.Error = s.err
+		} else {
+			code := s.exitCode
+			msg.ExitCode = &code
+		}
+	}
+	return msg, nil
+}
+
+// Kill kills the process of the session, if it's still running
+func (d *Daemon) Kill(id string) error {
+	s, err := d.get(id)
+	if err != nil {
+		return err
+	}
+
+	if s.exited() {
+		return nil
+	}
+	if err := s.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill process: %w", err)
+	}
+	return nil
+}
+
+// Reap removes the session and its spooled output, killing the process if needed
+func (d *Daemon) Reap(id string) error {
+	if err := d.Kill(id); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	s, ok := d.sessions[id]
+	delete(d.sessions, id)
+	if len(d.sessions) == 0 {
+		d.idleSince = time.Now()
+	}
+	d.mu.Unlock()
+
+	if !ok {
+		// reaped concurrently
+		return nil
+	}
+
+	<-s.done
+	if err := s.stdout.Remove(); err != nil {
+		return fmt.Errorf("failed to remove spooled stdout: %w", err)
+	}
+	if err := s.stderr.Remove(); err != nil {
+		return fmt.Errorf("failed to remove spooled stderr: %w", err)
+	}
+	return nil
+}
+
+// List returns the sessions managed by the daemon
+func (d *Daemon) List() []SessionInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sessions := make([]SessionInfo, 0, len(d.sessions))
+	for _, s := range d.sessions {
+		sessions = append(sessions, s.info())
+	}
+	return sessions
+}
+
+// Expire reaps the sessions whose process exited and that weren't polled for
+// the given time, in case their controller went away
+func (d *Daemon) Expire(ttl time.Duration) {
+	var expired []string
+
+	d.mu.Lock()
+	for id, s := range d.sessions {
+		s.mu.Lock()
+		lastPoll := s.lastPoll
+		s.mu.Unlock()
+
+		if s.exited() && time.Since(lastPoll) > ttl {
+			expired = append(expired, id)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, id := range expired {
+		log.Printf("expiring session %s", id)
+		if err := d.Reap(id); err != nil {
+			log.Printf("failed to reap session %s: %v", id, err)
+		}
+	}
+}
+
+// Idle returns for how long the daemon had no sessions
+func (d *Daemon) Idle() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.sessions) > 0 {
+		return 0
+	}
+	return time.Since(d.idleSince)
+}
+
+type StartArgs struct {
+	Bin    string
+	Args   []string
+	Limits SessionLimits
+}
+
+type PollArgs struct {
+	SessionID    string
+	StdoutOffset int64
+	StderrOffset int64
+}
+
+type daemonRPC struct {
+	d *Daemon
+}
+
+func (rpc *daemonRPC) Start(args StartArgs, reply *StartMessage) error {
+	log.Printf("got a call for: start")
+
+	sid, err := rpc.d.Start(args.Bin, args.Args, args.Limits)
+	if err != nil {
+		return err
+	}
+	reply.SessionID = sid
+	return nil
+}
+
+func (rpc *daemonRPC) Poll(args PollArgs, reply *PollReply) error {
+	log.Printf("got a call for: poll %s", args.SessionID)
+
+	msg, err := rpc.d.Poll(args.SessionID, args.StdoutOffset, args.StderrOffset)
+	if err != nil {
+		return err
+	}
+
+	// gob doesn't transmit zero values, so a pointer to a 0 exit code is lost
+	reply.Stdout = []byte(msg.Stdout)
+	reply.Stderr = []byte(msg.Stderr)
+	reply.Error = msg.Error
+	reply.Truncated = msg.Truncated
+	if msg.ExitCode == nil {
+		reply.Alive = true
+	} else {
+		reply.ExitCode = *msg.ExitCode
+	}
+	return nil
+}
+
+func (rpc *daemonRPC) Kill(sid string, _ *interface{}) error {
+	log.Printf("got a call for: kill %s", sid)
+	return rpc.d.Kill(sid)
+}
+
+func (rpc *daemonRPC) Reap(sid string, _ *interface{}) error {
+	log.Printf("got a call for: reap %s", sid)
+	return rpc.d.Reap(sid)
+}
+
+func (rpc *daemonRPC) List(_ int, reply *ListMessage) error {
+	log.Printf("got a call for: list")
+
+	reply.Sessions = rpc.d.List()
+	return nil
+}
+
+type DaemonServer struct {
+	addr string
+	rpc  *daemonRPC
+
+	http *http.Server
+	mu   sync.Mutex
+}
+
+func NewDaemonServer(d *Daemon, addr string) *DaemonServer {
+	return &DaemonServer{
+		addr: addr,
+		rpc:  &daemonRPC{d},
+	}
+}
+
+func (m *DaemonServer) Serve() error {
+	log.Printf("starting daemon...")
+
+	if err := os.RemoveAll(m.addr); err != nil {
+		return fmt.Errorf("failed to clear lingering socket %s: %w", m.addr, err)
+	}
+
+	listener, err := net.Listen("unix", m.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on socket %s: %w", m.addr, err)
+	}
+	defer listener.Close()
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("daemon", m.rpc); err != nil {
+		return fmt.Errorf("failed to register rpc api: %v", err)
+	}
+
+	log.Printf("starting RPC server at: %s", m.addr)
+	m.mu.Lock()
+	m.http = &http.Server{
+		Addr:    m.addr,
+		Handler: rpcServer,
+	}
+	m.mu.Unlock()
+
+	if err := m.http.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (m *DaemonServer) Shutdown() error {
+	log.Printf("shutting down daemon...")
+
+	if err := os.RemoveAll(m.addr); err != nil {
+		return fmt.Errorf("failed to remove unix socket %s: %w", m.addr, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.http != nil {
+		// dont care about cancellation context
+		return m.http.Shutdown(context.Background())
+	}
+	return nil
+}
+
+type DaemonClient struct {
+	addr string
+}
+
+func NewDaemonClient(addr string) *DaemonClient {
+	return &DaemonClient{addr}
+}
+
+func (c *DaemonClient) call(method string, args interface{}, reply interface{}) error {
+	client, err := rpc.DialHTTP("unix", c.addr)
+	if err != nil {
+		return &ErrCantConnect{fmt.Errorf("failed to connect to %s: %w", c.addr, err)}
+	}
+	defer client.Close()
+
+	if err := client.Call(method, args, reply); err != nil {
+		return fmt.Errorf("failed to call rpc method: %w", err)
+	}
+	return nil
+}
+
+func (c *DaemonClient) Start(bin string, args []string, limits SessionLimits) (string, error) {
+	var reply StartMessage
+	if err := c.call("daemon.Start", StartArgs{bin, args, limits}, &reply); err != nil {
+		return "", err
+	}
+	return reply.SessionID, nil
+}
+
+func (c *DaemonClient) Poll(sid string, stdoutOffset, stderrOffset int64) (*PollMessage, error) {
+	var reply PollReply
+	if err := c.call("daemon.Poll", PollArgs{sid, stdoutOffset, stderrOffset}, &reply); err != nil {
+		return nil, err
+	}
+
+	var code *int
+	if !reply.Alive {
+		code = &reply.ExitCode
+	}
+
+	return &PollMessage{
+		Stdout:    string(reply.Stdout),
+		Stderr:    string(reply.Stderr),
+		ExitCode:  code,
+		Error:     reply.Error,
+		Truncated: reply.Truncated,
+	}, nil
+}
+
+func (c *DaemonClient) Kill(sid string) error {
+	var reply interface{}
+	return c.call("daemon.Kill", sid, &reply)
+}
+
+func (c *DaemonClient) Reap(sid string) error {
+	var reply interface{}
+	return c.call("daemon.Reap", sid, &reply)
+}
+
+func (c *DaemonClient) List() ([]SessionInfo, error) {
+	var reply ListMessage
+	if err := c.call("daemon.List", 0, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Sessions, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package remote
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pollUntilExit polls the session from the given offsets until the process exited
+func pollUntilExit(t *testing.T, d *Daemon, sid string, stdoutOffset int64) (string, *PollMessage) {
+	var stdout string
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		msg, err := d.Poll(sid, stdoutOffset+int64(len(stdout)), 0)
+		require.NoError(t, err)
+
+		stdout += msg.Stdout
+		if msg.ExitCode != nil || msg.Error != "" {
+			return stdout, msg
+		}
+	}
+	require.FailNow(t, "process did not exit")
+	return "", nil
+}
+
+func TestDaemonSessions(t *testing.T) {
+	d, err := NewDaemon(t.TempDir())
+	require.NoError(t, err)
+
+	sid1, err := d.Start("sh", []string{"-c", "echo first; echo second"}, SessionLimits{})
+	require.NoError(t, err)
+	sid2, err := d.Start("sh", []string{"-c", "exit 3"}, SessionLimits{})
+	require.NoError(t, err)
+	require.NotEqual(t, sid1, sid2)
+	require.Equal(t, 2, len(d.List()))
+
+	stdout, msg := pollUntilExit(t, d, sid1, 0)
+	require.Equal(t, "first\nsecond\n", stdout)
+	require.Equal(t, 0, *msg.ExitCode)
+
+	// polling is not destructive, reading again from an offset returns the rest of the output
+	stdout, _ = pollUntilExit(t, d, sid1, int64(len("first\n")))
+	require.Equal(t, "second\n", stdout)
+
+	_, msg = pollUntilExit(t, d, sid2, 0)
+	require.Equal(t, 3, *msg.ExitCode)
+
+	require.NoError(t, d.Reap(sid1))
+	require.NoError(t, d.Reap(sid2))
+	require.Empty(t, d.List())
+
+	_, err = d.Poll(sid1, 0, 0)
+	require.True(t, errors.Is(err, ErrUnknownSession))
+}
+
+func TestDaemonLimits(t *testing.T) {
+	spoolDir := t.TempDir()
+	d, err := NewDaemon(spoolDir)
+	require.NoError(t, err)
+
+	sid, err := d.Start("sh", []string{"-c", "echo 0123456789"}, SessionLimits{MaxOutput: 4})
+	require.NoError(t, err)
+
+	stdout, msg := pollUntilExit(t, d, sid, 0)
+	require.Equal(t, "0123", stdout)
+	require.True(t, msg.Truncated)
+
+	sid, err = d.Start("sleep", []string{"10"}, SessionLimits{TimeQuota: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, msg = pollUntilExit(t, d, sid, 0)
+	require.Nil(t, msg.ExitCode)
+	require.Contains(t, msg.Error, "time quota")
+
+	require.NoError(t, d.Reap(sid))
+	spooled, err := filepath.Glob(filepath.Join(spoolDir, sid+".*"))
+	require.NoError(t, err)
+	require.Empty(t, spooled)
+}
+
+func TestDaemonServer(t *testing.T) {
+	d, err := NewDaemon(t.TempDir())
+	require.NoError(t, err)
+
+	addr := filepath.Join(t.TempDir(), "agent.sock")
+	server := NewDaemonServer(d, addr)
+	serverDone := make(chan error, 1)
+	go func() {
+		serverDone <- server.Serve()
+	}()
+
+	c := NewDaemonClient(addr)
+	var sessions []SessionInfo
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if sessions, err = c.List(); err == nil {
+			break
+		}
+	}
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	sid, err := c.Start("sh", []string{"-c", "echo hello"}, SessionLimits{})
+	require.NoError(t, err)
+
+	var msg *PollMessage
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		msg, err = c.Poll(sid, 0, 0)
+		require.NoError(t, err)
+		if msg.ExitCode != nil {
+			break
+		}
+	}
+	require.Equal(t, "hello\n", msg.Stdout)
+	require.Equal(t, 0, *msg.ExitCode)
+
+	sessions, err = c.List()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(sessions))
+	require.Equal(t, sid, sessions[0].SessionID)
+	require.False(t, sessions[0].Running)
+	require.Equal(t, int64(len("hello\n")), sessions[0].StdoutSize)
+
+	require.NoError(t, c.Reap(sid))
+	require.Error(t, c.Kill(sid))
+
+	require.NoError(t, server.Shutdown())
+	require.NoError(t, <-serverDone)
+}
@@ -97,6 +97,10 @@ type PollReply struct {
 
 	ExitCode int
 	Alive    bool
+
+	// Error and Truncated are only set by the daemon
+	Error     string
+	Truncated bool
 }
 
 // monitorRPC is a tightly coupled view into Monitor that can be used
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 )
 
 type StartMessage struct {
@@ -25,6 +26,30 @@ type PollMessage struct {
 
 	// Error is any error encountered while trying to reach the agent
 	Error string `json:"error,omitempty"`
+
+	// Truncated is set when the process output exceeded the output cap of the session
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// SessionInfo describes a session managed by the agent daemon
+type SessionInfo struct {
+	SessionID string    `json:"sid"`
+	Cmd       string    `json:"cmd"`
+	Pid       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+
+	// ExitCode and Error are only valid when the process is not running anymore
+	Running  bool   `json:"running"`
+	ExitCode int    `json:"exitcode"`
+	Error    string `json:"error,omitempty"`
+
+	StdoutSize int64 `json:"stdout_size"`
+	StderrSize int64 `json:"stderr_size"`
+	Truncated  bool  `json:"truncated,omitempty"`
+}
+
+type ListMessage struct {
+	Sessions []SessionInfo `json:"sessions"`
 }
 
 // SendResponse conveys to the caller a given response object o
@@ -0,0 +1,104 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/cerrors"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// groupStepState is the resume state of a group step
+type groupStepState struct {
+	// Targets are the targets that reached the step
+	Targets []*target.Target `json:"T,omitempty"`
+	// StepState is the resume state returned by RunGroup, if it was called
+	StepState json.RawMessage `json:"S,omitempty"`
+}
+
+// runGroupStep runs a group step: it collects the targets until the input is
+// closed, which happens once every target still alive has reached the step,
+// then runs the step once for all of them and reports their results.
+func runGroupStep(
+	ctx xcontext.Context,
+	step test.GroupTestStep,
+	ch test.TestStepChannels,
+	ev testevent.Emitter,
+	stepsVariables test.StepsVariables,
+	bundle test.TestStepBundle,
+	resumeState json.RawMessage,
+) (json.RawMessage, error) {
+	var state groupStepState
+	if len(resumeState) > 0 {
+		if err := json.Unmarshal(resumeState, &state); err != nil {
+			return nil, fmt.Errorf("invalid group step resume state: %w", err)
+		}
+	}
+
+	// targets that were in the step when it was paused are not injected again
+	targets := state.Targets
+collect:
+	for {
+		select {
+		// no need to check for pause here, pausing closes the channel
+		case tgt, ok := <-ch.In:
+			if !ok {
+				break collect
+			}
+			targets = append(targets, tgt)
+		case <-ctx.Done():
+			return nil, xcontext.ErrCanceled
+		}
+	}
+	if ctx.IsSignaledWith(xcontext.ErrPaused) {
+		ctx.Debugf("group step paused with %d targets", len(targets))
+		return marshalGroupStepState(targets, state.StepState)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	ctx.Debugf("running group step on %d targets", len(targets))
+	results, stepState, err := step.RunGroup(ctx, targets, ev, stepsVariables, bundle.Parameters, state.StepState)
+	if err == xcontext.ErrPaused {
+		return marshalGroupStepState(targets, stepState)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(targets))
+	for _, tgt := range targets {
+		known[tgt.ID] = true
+	}
+	for tgtID := range results {
+		if !known[tgtID] {
+			return nil, &cerrors.ErrTestStepReturnedUnexpectedResult{StepName: bundle.TestStepLabel, Target: tgtID}
+		}
+	}
+
+	for _, tgt := range targets {
+		select {
+		case ch.Out <- test.TestStepResult{Target: tgt, Err: results[tgt.ID]}:
+		case <-ctx.Done():
+			return nil, xcontext.ErrCanceled
+		}
+	}
+	return nil, nil
+}
+
+func marshalGroupStepState(targets []*target.Target, stepState json.RawMessage) (json.RawMessage, error) {
+	data, err := json.Marshal(groupStepState{Targets: targets, StepState: stepState})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal group step resume state: %w", err)
+	}
+	return data, xcontext.ErrPaused
+}
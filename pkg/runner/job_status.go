@@ -19,6 +19,10 @@ import (
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
 
+// testEventsPageSize is the maximum number of test events fetched from the
+// storage at once while building statuses.
+const testEventsPageSize = 1000
+
 // targetRoutingEvents gather all event names which track the flow of targets
 // between TestSteps
 var targetRoutingEvents = map[event.Name]struct{}{
@@ -29,101 +33,117 @@ var targetRoutingEvents = map[event.Name]struct{}{
 	target.EventTargetAcquireErr: {},
 }
 
-// buildTargetStatuses builds a list of TargetStepStatus, which represent the status of Targets within a TestStep
-func (jr *JobRunner) buildTargetStatuses(coordinates job.TestStepCoordinates, targetEvents []testevent.Event) ([]job.TargetStatus, error) {
-	var targetStatuses []job.TargetStatus
-	for _, testEvent := range targetEvents {
-
-		// Update the TargetStatus object associated to the Target. If there is no TargetStatus associated yet, append it
-		var targetStatus *job.TargetStatus
-		for index, candidateStatus := range targetStatuses {
-			if candidateStatus.Target.ID == testEvent.Data.Target.ID {
-				targetStatus = &targetStatuses[index]
-				break
-			}
+// forEachTestEvent calls handle on each test event matching the given fields,
+// in the order they were emitted. Events are fetched in pages of
+// testEventsPageSize events, and each page is dropped once handled, so that the
+// events of a whole job are never loaded at once.
+func (jr *JobRunner) forEachTestEvent(ctx xcontext.Context, handle func(testevent.Event), fields ...testevent.QueryField) error {
+	var sequenceIDAfter uint64
+	for {
+		pageFields := append(fields[:len(fields):len(fields)], testevent.QueryLimit(testEventsPageSize))
+		if sequenceIDAfter != 0 {
+			pageFields = append(pageFields, testevent.QuerySequenceIDAfter(sequenceIDAfter))
 		}
-
-		if targetStatus == nil {
-			// There is no TargetStatus associated with this Target, create one
-			targetStatuses = append(targetStatuses, job.TargetStatus{TestStepCoordinates: coordinates, Target: testEvent.Data.Target})
-			targetStatus = &targetStatuses[len(targetStatuses)-1]
+		page, err := jr.testEvManager.Fetch(ctx, pageFields...)
+		if err != nil {
+			return err
 		}
-		// append non-routing events
-		if _, isRoutingEvent := targetRoutingEvents[testEvent.Data.EventName]; !isRoutingEvent {
-			targetStatus.Events = append(targetStatus.Events, testEvent)
+		for _, ev := range page {
+			handle(ev)
 		}
-
-		evName := testEvent.Data.EventName
-		if evName == target.EventTargetIn {
-			targetStatus.InTime = testEvent.EmitTime
-		} else if evName == target.EventTargetOut {
-			targetStatus.OutTime = testEvent.EmitTime
-		} else if evName == target.EventTargetErr {
-			targetStatus.OutTime = testEvent.EmitTime
-			errorPayload, err := target.UnmarshalErrPayload(*testEvent.Data.Payload)
-			if err != nil {
-				targetStatus.Error = fmt.Sprintf("could not unmarshal payload error: %v", err)
-			} else {
-				targetStatus.Error = errorPayload.Error
-			}
+		if len(page) < testEventsPageSize {
+			return nil
 		}
+		sequenceIDAfter = page[len(page)-1].SequenceID
 	}
-
-	return targetStatuses, nil
 }
 
-// buildTestStepStatus builds the status object of a test step belonging to a test
-func (jr *JobRunner) buildTestStepStatus(ctx xcontext.Context, coordinates job.TestStepCoordinates) (*job.TestStepStatus, error) {
+// stepStatusBuilder builds the status of a test step one event at a time. Only
+// the events of the latest attempt are kept, otherwise all the reporters would
+// have to do the filtering on their side. Attempts run one after the other, so
+// an event of a new attempt discards what was built from the previous one.
+type stepStatusBuilder struct {
+	ctx     xcontext.Context
+	status  job.TestStepStatus
+	attempt uint32
+	// targets indexes status.TargetStatuses by target ID
+	targets map[string]int
+}
 
-	testStepStatus := job.TestStepStatus{TestStepCoordinates: coordinates}
+func newStepStatusBuilder(ctx xcontext.Context, coordinates job.TestStepCoordinates) *stepStatusBuilder {
+	return &stepStatusBuilder{
+		ctx:     ctx,
+		status:  job.TestStepStatus{TestStepCoordinates: coordinates},
+		targets: make(map[string]int),
+	}
+}
 
-	// Fetch all Events associated to this TestStep
-	testEvents, err := jr.testEvManager.Fetch(ctx,
-		testevent.QueryJobID(coordinates.JobID),
-		testevent.QueryRunID(coordinates.RunID),
-		testevent.QueryTestName(coordinates.TestName),
-		testevent.QueryTestStepLabel(coordinates.TestStepLabel),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("could not fetch events associated to test step %s: %v", coordinates.TestStepLabel, err)
+func (b *stepStatusBuilder) add(ev testevent.Event) {
+	if ev.Header.TestAttempt < b.attempt {
+		return
+	}
+	if ev.Header.TestAttempt > b.attempt {
+		b.attempt = ev.Header.TestAttempt
+		b.status.Events = nil
+		b.status.TargetStatuses = nil
+		b.targets = make(map[string]int)
 	}
 
-	// Keep only events of the latest attempt
-	// Otherwise we will have to make all reporters do the filtering on their side
-	var lastAttempt uint32
-	for _, ev := range testEvents {
-		if ev.Header.TestAttempt > lastAttempt {
-			lastAttempt = ev.Header.TestAttempt
+	if ev.Data.Target == nil {
+		// we don't want target routing events in step events, but we want
+		// them in target events below
+		if _, skip := targetRoutingEvents[ev.Data.EventName]; skip {
+			b.ctx.Warnf("Found routing event '%s' with no target associated, this could indicate a bug", ev.Data.EventName)
+			return
 		}
+		// this goes into TestStepStatus.Events
+		b.status.Events = append(b.status.Events, ev)
+		return
 	}
 
-	var stepEvents, targetEvents []testevent.Event
-	for _, ev := range testEvents {
-		if ev.Header.TestAttempt != lastAttempt {
-			continue
-		}
-		if ev.Data.Target == nil {
-			// we don't want target routing events in step events, but we want
-			// them in target events below
-			if _, skip := targetRoutingEvents[ev.Data.EventName]; skip {
-				ctx.Warnf("Found routing event '%s' with no target associated, this could indicate a bug", ev.Data.EventName)
-				continue
-			}
-			// this goes into TestStepStatus.Events
-			stepEvents = append(stepEvents, ev)
+	// Update the TargetStatus object associated to the Target. If there is no TargetStatus associated yet, append it
+	idx, ok := b.targets[ev.Data.Target.ID]
+	if !ok {
+		idx = len(b.status.TargetStatuses)
+		b.targets[ev.Data.Target.ID] = idx
+		b.status.TargetStatuses = append(b.status.TargetStatuses, job.TargetStatus{TestStepCoordinates: b.status.TestStepCoordinates, Target: ev.Data.Target})
+	}
+	targetStatus := &b.status.TargetStatuses[idx]
+
+	// append non-routing events
+	if _, isRoutingEvent := targetRoutingEvents[ev.Data.EventName]; !isRoutingEvent {
+		targetStatus.Events = append(targetStatus.Events, ev)
+	}
+
+	switch ev.Data.EventName {
+	case target.EventTargetIn:
+		targetStatus.InTime = ev.EmitTime
+	case target.EventTargetOut:
+		targetStatus.OutTime = ev.EmitTime
+	case target.EventTargetErr:
+		targetStatus.OutTime = ev.EmitTime
+		errorPayload, err := target.UnmarshalErrPayload(*ev.Data.Payload)
+		if err != nil {
+			targetStatus.Error = fmt.Sprintf("could not unmarshal payload error: %v", err)
 		} else {
-			// this goes into TargetStatus.Events
-			targetEvents = append(targetEvents, ev)
+			targetStatus.Error = errorPayload.Error
 		}
 	}
+}
 
-	testStepStatus.Events = stepEvents
-	targetStatuses, err := jr.buildTargetStatuses(coordinates, targetEvents)
+// buildTestStepStatus builds the status object of a test step belonging to a test
+func (jr *JobRunner) buildTestStepStatus(ctx xcontext.Context, coordinates job.TestStepCoordinates) (*job.TestStepStatus, error) {
+	builder := newStepStatusBuilder(ctx, coordinates)
+	err := jr.forEachTestEvent(ctx, builder.add,
+		testevent.QueryJobID(coordinates.JobID),
+		testevent.QueryRunID(coordinates.RunID),
+		testevent.QueryTestName(coordinates.TestName),
+		testevent.QueryTestStepLabel(coordinates.TestStepLabel),
+	)
 	if err != nil {
-		return nil, fmt.Errorf("could not build target status for test step %s: %v", coordinates.TestStepLabel, err)
+		return nil, fmt.Errorf("could not fetch events associated to test step %s: %v", coordinates.TestStepLabel, err)
 	}
-	testStepStatus.TargetStatuses = targetStatuses
-	return &testStepStatus, nil
+	return &builder.status, nil
 }
 
 // buildTestStatus builds the status of a test belonging to a specific to a test
@@ -163,26 +183,6 @@ func (jr *JobRunner) buildTestStatus(ctx xcontext.Context, coordinates job.TestC
 	// Calculate the overall status of the Targets which corresponds to the last TargetStatus
 	// object recorded for each Target.
 
-	// Fetch all events signaling that a Target has been acquired. This is the source of truth
-	// indicating which Targets belong to a Test.
-	targetAcquiredEvents, err := jr.testEvManager.Fetch(ctx,
-		testevent.QueryJobID(coordinates.JobID),
-		testevent.QueryRunID(coordinates.RunID),
-		testevent.QueryTestName(coordinates.TestName),
-		testevent.QueryEventNames([]event.Name{target.EventTargetAcquired, target.EventTargetAcquireErr}),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("could not fetch events associated to target acquisition")
-	}
-
-	var lastAttempt uint32
-	for _, ev := range targetAcquiredEvents {
-		if ev.Header.TestAttempt > lastAttempt {
-			lastAttempt = ev.Header.TestAttempt
-		}
-	}
-
-	var targetStatuses []job.TargetStatus
 	// Keep track of the last TargetStatus seen for each Target
 	targetMap := make(map[string]job.TargetStatus)
 	for _, testStepStatus := range testStatus.TestStepStatuses {
@@ -191,9 +191,19 @@ func (jr *JobRunner) buildTestStatus(ctx xcontext.Context, coordinates job.TestC
 		}
 	}
 
-	for _, targetEvent := range targetAcquiredEvents {
-		if targetEvent.Header.TestAttempt != lastAttempt {
-			continue
+	// Go through all events signaling that a Target has been acquired. This is the source of truth
+	// indicating which Targets belong to a Test. Only the ones of the latest attempt are kept.
+	var (
+		targetStatuses []job.TargetStatus
+		lastAttempt    uint32
+	)
+	err := jr.forEachTestEvent(ctx, func(targetEvent testevent.Event) {
+		if targetEvent.Header.TestAttempt < lastAttempt {
+			return
+		}
+		if targetEvent.Header.TestAttempt > lastAttempt {
+			lastAttempt = targetEvent.Header.TestAttempt
+			targetStatuses = nil
 		}
 
 		if targetEvent.Data.EventName == target.EventTargetAcquireErr {
@@ -217,7 +227,7 @@ func (jr *JobRunner) buildTestStatus(ctx xcontext.Context, coordinates job.TestC
 				Error:   errMessage,
 				Events:  []testevent.Event{targetEvent},
 			})
-			continue
+			return
 		}
 
 		t := *targetEvent.Data.Target
@@ -227,6 +237,14 @@ func (jr *JobRunner) buildTestStatus(ctx xcontext.Context, coordinates job.TestC
 			targetMap[t.ID] = job.TargetStatus{}
 		}
 		targetStatuses = append(targetStatuses, targetMap[t.ID])
+	},
+		testevent.QueryJobID(coordinates.JobID),
+		testevent.QueryRunID(coordinates.RunID),
+		testevent.QueryTestName(coordinates.TestName),
+		testevent.QueryEventNames([]event.Name{target.EventTargetAcquired, target.EventTargetAcquireErr}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch events associated to target acquisition")
 	}
 
 	testStatus.TargetStatuses = targetStatuses
@@ -249,37 +267,51 @@ func (jr *JobRunner) BuildRunStatus(ctx xcontext.Context, coordinates job.RunCoo
 	return &runStatus, nil
 }
 
-// BuildRunStatuses builds the status of all runs belonging to the job
-func (jr *JobRunner) BuildRunStatuses(ctx xcontext.Context, currentJob *job.Job) ([]job.RunStatus, error) {
-
-	// Calculate the status only for the runs which effectively were executed
-	runStartEvents, err := jr.frameworkEventManager.Fetch(ctx, frameworkevent.QueryEventName(EventRunStarted), frameworkevent.QueryJobID(currentJob.ID))
+// LastRunID returns the ID of the latest run of the job which was effectively
+// started, or 0 if no run was started yet.
+func (jr *JobRunner) LastRunID(ctx xcontext.Context, jobID types.JobID) (types.RunID, error) {
+	// Runs are started in order, so the latest RunStarted event carries the
+	// highest run ID.
+	runStartEvents, err := jr.frameworkEventManager.Fetch(ctx,
+		frameworkevent.QueryEventName(EventRunStarted),
+		frameworkevent.QueryJobID(jobID),
+		frameworkevent.QueryOrder(event.OrderDescending),
+		frameworkevent.QueryLimit(1),
+	)
 	if err != nil {
-		return nil, fmt.Errorf("could not determine how many runs were executed: %v", err)
+		return 0, fmt.Errorf("could not determine how many runs were executed: %v", err)
 	}
-	if len(runStartEvents) == 0 {
-		return nil, nil
+	if len(runStartEvents) == 0 || runStartEvents[0].Payload == nil {
+		return 0, nil
 	}
 
-	numRuns := types.RunID(0)
-	for _, runStartEvent := range runStartEvents {
-		payload, err := runStartEvent.Payload.MarshalJSON()
-		if err != nil {
-			return nil, fmt.Errorf("could not extract JSON payload from RunStart event: %v", err)
-		}
+	payloadUnmarshaled := RunStartedPayload{}
+	if err := json.Unmarshal(*runStartEvents[0].Payload, &payloadUnmarshaled); err != nil {
+		return 0, fmt.Errorf("could not unmarshal RunStarted event payload")
+	}
+	return payloadUnmarshaled.RunID, nil
+}
 
-		payloadUnmarshaled := RunStartedPayload{}
-		if err := json.Unmarshal(payload, &payloadUnmarshaled); err != nil {
-			return nil, fmt.Errorf("could not unmarshal RunStarted event payload")
-		}
+// BuildRunStatuses builds the status of all runs belonging to the job
+func (jr *JobRunner) BuildRunStatuses(ctx xcontext.Context, currentJob *job.Job) ([]job.RunStatus, error) {
+	return jr.BuildRunStatusesPage(ctx, currentJob, 0, 0)
+}
 
-		if payloadUnmarshaled.RunID > numRuns {
-			numRuns = payloadUnmarshaled.RunID
-		}
+// BuildRunStatusesPage builds the status of at most maxRuns runs of the job,
+// starting from the run following runsAfter. A maxRuns of 0 means no limit.
+func (jr *JobRunner) BuildRunStatusesPage(ctx xcontext.Context, currentJob *job.Job, runsAfter types.RunID, maxRuns uint) ([]job.RunStatus, error) {
+
+	// Calculate the status only for the runs which effectively were executed
+	numRuns, err := jr.LastRunID(ctx, currentJob.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	var runStatuses []job.RunStatus
-	for runID := types.RunID(1); runID <= numRuns; runID++ {
+	for runID := runsAfter + 1; runID <= numRuns; runID++ {
+		if maxRuns != 0 && uint(len(runStatuses)) >= maxRuns {
+			break
+		}
 		runCoordinates := job.RunCoordinates{JobID: currentJob.ID, RunID: runID}
 		runStatus, err := jr.BuildRunStatus(ctx, runCoordinates, currentJob)
 		if err != nil {
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepStatusBuilder(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := &target.Target{ID: "T1"}
+	t2 := &target.Target{ID: "T2"}
+	errPayload, err := target.MarshallErrPayload("boom")
+	require.NoError(t, err)
+
+	var sequenceID uint64
+	newEvent := func(attempt uint32, tgt *target.Target, name event.Name, payload json.RawMessage) testevent.Event {
+		sequenceID++
+		ev := testevent.Event{
+			EmitTime:   start.Add(time.Duration(sequenceID) * time.Second),
+			SequenceID: sequenceID,
+			Header:     &testevent.Header{JobID: 1, RunID: 1, TestName: "test", TestStepLabel: "step", TestAttempt: attempt},
+			Data:       &testevent.Data{Target: tgt, EventName: name},
+		}
+		if payload != nil {
+			ev.Data.Payload = &payload
+		}
+		return ev
+	}
+
+	coordinates := job.TestStepCoordinates{TestStepLabel: "step"}
+	b := newStepStatusBuilder(xcontext.Background(), coordinates)
+	// the first attempt is discarded once the second one starts
+	b.add(newEvent(0, t1, target.EventTargetIn, nil))
+	b.add(newEvent(0, t1, target.EventTargetErr, errPayload))
+	b.add(newEvent(0, nil, "StepEvent", nil))
+	in1 := newEvent(1, t1, target.EventTargetIn, nil)
+	b.add(in1)
+	stepEvent := newEvent(1, nil, "StepEvent", nil)
+	b.add(stepEvent)
+	in2 := newEvent(1, t2, target.EventTargetIn, nil)
+	b.add(in2)
+	targetEvent := newEvent(1, t1, "TargetEvent", nil)
+	b.add(targetEvent)
+	out1 := newEvent(1, t1, target.EventTargetOut, nil)
+	b.add(out1)
+	err2 := newEvent(1, t2, target.EventTargetErr, errPayload)
+	b.add(err2)
+	// routing events must have a target
+	b.add(newEvent(1, nil, target.EventTargetOut, nil))
+
+	require.Equal(t, []testevent.Event{stepEvent}, b.status.Events)
+	require.Equal(t, []job.TargetStatus{
+		{
+			TestStepCoordinates: coordinates,
+			Target:              t1,
+			InTime:              in1.EmitTime,
+			OutTime:             out1.EmitTime,
+			Events:              []testevent.Event{targetEvent},
+		},
+		{
+			TestStepCoordinates: coordinates,
+			Target:              t2,
+			InTime:              in2.EmitTime,
+			OutTime:             err2.EmitTime,
+			Error:               "boom",
+		},
+	}, b.status.TargetStatuses)
+}
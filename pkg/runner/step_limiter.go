@@ -0,0 +1,163 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// stepSemaphores are the named semaphores of the steps, shared by all the
+// jobs running on this server
+var stepSemaphores = newSemaphoreRegistry()
+
+// stepLimiter enforces the limits of a step on the targets running it
+type stepLimiter struct {
+	// slots has a buffer of MaxConcurrency, nil if there is no limit
+	slots      chan struct{}
+	interval   time.Duration
+	semaphores []test.StepSemaphore
+	registry   *semaphoreRegistry
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+func newStepLimiter(limits test.StepLimits, registry *semaphoreRegistry) *stepLimiter {
+	l := &stepLimiter{
+		interval: limits.MinStartInterval,
+		registry: registry,
+	}
+	if limits.MaxConcurrency > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrency)
+	}
+	// always acquire the semaphores in the same order, so that steps using
+	// the same ones cannot deadlock
+	l.semaphores = append(l.semaphores, limits.Semaphores...)
+	sort.Slice(l.semaphores, func(i, j int) bool {
+		return l.semaphores[i].Name < l.semaphores[j].Name
+	})
+	return l
+}
+
+// acquire waits until the limits allow a target to run the step. It returns
+// the function to call when the target is done with the step.
+func (l *stepLimiter) acquire(ctx xcontext.Context) (func(), error) {
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			releases = append(releases, func() { <-l.slots })
+		case <-ctx.Until(xcontext.ErrPaused):
+			return nil, xcontext.ErrPaused
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for _, s := range l.semaphores {
+		r, err := l.registry.acquire(ctx, s)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+
+	if l.interval > 0 {
+		l.mu.Lock()
+		start := time.Now()
+		if start.Before(l.nextStart) {
+			start = l.nextStart
+		}
+		l.nextStart = start.Add(l.interval)
+		l.mu.Unlock()
+
+		if wait := time.Until(start); wait > 0 {
+			ctx.Debugf("waiting %s to start the step", wait)
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Until(xcontext.ErrPaused):
+				release()
+				return nil, xcontext.ErrPaused
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
+
+// semaphoreRegistry holds the named semaphores in use
+type semaphoreRegistry struct {
+	mu         sync.Mutex
+	semaphores map[string]*namedSemaphore
+}
+
+type namedSemaphore struct {
+	capacity int
+	slots    chan struct{}
+	// users is the number of targets holding or waiting for the semaphore,
+	// it's removed from the registry when there are none left
+	users int
+}
+
+func newSemaphoreRegistry() *semaphoreRegistry {
+	return &semaphoreRegistry{semaphores: make(map[string]*namedSemaphore)}
+}
+
+// acquire waits for the semaphore and returns the function releasing it. A
+// semaphore that is in use cannot be used with a different capacity.
+func (r *semaphoreRegistry) acquire(ctx xcontext.Context, s test.StepSemaphore) (func(), error) {
+	r.mu.Lock()
+	sem := r.semaphores[s.Name]
+	if sem == nil {
+		sem = &namedSemaphore{capacity: s.Capacity, slots: make(chan struct{}, s.Capacity)}
+		r.semaphores[s.Name] = sem
+	} else if sem.capacity != s.Capacity {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("semaphore '%s' is in use with capacity %d, not %d", s.Name, sem.capacity, s.Capacity)
+	}
+	sem.users++
+	r.mu.Unlock()
+
+	done := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		sem.users--
+		if sem.users == 0 {
+			delete(r.semaphores, s.Name)
+		}
+	}
+
+	select {
+	case sem.slots <- struct{}{}:
+		return func() {
+			<-sem.slots
+			done()
+		}, nil
+	case <-ctx.Until(xcontext.ErrPaused):
+		done()
+		return nil, xcontext.ErrPaused
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireAsync acquires the limits in the background, the release function
+// or the error are sent to the returned channels
+func acquireAsync(ctx xcontext.Context, l *stepLimiter) (<-chan func(), <-chan error) {
+	released := make(chan func(), 1)
+	failed := make(chan error, 1)
+	go func() {
+		release, err := l.acquire(ctx)
+		if err != nil {
+			failed <- err
+			return
+		}
+		released <- release
+	}()
+	return released, failed
+}
+
+func requireWaiting(t *testing.T, released <-chan func()) {
+	select {
+	case <-released:
+		t.Fatal("limits should not allow the target in")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func requireAcquired(t *testing.T, released <-chan func()) func() {
+	select {
+	case release := <-released:
+		return release
+	case <-time.After(time.Second):
+		t.Fatal("limits should allow the target in")
+		return nil
+	}
+}
+
+func TestStepLimiterMaxConcurrency(t *testing.T) {
+	ctx := xcontext.Background()
+	l := newStepLimiter(test.StepLimits{MaxConcurrency: 2}, newSemaphoreRegistry())
+
+	release1, err := l.acquire(ctx)
+	require.NoError(t, err)
+	_, err = l.acquire(ctx)
+	require.NoError(t, err)
+
+	released, _ := acquireAsync(ctx, l)
+	requireWaiting(t, released)
+	release1()
+	requireAcquired(t, released)
+}
+
+func TestStepLimiterMinStartInterval(t *testing.T) {
+	ctx := xcontext.Background()
+	l := newStepLimiter(test.StepLimits{MinStartInterval: 100 * time.Millisecond}, newSemaphoreRegistry())
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := l.acquire(ctx)
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestStepLimiterSemaphores(t *testing.T) {
+	ctx := xcontext.Background()
+	registry := newSemaphoreRegistry()
+
+	// the steps share the semaphore, even if they are in different jobs
+	rack := test.StepSemaphore{Name: "pdu-rack3", Capacity: 1}
+	l1 := newStepLimiter(test.StepLimits{Semaphores: []test.StepSemaphore{rack}}, registry)
+	l2 := newStepLimiter(test.StepLimits{Semaphores: []test.StepSemaphore{{Name: "tftp", Capacity: 5}, rack}}, registry)
+
+	release1, err := l1.acquire(ctx)
+	require.NoError(t, err)
+	released, _ := acquireAsync(ctx, l2)
+	requireWaiting(t, released)
+
+	// a semaphore in use cannot change capacity
+	_, err = newStepLimiter(test.StepLimits{Semaphores: []test.StepSemaphore{{Name: "pdu-rack3", Capacity: 2}}}, registry).acquire(ctx)
+	require.Error(t, err)
+
+	release1()
+	release2 := requireAcquired(t, released)
+	release2()
+
+	// unused semaphores are forgotten
+	require.Empty(t, registry.semaphores)
+	_, err = newStepLimiter(test.StepLimits{Semaphores: []test.StepSemaphore{{Name: "pdu-rack3", Capacity: 2}}}, registry).acquire(ctx)
+	require.NoError(t, err)
+}
+
+func TestStepLimiterPause(t *testing.T) {
+	ctx, pause := xcontext.WithNotify(xcontext.Background(), xcontext.ErrPaused)
+	registry := newSemaphoreRegistry()
+	l := newStepLimiter(test.StepLimits{MaxConcurrency: 1, Semaphores: []test.StepSemaphore{{Name: "bmc", Capacity: 1}}}, registry)
+
+	_, err := l.acquire(ctx)
+	require.NoError(t, err)
+	released, failed := acquireAsync(ctx, l)
+	requireWaiting(t, released)
+
+	pause()
+	select {
+	case err := <-failed:
+		require.Equal(t, xcontext.ErrPaused, err)
+	case <-time.After(time.Second):
+		t.Fatal("acquiring the limits should stop on pause")
+	}
+}
@@ -398,6 +398,9 @@ func (sr *StepRunner) runningLoop(
 		}()
 
 		inChannels := test.TestStepChannels{In: stepIn, Out: stepOut}
+		if groupStep, ok := bundle.TestStep.(test.GroupTestStep); ok {
+			return runGroupStep(ctx, groupStep, inChannels, ev, stepsVariables, bundle, resumeState)
+		}
 		return bundle.TestStep.Run(ctx, inChannels, ev, stepsVariables, bundle.Parameters, resumeState)
 	}()
 	ctx.Debugf("TestStep finished '%v', rs: '%s'", err, string(resultResumeState))
@@ -24,6 +24,7 @@ type stepState struct {
 	ev                 testevent.Emitter
 	tsv                *testStepsVariables
 	stepRunner         *StepRunner
+	limiter            *stepLimiter
 	addTarget          AddTargetToStep
 	leftTargetsCounter int // the number of targets that will be assigned to the step, when reaches 0 the stepRunner should be stopped
 	stopped            chan struct{}
@@ -52,6 +53,7 @@ func newStepState(
 		ev:                 emitterFactory.New(sb.TestStepLabel),
 		tsv:                tsv,
 		stepRunner:         NewStepRunner(),
+		limiter:            newStepLimiter(sb.Limits, stepSemaphores),
 		stopped:            make(chan struct{}),
 		resumeState:        resumeState,
 		resumeStateTargets: resumeStateTargets,
@@ -130,7 +132,7 @@ func (ss *stepState) Run(ctx xcontext.Context) error {
 	stepCtx = stepCtx.WithField("step_label", ss.sb.TestStepLabel)
 
 	addTarget, resumeTargetsNotifiers, stepRunResult, err := ss.stepRunner.Run(
-		stepCtx, ss.sb, newStepVariablesAccessor(ss.sb.TestStepLabel, ss.tsv), ss.ev, ss.resumeState,
+		stepCtx, ss.sb, newStepVariablesAccessor(ss.sb.TestStepLabel, ss.sb.Loop, ss.tsv), ss.ev, ss.resumeState,
 		ss.resumeStateTargets,
 	)
 	if err != nil {
@@ -164,6 +166,12 @@ func (ss *stepState) Run(ctx xcontext.Context) error {
 	return nil
 }
 
+// AcquireLimits waits until the limits of the step allow a target in, it
+// returns the function to call once the target is done with the step
+func (ss *stepState) AcquireLimits(ctx xcontext.Context) (func(), error) {
+	return ss.limiter.acquire(ctx)
+}
+
 func (ss *stepState) InjectTarget(ctx xcontext.Context, tgt *target.Target) (ChanNotifier, error) {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
@@ -98,6 +98,12 @@ func (tr *TestRunner) Run(
 	resumeState json.RawMessage,
 ) (json.RawMessage, map[string]error, error) {
 
+	for _, sb := range t.TestStepsBundles {
+		if err := test.CheckStepLimits(sb.TestStep, sb.Limits); err != nil {
+			return nil, nil, fmt.Errorf("invalid limits for test step %s: %w", sb.TestStepLabel, err)
+		}
+	}
+
 	// Peel off contexts used for steps and target handlers.
 	runCtx, runCancel := xcontext.WithCancel(ctx)
 	defer runCancel()
@@ -386,6 +392,12 @@ loop:
 		// Make sure we have a step runner active. If not, start one.
 		err := ss.Run(ctx)
 
+		// Wait for the concurrency limits of the step, if any.
+		var releaseLimits func()
+		if err == nil {
+			releaseLimits, err = ss.AcquireLimits(ctx)
+		}
+
 		var targetNotifier ChanNotifier
 		if err == nil {
 			// Inject the target.
@@ -426,6 +438,9 @@ loop:
 				err = ctx.Err()
 			}
 		}
+		if releaseLimits != nil {
+			releaseLimits()
+		}
 		if err != nil {
 			ctx.Errorf("Target handler failed: %v", err)
 			switch err {
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
 	"github.com/linuxboot/contest/pkg/xcontext/logger"
 	"github.com/linuxboot/contest/plugins/teststeps"
+	"github.com/linuxboot/contest/plugins/teststeps/barrier"
 	"github.com/linuxboot/contest/tests/common"
 	"github.com/linuxboot/contest/tests/common/goroutine_leak_check"
 	"github.com/linuxboot/contest/tests/plugins/teststeps/badtargets"
@@ -667,3 +669,213 @@ func (s *TestRunnerSuite) TestPauseResumeSimple() {
 {[1 5 SimpleTest 0 Step3][Target{ID: "T2"} TargetOut]}
 `, s.MemoryStorage.GetTargetEvents(ctx, testName, "T2"))
 }
+
+const groupStepName = "GroupStep"
+
+type groupStep struct {
+	runGroupFunction func(ctx xcontext.Context, targets []*target.Target, ev testevent.Emitter,
+		resumeState json.RawMessage) (test.TestStepGroupResults, json.RawMessage, error)
+}
+
+func (gs *groupStep) Name() string {
+	return groupStepName
+}
+
+func (gs *groupStep) Run(ctx xcontext.Context, ch test.TestStepChannels, ev testevent.Emitter,
+	stepsVars test.StepsVariables, params test.TestStepParameters, resumeState json.RawMessage,
+) (json.RawMessage, error) {
+	return nil, fmt.Errorf("group step should not be run per target")
+}
+
+func (gs *groupStep) WaitsForAllTargets() {}
+
+func (gs *groupStep) RunGroup(ctx xcontext.Context, targets []*target.Target, ev testevent.Emitter,
+	stepsVars test.StepsVariables, params test.TestStepParameters, resumeState json.RawMessage,
+) (test.TestStepGroupResults, json.RawMessage, error) {
+	return gs.runGroupFunction(ctx, targets, ev, resumeState)
+}
+
+func (gs *groupStep) ValidateParameters(ctx xcontext.Context, params test.TestStepParameters) error {
+	return nil
+}
+
+func (s *TestRunnerSuite) registerGroupStep(runGroupFunction func(ctx xcontext.Context, targets []*target.Target,
+	ev testevent.Emitter, resumeState json.RawMessage) (test.TestStepGroupResults, json.RawMessage, error)) {
+	require.NoError(s.T(), s.PluginRegistry.RegisterTestStep(groupStepName, func() test.TestStep {
+		return &groupStep{runGroupFunction: runGroupFunction}
+	}, []event.Name{"GroupEvent"}))
+}
+
+// A group step runs once for the targets that did not fail before it.
+func (s *TestRunnerSuite) TestGroupStep() {
+	ctx, cancel := logrusctx.NewContext(logger.LevelDebug)
+	defer cancel()
+
+	var runs [][]string
+	s.registerGroupStep(func(ctx xcontext.Context, targets []*target.Target, ev testevent.Emitter,
+		resumeState json.RawMessage) (test.TestStepGroupResults, json.RawMessage, error) {
+		var ids []string
+		for _, tgt := range targets {
+			ids = append(ids, tgt.ID)
+		}
+		sort.Strings(ids)
+		runs = append(runs, ids)
+		require.NoError(s.T(), ev.Emit(ctx, testevent.Data{EventName: "GroupEvent"}))
+		return test.TestStepGroupResults{"T3": fmt.Errorf("group failed")}, nil, nil
+	})
+
+	tr := newTestRunner()
+	_, targetsResults, err := s.runWithTimeout(ctx, tr, nil, 1, 2*time.Second,
+		[]*target.Target{tgt("T1"), tgt("T2"), tgt("T3")},
+		[]test.TestStepBundle{
+			s.newTestStep(ctx, "Step1", 0, "T1", "T2=50"),
+			s.NewStep(ctx, "Group", groupStepName, nil),
+			s.newTestStep(ctx, "Step3", 0, "", ""),
+		},
+	)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), [][]string{{"T2", "T3"}}, runs)
+	require.Error(s.T(), targetsResults["T1"])
+	require.NoError(s.T(), targetsResults["T2"])
+	require.Error(s.T(), targetsResults["T3"])
+
+	// the group events are not associated with any target
+	require.Equal(s.T(), `
+{[1 1 SimpleTest 0 Group][(*Target)(nil) GroupEvent]}
+`, s.MemoryStorage.GetStepEvents(ctx, testName, "Group"))
+}
+
+// A group step paused while running is run again on resume with the same
+// targets and its resume state.
+func (s *TestRunnerSuite) TestGroupStepPauseResume() {
+	ctx, cancel := logrusctx.NewContext(logger.LevelDebug)
+	defer cancel()
+
+	var (
+		mu    sync.Mutex
+		pause xcontext.CancelFunc
+		runs  []string
+	)
+	s.registerGroupStep(func(ctx xcontext.Context, targets []*target.Target, ev testevent.Emitter,
+		resumeState json.RawMessage) (test.TestStepGroupResults, json.RawMessage, error) {
+		mu.Lock()
+		runs = append(runs, fmt.Sprintf("%d targets, state '%s'", len(targets), string(resumeState)))
+		p := pause
+		mu.Unlock()
+		if p != nil {
+			p()
+			<-ctx.Until(xcontext.ErrPaused)
+			return nil, json.RawMessage(`"half way"`), xcontext.ErrPaused
+		}
+		return nil, nil, nil
+	})
+
+	targets := []*target.Target{tgt("T1"), tgt("T2")}
+	steps := []test.TestStepBundle{
+		s.newTestStep(ctx, "Step1", 0, "", ""),
+		s.NewStep(ctx, "Group", groupStepName, nil),
+	}
+
+	var resumeState []byte
+	{
+		ctx1, ctxPause := xcontext.WithNotify(ctx, xcontext.ErrPaused)
+		ctx1, ctxCancel := xcontext.WithCancel(ctx1)
+		defer ctxCancel()
+
+		mu.Lock()
+		pause = ctxPause
+		mu.Unlock()
+
+		var err error
+		resumeState, _, err = s.runWithTimeout(ctx1, newTestRunner(), nil, 1, 2*time.Second, targets, steps)
+		require.IsType(s.T(), xcontext.ErrPaused, err)
+		require.NotEmpty(s.T(), resumeState)
+	}
+	{
+		mu.Lock()
+		pause = nil
+		mu.Unlock()
+
+		_, targetsResults, err := s.runWithTimeout(ctx, newTestRunner(), resumeState, 2, 2*time.Second,
+			[]*target.Target{tgt("T1"), tgt("T2")}, steps)
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), targetsResults["T1"])
+		require.NoError(s.T(), targetsResults["T2"])
+	}
+	require.Equal(s.T(), []string{`2 targets, state ''`, `2 targets, state '"half way"'`}, runs)
+}
+
+// A barrier holds the targets until all of them arrive, so limiting the targets
+// in it would deadlock: such a step is rejected instead of hanging.
+func (s *TestRunnerSuite) TestBarrierMaxConcurrency() {
+	ctx, cancel := logrusctx.NewContext(logger.LevelDebug)
+	defer cancel()
+
+	require.NoError(s.T(), s.PluginRegistry.RegisterTestStep(barrier.Load()))
+	_, err := s.PluginRegistry.NewTestStepBundle(ctx, test.TestStepDescriptor{
+		Name:           barrier.Name,
+		Label:          "Barrier",
+		MaxConcurrency: 1,
+	})
+	require.Error(s.T(), err)
+	require.Contains(s.T(), err.Error(), "cannot have limits")
+
+	step := s.NewStep(ctx, "Barrier", barrier.Name, nil)
+	step.Limits = test.StepLimits{MaxConcurrency: 1}
+
+	tr := newTestRunner()
+	_, _, err = s.runWithTimeout(ctx, tr, nil, 1, 2*time.Second,
+		[]*target.Target{tgt("T1"), tgt("T2"), tgt("T3")},
+		[]test.TestStepBundle{step},
+	)
+	require.Error(s.T(), err)
+	require.Contains(s.T(), err.Error(), "cannot have limits")
+}
+
+// The runner does not let more targets than allowed run a step at the same time.
+func (s *TestRunnerSuite) TestStepMaxConcurrency() {
+	ctx, cancel := logrusctx.NewContext(logger.LevelDebug)
+	defer cancel()
+
+	var (
+		mu              sync.Mutex
+		running, maxRun int
+	)
+	require.NoError(s.T(), s.RegisterStateFullStep(
+		func(ctx xcontext.Context, ch test.TestStepChannels, ev testevent.Emitter,
+			stepsVars test.StepsVariables, params test.TestStepParameters, resumeState json.RawMessage,
+		) (json.RawMessage, error) {
+			return teststeps.ForEachTarget(stateFullStepName, ctx, ch, func(ctx xcontext.Context, target *target.Target) error {
+				mu.Lock()
+				running++
+				if running > maxRun {
+					maxRun = running
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+		},
+		nil,
+	))
+
+	step := s.NewStep(ctx, "Step1", stateFullStepName, nil)
+	step.Limits = test.StepLimits{MaxConcurrency: 2}
+
+	tr := newTestRunner()
+	_, targetsResults, err := s.runWithTimeout(ctx, tr, nil, 1, 2*time.Second,
+		[]*target.Target{tgt("T1"), tgt("T2"), tgt("T3"), tgt("T4"), tgt("T5")},
+		[]test.TestStepBundle{step},
+	)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), targetsResults, 5)
+	for id, res := range targetsResults {
+		require.NoError(s.T(), res, id)
+	}
+	require.Equal(s.T(), 2, maxRun)
+}
@@ -115,12 +115,14 @@ func (tsv *testStepsVariables) checkInput(tgtID string, stepLabel string, name s
 
 type stepVariablesAccessor struct {
 	stepLabel string
+	loop      *test.StepLoop
 	tsv       *testStepsVariables
 }
 
-func newStepVariablesAccessor(stepLabel string, tsv *testStepsVariables) *stepVariablesAccessor {
+func newStepVariablesAccessor(stepLabel string, loop *test.StepLoop, tsv *testStepsVariables) *stepVariablesAccessor {
 	return &stepVariablesAccessor{
 		stepLabel: stepLabel,
+		loop:      loop,
 		tsv:       tsv,
 	}
 }
@@ -151,4 +153,9 @@ func (sva *stepVariablesAccessor) Get(tgtID string, stepLabel, name string, out
 	return json.Unmarshal(b, out)
 }
 
+func (sva *stepVariablesAccessor) StepLoop() *test.StepLoop {
+	return sva.loop
+}
+
 var _ test.StepsVariables = (*stepVariablesAccessor)(nil)
+var _ test.StepLoopReader = (*stepVariablesAccessor)(nil)
@@ -0,0 +1,54 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// ImportStorageManager stores events which were emitted elsewhere, e.g. by
+// another server, as they are. Unlike the emitters, it keeps the original
+// emit time of the events. Importing always uses the SyncEngine.
+type ImportStorageManager struct {
+	vault EngineVault
+}
+
+// StoreTestEvents stores the given test events in order.
+func (ism ImportStorageManager) StoreTestEvents(ctx xcontext.Context, events []testevent.Event) error {
+	storage, err := ism.vault.GetEngine(SyncEngine)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if err := storage.StoreTestEvent(ctx, ev); err != nil {
+			return fmt.Errorf("could not persist test event %d: %w", ev.SequenceID, err)
+		}
+	}
+	return nil
+}
+
+// StoreFrameworkEvents stores the given framework events in order.
+func (ism ImportStorageManager) StoreFrameworkEvents(ctx xcontext.Context, events []frameworkevent.Event) error {
+	storage, err := ism.vault.GetEngine(SyncEngine)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if err := storage.StoreFrameworkEvent(ctx, ev); err != nil {
+			return fmt.Errorf("could not persist framework event %d: %w", ev.SequenceID, err)
+		}
+	}
+	return nil
+}
+
+// NewImportStorageManager creates a new ImportStorageManager object.
+func NewImportStorageManager(vault EngineVault) ImportStorageManager {
+	return ImportStorageManager{vault: vault}
+}
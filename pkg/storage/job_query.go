@@ -8,6 +8,7 @@ package storage
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/linuxboot/contest/pkg/job"
 )
@@ -22,11 +23,14 @@ type JobQuery struct {
 	States   []job.State
 	Tags     []string
 	ServerID string
+	// RequestedBefore restricts the query to jobs requested before the given time.
+	RequestedBefore time.Time
 }
 
 type jobQueryFieldStates []job.State
 type jobQueryFieldTags []string
 type jobQueryFieldServerID string
+type jobQueryFieldRequestedBefore time.Time
 
 func QueryJobStates(states ...job.State) JobQueryField { return jobQueryFieldStates(states) }
 func (value jobQueryFieldStates) queryFieldPointer(query *JobQuery) interface{} {
@@ -43,6 +47,11 @@ func (value jobQueryFieldServerID) queryFieldPointer(query *JobQuery) interface{
 	return &query.ServerID
 }
 
+func QueryJobRequestedBefore(t time.Time) JobQueryField { return jobQueryFieldRequestedBefore(t) }
+func (value jobQueryFieldRequestedBefore) queryFieldPointer(query *JobQuery) interface{} {
+	return &query.RequestedBefore
+}
+
 func BuildJobQuery(queryFields ...JobQueryField) (*JobQuery, error) {
 	return JobQueryFields(queryFields).BuildQuery()
 }
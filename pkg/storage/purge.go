@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"errors"
+
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// ErrPurgeNotSupported is returned when the storage engine does not implement
+// PurgeStorage.
+var ErrPurgeNotSupported = errors.New("storage engine does not support purging jobs")
+
+// PurgeStorage is implemented by storage engines that can permanently delete
+// the data of a job, which is required to enforce retention policies.
+type PurgeStorage interface {
+	// PurgeJob deletes the request, tags, reports, test events and framework
+	// events of a job. Purging a job that does not exist is not an error.
+	PurgeJob(ctx xcontext.Context, jobID types.JobID) error
+}
+
+// PurgeStorageManager implements PurgeStorage on top of the storage engines
+// in the vault. Purging always uses the SyncEngine.
+type PurgeStorageManager struct {
+	vault EngineVault
+}
+
+// PurgeJob implements PurgeStorage.
+func (psm PurgeStorageManager) PurgeJob(ctx xcontext.Context, jobID types.JobID) error {
+	storage, err := psm.vault.GetEngine(SyncEngine)
+	if err != nil {
+		return err
+	}
+	purgeStorage, ok := storage.(PurgeStorage)
+	if !ok {
+		return ErrPurgeNotSupported
+	}
+	return purgeStorage.PurgeJob(ctx, jobID)
+}
+
+// NewPurgeStorageManager creates a new PurgeStorageManager object.
+func NewPurgeStorageManager(vault EngineVault) PurgeStorageManager {
+	return PurgeStorageManager{vault: vault}
+}
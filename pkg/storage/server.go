@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// ErrServerStorageNotSupported is returned when the storage engine does not
+// implement ServerStorage.
+var ErrServerStorageNotSupported = errors.New("storage engine does not support server tracking")
+
+// ServerInfo describes a ConTest server instance sharing the storage with
+// other instances.
+type ServerInfo struct {
+	ServerID    string
+	InstanceTag string
+	// Heartbeat is the last time the server reported itself as alive.
+	Heartbeat time.Time
+}
+
+// ServerStorage is implemented by storage engines that can keep track of the
+// liveness of server instances, which is required for surviving servers to
+// take over the jobs of dead ones.
+type ServerStorage interface {
+	// StoreServerHeartbeat records the server as alive as of info.Heartbeat.
+	StoreServerHeartbeat(ctx xcontext.Context, info ServerInfo) error
+	// ListServers returns all the known servers with the given instance tag.
+	ListServers(ctx xcontext.Context, instanceTag string) ([]ServerInfo, error)
+	// AcquireServerLease grants holder the exclusive right to operate on the
+	// jobs of serverID until expiresAt. It returns false if another holder
+	// has a lease that has not expired as of now.
+	AcquireServerLease(ctx xcontext.Context, serverID, holder string, now, expiresAt time.Time) (bool, error)
+	// RemoveDeadServer forgets serverID once holder took over its jobs, unless
+	// it reported itself as alive since deadBefore or the lease changed hands.
+	RemoveDeadServer(ctx xcontext.Context, serverID, holder string, deadBefore time.Time) error
+	// UpdateJobServerID assigns the job to a different server.
+	UpdateJobServerID(ctx xcontext.Context, jobID types.JobID, serverID string) error
+}
+
+// ServerStorageManager implements ServerStorage on top of the storage engines
+// in the vault. All operations use the SyncEngine, as they require read after
+// write consistency.
+type ServerStorageManager struct {
+	vault EngineVault
+}
+
+func (ssm ServerStorageManager) engine() (ServerStorage, error) {
+	storage, err := ssm.vault.GetEngine(SyncEngine)
+	if err != nil {
+		return nil, err
+	}
+	serverStorage, ok := storage.(ServerStorage)
+	if !ok {
+		return nil, ErrServerStorageNotSupported
+	}
+	return serverStorage, nil
+}
+
+// StoreServerHeartbeat implements ServerStorage.
+func (ssm ServerStorageManager) StoreServerHeartbeat(ctx xcontext.Context, info ServerInfo) error {
+	storage, err := ssm.engine()
+	if err != nil {
+		return err
+	}
+	return storage.StoreServerHeartbeat(ctx, info)
+}
+
+// ListServers implements ServerStorage.
+func (ssm ServerStorageManager) ListServers(ctx xcontext.Context, instanceTag string) ([]ServerInfo, error) {
+	storage, err := ssm.engine()
+	if err != nil {
+		return nil, err
+	}
+	return storage.ListServers(ctx, instanceTag)
+}
+
+// AcquireServerLease implements ServerStorage.
+func (ssm ServerStorageManager) AcquireServerLease(ctx xcontext.Context, serverID, holder string, now, expiresAt time.Time) (bool, error) {
+	storage, err := ssm.engine()
+	if err != nil {
+		return false, err
+	}
+	return storage.AcquireServerLease(ctx, serverID, holder, now, expiresAt)
+}
+
+// RemoveDeadServer implements ServerStorage.
+func (ssm ServerStorageManager) RemoveDeadServer(ctx xcontext.Context, serverID, holder string, deadBefore time.Time) error {
+	storage, err := ssm.engine()
+	if err != nil {
+		return err
+	}
+	return storage.RemoveDeadServer(ctx, serverID, holder, deadBefore)
+}
+
+// UpdateJobServerID implements ServerStorage.
+func (ssm ServerStorageManager) UpdateJobServerID(ctx xcontext.Context, jobID types.JobID, serverID string) error {
+	storage, err := ssm.engine()
+	if err != nil {
+		return err
+	}
+	return storage.UpdateJobServerID(ctx, jobID, serverID)
+}
+
+// NewServerStorageManager creates a new ServerStorageManager object.
+func NewServerStorageManager(vault EngineVault) ServerStorageManager {
+	return ServerStorageManager{vault: vault}
+}
@@ -8,6 +8,7 @@ package target
 import (
 	"time"
 
+	"github.com/linuxboot/contest/pkg/event"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
@@ -15,6 +16,25 @@ import (
 // locker defines the locking engine used by ConTest.
 var locker Locker
 
+// EventTargetLockForceUnlocked is emitted as a framework event for the job
+// holding a lock that was removed by an administrative override.
+var EventTargetLockForceUnlocked = event.Name("TargetLockForceUnlocked")
+
+// LockInfo describes a lock on a target.
+type LockInfo struct {
+	TargetID string
+	// JobID is the owner of the lock.
+	JobID     types.JobID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ForceUnlockPayload is the payload of the TargetLockForceUnlocked event.
+type ForceUnlockPayload struct {
+	Lock      LockInfo
+	Requestor string
+}
+
 // LockerFactory is a type representing a function which builds
 // a Locker.
 type LockerFactory func(time.Duration, time.Duration) Locker
@@ -57,6 +77,19 @@ type Locker interface {
 	// Passing empty list of targets is allowed and is a no-op.
 	RefreshLocks(ctx xcontext.Context, jobID types.JobID, duration time.Duration, targets []*Target) error
 
+	// ListLocks returns the locks on the specified targets, sorted by target ID.
+	// Expired locks that have not been taken over yet are included.
+	// Targets that are not locked are omitted. Passing empty list of targets
+	// returns all the known locks.
+	ListLocks(ctx xcontext.Context, targets []*Target) ([]LockInfo, error)
+
+	// ForceUnlock unlocks the specified targets regardless of the owner, and
+	// returns the locks that were removed. This is an administrative override
+	// for stuck locks, the owner is not notified.
+	// Targets that are not locked are ignored.
+	// Passing empty list of targets is allowed and is a no-op.
+	ForceUnlock(ctx xcontext.Context, targets []*Target) ([]LockInfo, error)
+
 	// Close finalizes the locker and releases resources.
 	// No API calls must be in flight when this is invoked or afterwards.
 	Close() error
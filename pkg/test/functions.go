@@ -6,6 +6,7 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -72,6 +73,28 @@ func registerStepVariableAccessor(fm map[string]interface{}, tgtID string, vars
 		}
 		return i, nil
 	}
+	fm["LoopIndex"] = func() (int, error) {
+		loop, err := stepLoopOf(vars)
+		if err != nil {
+			return 0, err
+		}
+		return loop.Index, nil
+	}
+	// LoopValue returns strings as they are and other values as JSON
+	fm["LoopValue"] = func() (string, error) {
+		loop, err := stepLoopOf(vars)
+		if err != nil {
+			return "", err
+		}
+		if loop.Value == nil {
+			return "", fmt.Errorf("the step is not in a ForEach loop")
+		}
+		var s string
+		if err := json.Unmarshal(loop.Value, &s); err == nil {
+			return s, nil
+		}
+		return string(loop.Value), nil
+	}
 }
 
 // RegisterFunction registers a template function suitable for text/template.
@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"time"
 
+	"github.com/insomniacslk/xjson"
 	"github.com/linuxboot/contest/pkg/event"
 	"github.com/linuxboot/contest/pkg/event/testevent"
 	"github.com/linuxboot/contest/pkg/target"
@@ -83,6 +85,29 @@ type TestStepDescriptor struct {
 	Label            string
 	Parameters       TestStepParameters
 	VariablesMapping map[string]string
+
+	// Repeat, if set, runs the step this many times in a row, see ExpandStepLoops
+	Repeat int `json:",omitempty"`
+	// ForEach, if set, runs the step once for each value, see ExpandStepLoops
+	ForEach []json.RawMessage `json:",omitempty"`
+
+	// MaxConcurrency, MinStartInterval and Semaphores limit how the targets
+	// run the step, see StepLimits
+	MaxConcurrency   int             `json:",omitempty"`
+	MinStartInterval xjson.Duration  `json:",omitempty"`
+	Semaphores       []StepSemaphore `json:",omitempty"`
+
+	// Loop is set on the steps expanded from a Repeat or ForEach loop
+	Loop *StepLoop `json:"-"`
+}
+
+// StepLimits returns the limits of the step
+func (d TestStepDescriptor) StepLimits() StepLimits {
+	return StepLimits{
+		MaxConcurrency:   d.MaxConcurrency,
+		MinStartInterval: time.Duration(d.MinStartInterval),
+		Semaphores:       d.Semaphores,
+	}
 }
 
 // TestStepBundle bundles the selected TestStep together with its parameters as
@@ -92,6 +117,10 @@ type TestStepBundle struct {
 	TestStepLabel string
 	Parameters    TestStepParameters
 	AllowedEvents map[event.Name]bool
+	// Loop is the iteration of the step, if it was expanded from a loop
+	Loop *StepLoop
+	// Limits limit how the targets run the step
+	Limits StepLimits
 }
 
 // TestStepResult is used by TestSteps to report result for a particular target.
@@ -144,6 +173,33 @@ type TestStep interface {
 	ValidateParameters(ctx xcontext.Context, params TestStepParameters) error
 }
 
+// TestStepGroupResults are the results of a group step by target ID. Empty Err
+// means success, and so does a missing result.
+type TestStepGroupResults map[string]error
+
+// RendezvousTestStep is implemented by the steps that hold the targets until
+// all the targets still alive have reached them, like group steps and barriers.
+type RendezvousTestStep interface {
+	TestStep
+	// WaitsForAllTargets does nothing, it marks the step as a rendezvous.
+	WaitsForAllTargets()
+}
+
+// GroupTestStep is implemented by the steps that run once for all the targets
+// instead of once per target, like setup and teardown steps. The TestRunner
+// waits for all the targets still alive to reach the step, and calls RunGroup
+// instead of Run. Events that are about the group rather than a single target
+// are emitted without a target.
+type GroupTestStep interface {
+	RendezvousTestStep
+	// RunGroup runs the step on all the targets and returns their results.
+	// Like Run, it returns its resume state along with xcontext.ErrPaused when
+	// paused, and an error when the step itself failed, which fails the test.
+	RunGroup(ctx xcontext.Context, targets []*target.Target, ev testevent.Emitter,
+		stepsVars StepsVariables, params TestStepParameters,
+		resumeState json.RawMessage) (TestStepGroupResults, json.RawMessage, error)
+}
+
 var identifierRegexPattern *regexp.Regexp
 
 func init() {
@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package test
+
+import (
+	"fmt"
+	"time"
+)
+
+// StepSemaphore is a named semaphore shared by all the steps of all the jobs
+// of a server that use it, like a lab resource that can only serve a few
+// targets at a time. Each target holds it while running the step.
+type StepSemaphore struct {
+	Name string
+	// Capacity is how many targets can hold the semaphore at the same time
+	Capacity int
+}
+
+// StepLimits limit how targets run a step. They are enforced by the
+// TestRunner, which only injects a target into the step once they allow it.
+type StepLimits struct {
+	// MaxConcurrency is the maximum number of targets running the step at the
+	// same time, 0 for no limit
+	MaxConcurrency int
+	// MinStartInterval is the minimum time between the starts of the step on
+	// two targets
+	MinStartInterval time.Duration
+	// Semaphores are the named semaphores each target holds while running the step
+	Semaphores []StepSemaphore
+}
+
+// IsZero returns true if there are no limits
+func (l StepLimits) IsZero() bool {
+	return l.MaxConcurrency == 0 && l.MinStartInterval == 0 && len(l.Semaphores) == 0
+}
+
+// Validate checks the limits
+func (l StepLimits) Validate() error {
+	if l.MaxConcurrency < 0 {
+		return fmt.Errorf("invalid MaxConcurrency %d: must not be negative", l.MaxConcurrency)
+	}
+	if l.MinStartInterval < 0 {
+		return fmt.Errorf("invalid MinStartInterval %s: must not be negative", l.MinStartInterval)
+	}
+	names := make(map[string]bool, len(l.Semaphores))
+	for _, s := range l.Semaphores {
+		if s.Name == "" {
+			return fmt.Errorf("semaphores must have a name")
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate semaphore '%s'", s.Name)
+		}
+		names[s.Name] = true
+		if s.Capacity <= 0 {
+			return fmt.Errorf("invalid capacity %d for semaphore '%s': must be positive", s.Capacity, s.Name)
+		}
+	}
+	return nil
+}
+
+// CheckStepLimits checks that the step can have the limits. Rendezvous steps
+// cannot: the targets they hold would keep the slots the others wait for.
+func CheckStepLimits(step TestStep, limits StepLimits) error {
+	if _, ok := step.(RendezvousTestStep); ok && !limits.IsZero() {
+		return fmt.Errorf("steps waiting for all the targets, like group steps, cannot have limits")
+	}
+	return nil
+}
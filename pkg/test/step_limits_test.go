@@ -0,0 +1,45 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepLimits(t *testing.T) {
+	var d TestStepDescriptor
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "cmd",
+		"label": "flash",
+		"MaxConcurrency": 10,
+		"MinStartInterval": "5s",
+		"Semaphores": [{"Name": "pdu-rack3", "Capacity": 2}]
+	}`), &d))
+
+	limits := d.StepLimits()
+	require.NoError(t, limits.Validate())
+	require.Equal(t, StepLimits{
+		MaxConcurrency:   10,
+		MinStartInterval: 5 * time.Second,
+		Semaphores:       []StepSemaphore{{Name: "pdu-rack3", Capacity: 2}},
+	}, limits)
+	require.False(t, limits.IsZero())
+	require.True(t, StepLimits{}.IsZero())
+
+	for _, invalid := range []StepLimits{
+		{MaxConcurrency: -1},
+		{MinStartInterval: -time.Second},
+		{Semaphores: []StepSemaphore{{Capacity: 1}}},
+		{Semaphores: []StepSemaphore{{Name: "tftp"}}},
+		{Semaphores: []StepSemaphore{{Name: "tftp", Capacity: 1}, {Name: "tftp", Capacity: 1}}},
+	} {
+		require.Error(t, invalid.Validate(), "%+v", invalid)
+	}
+}
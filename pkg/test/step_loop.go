@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxStepLoopIterations is the maximum number of steps a loop can be expanded into
+const MaxStepLoopIterations = 1000
+
+// StepLoop is the iteration of a step expanded from a Repeat or ForEach loop
+type StepLoop struct {
+	// Index is the iteration number, starting from 0
+	Index int
+	// Value is the ForEach value of the iteration, it's nil for Repeat loops
+	Value json.RawMessage
+}
+
+// StepLoopReader is implemented by the step variables passed to the steps
+// expanded from a loop, so that their templates can use the iteration
+type StepLoopReader interface {
+	// StepLoop returns the iteration of the step, or nil if it's not in a loop
+	StepLoop() *StepLoop
+}
+
+// StepLoopLabel returns the label of the step of a loop at the given iteration
+func StepLoopLabel(label string, index int) string {
+	return fmt.Sprintf("%s_%d", label, index)
+}
+
+// ExpandStepLoops replaces every step descriptor with a Repeat or ForEach loop
+// with a step descriptor per iteration. The steps of the iterations are labelled
+// as "<label>_<index>", and run one after the other for each target.
+func ExpandStepLoops(descriptors []*TestStepDescriptor) ([]*TestStepDescriptor, error) {
+	var expanded []*TestStepDescriptor
+	for _, d := range descriptors {
+		if d == nil || (d.Repeat == 0 && d.ForEach == nil) {
+			expanded = append(expanded, d)
+			continue
+		}
+
+		if d.Label == "" {
+			return nil, fmt.Errorf("test step '%s' with a loop must have a label", d.Name)
+		}
+		if d.Repeat != 0 && d.ForEach != nil {
+			return nil, fmt.Errorf("test step '%s' cannot have both Repeat and ForEach", d.Label)
+		}
+
+		iterations := d.Repeat
+		if d.ForEach != nil {
+			iterations = len(d.ForEach)
+		}
+		if iterations <= 0 || iterations > MaxStepLoopIterations {
+			return nil, fmt.Errorf("test step '%s' loop must have between 1 and %d iterations, got %d", d.Label, MaxStepLoopIterations, iterations)
+		}
+
+		for i := 0; i < iterations; i++ {
+			loop := &StepLoop{Index: i}
+			if d.ForEach != nil {
+				loop.Value = d.ForEach[i]
+			}
+			expanded = append(expanded, &TestStepDescriptor{
+				Name:             d.Name,
+				Label:            StepLoopLabel(d.Label, i),
+				Parameters:       d.Parameters,
+				VariablesMapping: d.VariablesMapping,
+				MaxConcurrency:   d.MaxConcurrency,
+				MinStartInterval: d.MinStartInterval,
+				Semaphores:       d.Semaphores,
+				Loop:             loop,
+			})
+		}
+	}
+	return expanded, nil
+}
+
+// stepLoopOf returns the iteration of the step the variables are passed to
+func stepLoopOf(vars StepsVariablesReader) (*StepLoop, error) {
+	if lr, ok := vars.(StepLoopReader); ok {
+		if loop := lr.StepLoop(); loop != nil {
+			return loop, nil
+		}
+	}
+	return nil, fmt.Errorf("the step is not in a Repeat or ForEach loop")
+}
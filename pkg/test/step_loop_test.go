@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/stretchr/testify/require"
+)
+
+type loopVars struct {
+	loop *StepLoop
+}
+
+func (v loopVars) Get(tgtID string, stepLabel, name string, out interface{}) error {
+	return fmt.Errorf("no variable %s.%s", stepLabel, name)
+}
+
+func (v loopVars) StepLoop() *StepLoop {
+	return v.loop
+}
+
+func TestExpandStepLoops(t *testing.T) {
+	var descriptors []*TestStepDescriptor
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"name": "cmd", "label": "reboot", "repeat": 2},
+		{"name": "cmd", "label": "check"},
+		{"name": "cmd", "label": "slot", "foreach": ["pci0", {"bus": 1}]}
+	]`), &descriptors))
+
+	expanded, err := ExpandStepLoops(descriptors)
+	require.NoError(t, err)
+
+	var labels []string
+	for _, d := range expanded {
+		labels = append(labels, d.Label)
+	}
+	require.Equal(t, []string{"reboot_0", "reboot_1", "check", "slot_0", "slot_1"}, labels)
+	require.Equal(t, &StepLoop{Index: 1}, expanded[1].Loop)
+	require.Nil(t, expanded[2].Loop)
+	require.Equal(t, json.RawMessage(`{"bus": 1}`), expanded[4].Loop.Value)
+
+	// expanding again keeps the same steps, as it happens when a job is resumed
+	again, err := ExpandStepLoops(expanded)
+	require.NoError(t, err)
+	require.Equal(t, expanded, again)
+
+	for _, invalid := range []string{
+		`[{"name": "cmd", "repeat": 2}]`,
+		`[{"name": "cmd", "label": "a", "repeat": -1}]`,
+		`[{"name": "cmd", "label": "a", "repeat": 100000}]`,
+		`[{"name": "cmd", "label": "a", "foreach": []}]`,
+		`[{"name": "cmd", "label": "a", "repeat": 2, "foreach": [1, 2]}]`,
+	} {
+		var descriptors []*TestStepDescriptor
+		require.NoError(t, json.Unmarshal([]byte(invalid), &descriptors))
+		_, err := ExpandStepLoops(descriptors)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestStepLoopFunctions(t *testing.T) {
+	tgt := &target.Target{ID: "1"}
+
+	res, err := NewParam("{{ LoopIndex }}: {{ LoopValue }}").Expand(tgt, loopVars{&StepLoop{Index: 3, Value: json.RawMessage(`"pci0"`)}})
+	require.NoError(t, err)
+	require.Equal(t, "3: pci0", res)
+
+	res, err = NewParam("{{ LoopValue }}").Expand(tgt, loopVars{&StepLoop{Value: json.RawMessage(`{"bus":1}`)}})
+	require.NoError(t, err)
+	require.Equal(t, `{"bus":1}`, res)
+
+	_, err = NewParam("{{ LoopValue }}").Expand(tgt, loopVars{&StepLoop{Index: 3}})
+	require.Error(t, err)
+
+	_, err = NewParam("{{ LoopIndex }}").Expand(tgt, loopVars{})
+	require.Error(t, err)
+}
@@ -6,16 +6,19 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/linuxboot/contest/pkg/api"
 	"github.com/linuxboot/contest/pkg/job"
@@ -85,9 +88,51 @@ func (h *HTTP) Stop(ctx context.Context, requestor string, jobID types.JobID) (*
 	return &api.StopResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
 }
 
+func (h *HTTP) Pause(ctx context.Context, requestor string, jobID types.JobID) (*api.PauseResponse, error) {
+	params := url.Values{}
+	params.Add("jobID", strconv.Itoa(int(jobID)))
+	resp, err := h.request(requestor, "pause", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataPause{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.PauseResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Resume(ctx context.Context, requestor string, jobID types.JobID) (*api.ResumeResponse, error) {
+	params := url.Values{}
+	params.Add("jobID", strconv.Itoa(int(jobID)))
+	resp, err := h.request(requestor, "resume", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataResume{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.ResumeResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
 func (h *HTTP) Status(ctx context.Context, requestor string, jobID types.JobID) (*api.StatusResponse, error) {
+	return h.StatusPage(ctx, requestor, jobID, 0, 0)
+}
+
+func (h *HTTP) StatusPage(ctx context.Context, requestor string, jobID types.JobID, runsAfter types.RunID, maxRuns uint) (*api.StatusResponse, error) {
 	params := url.Values{}
 	params.Add("jobID", strconv.Itoa(int(jobID)))
+	if runsAfter != 0 {
+		params.Add("runsAfter", strconv.FormatUint(uint64(runsAfter), 10))
+	}
+	if maxRuns != 0 {
+		params.Add("maxRuns", strconv.FormatUint(uint64(maxRuns), 10))
+	}
 	resp, err := h.request(requestor, "status", params)
 	if err != nil {
 		return nil, err
@@ -142,7 +187,151 @@ func (h *HTTP) List(ctx context.Context, requestor string, states []job.State, t
 	return &api.ListResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
 }
 
+func (h *HTTP) Validate(ctx context.Context, requestor string, jobDescriptor string) (*api.ValidateResponse, error) {
+	params := url.Values{}
+	params.Add("jobDesc", jobDescriptor)
+	resp, err := h.request(requestor, "validate", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataValidate{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.ValidateResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Locks(ctx context.Context, requestor string, targetIDs []string) (*api.LocksResponse, error) {
+	params := url.Values{}
+	if len(targetIDs) > 0 {
+		params.Add("targetIDs", strings.Join(targetIDs, ","))
+	}
+	resp, err := h.request(requestor, "locks", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataLocks{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.LocksResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Unlock(ctx context.Context, requestor string, targetIDs []string) (*api.UnlockResponse, error) {
+	params := url.Values{}
+	params.Add("targetIDs", strings.Join(targetIDs, ","))
+	resp, err := h.request(requestor, "unlock", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataUnlock{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.UnlockResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Purge(ctx context.Context, requestor string, jobID types.JobID, archive bool) (*api.PurgeResponse, error) {
+	params := url.Values{}
+	params.Add("jobID", strconv.Itoa(int(jobID)))
+	params.Add("archive", strconv.FormatBool(archive))
+	resp, err := h.request(requestor, "purge", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataPurge{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.PurgeResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Analytics(ctx context.Context, requestor string, tag string, since, until time.Time) (*api.AnalyticsResponse, error) {
+	params := url.Values{}
+	params.Add("tag", tag)
+	if !since.IsZero() {
+		params.Add("since", since.Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		params.Add("until", until.Format(time.RFC3339))
+	}
+	resp, err := h.request(requestor, "analytics", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataAnalytics{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.AnalyticsResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Diff(ctx context.Context, requestor string, jobA, jobB types.JobID) (*api.DiffResponse, error) {
+	params := url.Values{}
+	params.Add("jobA", strconv.Itoa(int(jobA)))
+	params.Add("jobB", strconv.Itoa(int(jobB)))
+	resp, err := h.request(requestor, "diff", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataDiff{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.DiffResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Export(ctx context.Context, requestor string, jobID types.JobID) (*api.ExportResponse, error) {
+	params := url.Values{}
+	params.Add("jobID", strconv.Itoa(int(jobID)))
+	resp, err := h.request(requestor, "export", params)
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataExport{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.ExportResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
+func (h *HTTP) Import(ctx context.Context, requestor string, archive []byte) (*api.ImportResponse, error) {
+	// The archive can be large, so it is uploaded as a file rather than as a
+	// form value, which the server limits in size.
+	resp, err := h.requestWithFiles(requestor, "import", url.Values{}, map[string][]byte{"jobArchive": archive})
+	if err != nil {
+		return nil, err
+	}
+	data := api.ResponseDataImport{}
+	if string(resp.Data) != "" {
+		if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+			return nil, fmt.Errorf("cannot decode json response: %v", err)
+		}
+	}
+	return &api.ImportResponse{ServerID: resp.ServerID, Data: data, Err: resp.Error}, nil
+}
+
 func (h *HTTP) request(requestor string, verb string, params url.Values) (*HTTPPartiallyDecodedResponse, error) {
+	return h.requestWithFiles(requestor, verb, params, nil)
+}
+
+// requestWithFiles is like request, but also uploads the given files in a
+// multipart form, if any.
+func (h *HTTP) requestWithFiles(requestor string, verb string, params url.Values, files map[string][]byte) (*HTTPPartiallyDecodedResponse, error) {
 	params.Set("requestor", requestor)
 	u, err := url.Parse(h.Addr)
 	if err != nil {
@@ -160,8 +349,37 @@ func (h *HTTP) request(requestor string, verb string, params url.Values) (*HTTPP
 	for k, v := range params {
 		fmt.Fprintf(os.Stderr, "    %s: %s\n", k, v)
 	}
+	for k, v := range files {
+		fmt.Fprintf(os.Stderr, "    %s: <%d bytes>\n", k, len(v))
+	}
 	fmt.Fprintf(os.Stderr, "\n")
-	resp, err := http.PostForm(u.String(), params)
+	var resp *http.Response
+	if len(files) == 0 {
+		resp, err = http.PostForm(u.String(), params)
+	} else {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for k, vs := range params {
+			for _, v := range vs {
+				if err := mw.WriteField(k, v); err != nil {
+					return nil, fmt.Errorf("cannot encode form field %s: %v", k, err)
+				}
+			}
+		}
+		for k, v := range files {
+			fw, err := mw.CreateFormFile(k, k)
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode form file %s: %v", k, err)
+			}
+			if _, err := fw.Write(v); err != nil {
+				return nil, fmt.Errorf("cannot encode form file %s: %v", k, err)
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, fmt.Errorf("cannot encode multipart form: %v", err)
+		}
+		resp, err = http.Post(u.String(), mw.FormDataContentType(), &body)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("HTTP POST failed: %v", err)
 	}
@@ -7,6 +7,7 @@ package transport
 
 import (
 	"context"
+	"time"
 
 	"github.com/linuxboot/contest/pkg/api"
 	"github.com/linuxboot/contest/pkg/job"
@@ -20,6 +21,18 @@ type Transport interface {
 	Start(ctx context.Context, requestor string, jobDescriptor string) (*api.StartResponse, error)
 	Stop(ctx context.Context, requestor string, jobID types.JobID) (*api.StopResponse, error)
 	Status(ctx context.Context, requestor string, jobID types.JobID) (*api.StatusResponse, error)
+	StatusPage(ctx context.Context, requestor string, jobID types.JobID, runsAfter types.RunID, maxRuns uint) (*api.StatusResponse, error)
+	Pause(ctx context.Context, requestor string, jobID types.JobID) (*api.PauseResponse, error)
+	Resume(ctx context.Context, requestor string, jobID types.JobID) (*api.ResumeResponse, error)
 	Retry(ctx context.Context, requestor string, jobID types.JobID) (*api.RetryResponse, error)
 	List(ctx context.Context, requestor string, states []job.State, tags []string) (*api.ListResponse, error)
+	Validate(ctx context.Context, requestor string, jobDescriptor string) (*api.ValidateResponse, error)
+	Locks(ctx context.Context, requestor string, targetIDs []string) (*api.LocksResponse, error)
+	Unlock(ctx context.Context, requestor string, targetIDs []string) (*api.UnlockResponse, error)
+	Purge(ctx context.Context, requestor string, jobID types.JobID, archive bool) (*api.PurgeResponse, error)
+	Analytics(ctx context.Context, requestor string, tag string, since, until time.Time) (*api.AnalyticsResponse, error)
+	Diff(ctx context.Context, requestor string, jobA, jobB types.JobID) (*api.DiffResponse, error)
+	Export(ctx context.Context, requestor string, jobID types.JobID) (*api.ExportResponse, error)
+	// Import imports a job archive, either as JSON or as gzipped JSON.
+	Import(ctx context.Context, requestor string, archive []byte) (*api.ImportResponse, error)
 }
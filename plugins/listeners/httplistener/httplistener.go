@@ -6,10 +6,13 @@
 package httplistener
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -86,6 +89,27 @@ type apiHandler struct {
 	api *api.API
 }
 
+// readJobArchive reads the job archive uploaded as the jobArchive file, and
+// decompresses it if it is gzipped.
+func readJobArchive(r *http.Request) ([]byte, error) {
+	f, _, err := r.FormFile("jobArchive")
+	if err != nil {
+		return nil, fmt.Errorf("missing job archive: %w", err)
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var archive io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzipped job archive: %w", err)
+		}
+		defer zr.Close()
+		archive = zr
+	}
+	return io.ReadAll(archive)
+}
+
 func (h *apiHandler) reply(w http.ResponseWriter, status int, msg string) {
 	w.WriteHeader(status)
 	if _, err := fmt.Fprint(w, msg); err != nil {
@@ -127,6 +151,16 @@ func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			httpStatus = http.StatusBadRequest
 			errMsg = fmt.Sprintf("Start failed: %v", err)
 		}
+	case "validate":
+		if jobDesc == "" {
+			httpStatus = http.StatusBadRequest
+			errMsg = "Missing job description"
+			break
+		}
+		if resp, err = h.api.Validate(ctx, requestor, jobDesc); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Validate failed: %v", err)
+		}
 	case "status":
 		jobID, err := strToJobID(jobIDStr)
 		if err != nil {
@@ -134,7 +168,22 @@ func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			errMsg = fmt.Sprintf("Status failed: %v", err)
 			break
 		}
-		if resp, err = h.api.Status(ctx, requestor, jobID); err != nil {
+		var runsAfter, maxRuns uint64
+		if runsAfterStr := r.PostFormValue("runsAfter"); runsAfterStr != "" {
+			if runsAfter, err = strconv.ParseUint(runsAfterStr, 10, 64); err != nil {
+				httpStatus = http.StatusBadRequest
+				errMsg = fmt.Sprintf("Status failed: invalid runsAfter: %v", err)
+				break
+			}
+		}
+		if maxRunsStr := r.PostFormValue("maxRuns"); maxRunsStr != "" {
+			if maxRuns, err = strconv.ParseUint(maxRunsStr, 10, 64); err != nil {
+				httpStatus = http.StatusBadRequest
+				errMsg = fmt.Sprintf("Status failed: invalid maxRuns: %v", err)
+				break
+			}
+		}
+		if resp, err = h.api.StatusPage(ctx, requestor, jobID, types.RunID(runsAfter), uint(maxRuns)); err != nil {
 			httpStatus = http.StatusBadRequest
 			errMsg = fmt.Sprintf("Status failed: %v", err)
 		}
@@ -149,6 +198,28 @@ func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			httpStatus = http.StatusBadRequest
 			errMsg = fmt.Sprintf("Stop failed: %v", err)
 		}
+	case "pause":
+		jobID, err := strToJobID(jobIDStr)
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Pause failed: %v", err)
+			break
+		}
+		if resp, err = h.api.Pause(ctx, requestor, jobID); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Pause failed: %v", err)
+		}
+	case "resume":
+		jobID, err := strToJobID(jobIDStr)
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Resume failed: %v", err)
+			break
+		}
+		if resp, err = h.api.Resume(ctx, requestor, jobID); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Resume failed: %v", err)
+		}
 	case "retry":
 		jobID, err := strToJobID(jobIDStr)
 		if err != nil {
@@ -188,6 +259,106 @@ func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			httpStatus = http.StatusBadRequest
 			errMsg = fmt.Sprintf("List failed: %v", err)
 		}
+	case "locks":
+		var targetIDs []string
+		if targetIDsStr := r.PostFormValue("targetIDs"); len(targetIDsStr) > 0 {
+			targetIDs = strings.Split(targetIDsStr, ",")
+		}
+		if resp, err = h.api.Locks(ctx, requestor, targetIDs); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Locks failed: %v", err)
+		}
+	case "unlock":
+		targetIDsStr := r.PostFormValue("targetIDs")
+		if targetIDsStr == "" {
+			httpStatus = http.StatusBadRequest
+			errMsg = "Missing target IDs"
+			break
+		}
+		if resp, err = h.api.Unlock(ctx, requestor, strings.Split(targetIDsStr, ",")); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Unlock failed: %v", err)
+		}
+	case "purge":
+		jobID, err := strToJobID(jobIDStr)
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Purge failed: %v", err)
+			break
+		}
+		var archive bool
+		if archiveStr := r.PostFormValue("archive"); archiveStr != "" {
+			if archive, err = strconv.ParseBool(archiveStr); err != nil {
+				httpStatus = http.StatusBadRequest
+				errMsg = fmt.Sprintf("Purge failed: invalid archive flag: %v", err)
+				break
+			}
+		}
+		if resp, err = h.api.Purge(ctx, requestor, jobID, archive); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Purge failed: %v", err)
+		}
+	case "analytics":
+		// The window defaults to all the events emitted until now.
+		var since, until time.Time
+		if sinceStr := r.PostFormValue("since"); sinceStr != "" {
+			if since, err = time.Parse(time.RFC3339, sinceStr); err != nil {
+				httpStatus = http.StatusBadRequest
+				errMsg = fmt.Sprintf("Analytics failed: invalid since: %v", err)
+				break
+			}
+		}
+		until = time.Now()
+		if untilStr := r.PostFormValue("until"); untilStr != "" {
+			if until, err = time.Parse(time.RFC3339, untilStr); err != nil {
+				httpStatus = http.StatusBadRequest
+				errMsg = fmt.Sprintf("Analytics failed: invalid until: %v", err)
+				break
+			}
+		}
+		if resp, err = h.api.Analytics(ctx, requestor, r.PostFormValue("tag"), since, until); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Analytics failed: %v", err)
+		}
+	case "diff":
+		jobA, err := strToJobID(r.PostFormValue("jobA"))
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Diff failed: invalid jobA: %v", err)
+			break
+		}
+		jobB, err := strToJobID(r.PostFormValue("jobB"))
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Diff failed: invalid jobB: %v", err)
+			break
+		}
+		if resp, err = h.api.Diff(ctx, requestor, jobA, jobB); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Diff failed: %v", err)
+		}
+	case "export":
+		jobID, err := strToJobID(jobIDStr)
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Export failed: %v", err)
+			break
+		}
+		if resp, err = h.api.Export(ctx, requestor, jobID); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Export failed: %v", err)
+		}
+	case "import":
+		archive, err := readJobArchive(r)
+		if err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Import failed: %v", err)
+			break
+		}
+		if resp, err = h.api.Import(ctx, requestor, archive); err != nil {
+			httpStatus = http.StatusBadRequest
+			errMsg = fmt.Sprintf("Import failed: %v", err)
+		}
 	case "version":
 		resp = h.api.Version()
 	default:
@@ -0,0 +1,34 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package noop implements a no-op API listener, which does not accept any
+// request. It is useful when the JobManager is driven in-process.
+package noop
+
+import (
+	"errors"
+
+	"github.com/linuxboot/contest/pkg/api"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// Noop implements the api.Listener interface.
+type Noop struct{}
+
+// New instantiates a new no-op listener.
+func New() *Noop {
+	return &Noop{}
+}
+
+// Serve implements the api.Listener.Serve interface method. It does not serve
+// any request, and just waits for the context to be cancelled.
+func (n *Noop) Serve(ctx xcontext.Context, a *api.API) error {
+	if a == nil {
+		return errors.New("API object is nil")
+	}
+	ctx.Debugf("Serving a no-op listener")
+	<-ctx.Done()
+	return nil
+}
@@ -29,8 +29,19 @@ type Memory struct {
 	lock            sync.Mutex
 	testEvents      []testevent.Event
 	frameworkEvents []frameworkevent.Event
-	jobIDCounter    types.JobID
-	jobInfo         map[types.JobID]*jobInfo
+	// Sequence IDs are not derived from the length of the event lists, as
+	// purging jobs removes events.
+	testEventSeq      uint64
+	frameworkEventSeq uint64
+	jobIDCounter      types.JobID
+	jobInfo           map[types.JobID]*jobInfo
+	servers           map[string]*serverInfo
+}
+
+type serverInfo struct {
+	info           storage.ServerInfo
+	leaseHolder    string
+	leaseExpiresAt time.Time
 }
 
 type jobInfo struct {
@@ -41,7 +52,21 @@ type jobInfo struct {
 }
 
 func emptyEventQuery(eventQuery *event.Query) bool {
-	return eventQuery.JobID == 0 && len(eventQuery.EventNames) == 0 && eventQuery.EmittedStartTime.IsZero() && eventQuery.EmittedEndTime.IsZero()
+	return eventQuery.JobID == 0 && len(eventQuery.EventNames) == 0 && eventQuery.EmittedStartTime.IsZero() && eventQuery.EmittedEndTime.IsZero() && eventQuery.SequenceIDAfter == 0
+}
+
+// eventIndex returns the index of the i-th of n events to visit in the given
+// order. Events are stored in ascending order of sequence ID.
+func eventIndex(i, n int, order event.Order) int {
+	if order == event.OrderDescending {
+		return n - 1 - i
+	}
+	return i
+}
+
+// limitReached returns whether count events are enough for the query.
+func limitReached(eventQuery *event.Query, count int) bool {
+	return eventQuery.Limit > 0 && uint(count) >= eventQuery.Limit
 }
 
 // emptyFrameworkEventQuery returns whether the Query contains only default values
@@ -62,7 +87,8 @@ func emptyTestEventQuery(eventQuery *testevent.Query) bool {
 func (m *Memory) StoreTestEvent(_ xcontext.Context, event testevent.Event) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	event.SequenceID = uint64(len(m.testEvents)) + 1
+	m.testEventSeq++
+	event.SequenceID = m.testEventSeq
 	m.testEvents = append(m.testEvents, event)
 	return nil
 }
@@ -130,8 +156,13 @@ func (m *Memory) GetTestEvents(_ xcontext.Context, eventQuery *testevent.Query)
 		return matchingTestEvents, nil
 	}
 
-	for _, event := range m.testEvents {
-		if eventJobMatch(eventQuery.JobID, event.Header.JobID) &&
+	for i := range m.testEvents {
+		if limitReached(&eventQuery.Query, len(matchingTestEvents)) {
+			break
+		}
+		event := m.testEvents[eventIndex(i, len(m.testEvents), eventQuery.Order)]
+		if event.SequenceID > eventQuery.SequenceIDAfter &&
+			eventJobMatch(eventQuery.JobID, event.Header.JobID) &&
 			eventRunMatch(eventQuery.RunID, event.Header.RunID) &&
 			eventNameMatch(eventQuery.EventNames, event.Data.EventName) &&
 			eventTimeMatch(eventQuery.EmittedStartTime, eventQuery.EmittedEndTime, event.EmitTime) &&
@@ -150,6 +181,9 @@ func (m *Memory) Reset() error {
 	m.testEvents = []testevent.Event{}
 	m.frameworkEvents = []frameworkevent.Event{}
 	m.jobInfo = make(map[types.JobID]*jobInfo)
+	m.servers = make(map[string]*serverInfo)
+	m.testEventSeq = 0
+	m.frameworkEventSeq = 0
 	m.jobIDCounter = 1
 	return nil
 }
@@ -252,6 +286,9 @@ jobLoop:
 				continue
 			}
 		}
+		if !query.RequestedBefore.IsZero() && !jobInfo.request.RequestTime.Before(query.RequestedBefore) {
+			continue
+		}
 		if len(query.Tags) > 0 {
 			for _, qTag := range query.Tags {
 				found := false
@@ -297,7 +334,8 @@ jobLoop:
 func (m *Memory) StoreFrameworkEvent(_ xcontext.Context, event frameworkevent.Event) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	event.SequenceID = uint64(len(m.frameworkEvents)) + 1
+	m.frameworkEventSeq++
+	event.SequenceID = m.frameworkEventSeq
 	m.frameworkEvents = append(m.frameworkEvents, event)
 	return nil
 }
@@ -312,8 +350,13 @@ func (m *Memory) GetFrameworkEvent(_ xcontext.Context, eventQuery *frameworkeven
 	if emptyFrameworkEventQuery(eventQuery) {
 		return matchingFrameworkEvents, nil
 	}
-	for _, event := range m.frameworkEvents {
-		if eventJobMatch(eventQuery.JobID, event.JobID) &&
+	for i := range m.frameworkEvents {
+		if limitReached(&eventQuery.Query, len(matchingFrameworkEvents)) {
+			break
+		}
+		event := m.frameworkEvents[eventIndex(i, len(m.frameworkEvents), eventQuery.Order)]
+		if event.SequenceID > eventQuery.SequenceIDAfter &&
+			eventJobMatch(eventQuery.JobID, event.JobID) &&
 			eventNameMatch(eventQuery.EventNames, event.EventName) &&
 			eventTimeMatch(eventQuery.EmittedStartTime, eventQuery.EmittedEndTime, event.EmitTime) {
 			matchingFrameworkEvents = append(matchingFrameworkEvents, event)
@@ -322,6 +365,96 @@ func (m *Memory) GetFrameworkEvent(_ xcontext.Context, eventQuery *frameworkeven
 	return matchingFrameworkEvents, nil
 }
 
+// PurgeJob deletes the request, reports, test events and framework events of a job
+func (m *Memory) PurgeJob(_ xcontext.Context, jobID types.JobID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.jobInfo, jobID)
+	testEvents := m.testEvents[:0]
+	for _, ev := range m.testEvents {
+		if ev.Header.JobID != jobID {
+			testEvents = append(testEvents, ev)
+		}
+	}
+	m.testEvents = testEvents
+	frameworkEvents := m.frameworkEvents[:0]
+	for _, ev := range m.frameworkEvents {
+		if ev.JobID != jobID {
+			frameworkEvents = append(frameworkEvents, ev)
+		}
+	}
+	m.frameworkEvents = frameworkEvents
+	return nil
+}
+
+// StoreServerHeartbeat records the server as alive as of info.Heartbeat
+func (m *Memory) StoreServerHeartbeat(_ xcontext.Context, info storage.ServerInfo) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	si := m.servers[info.ServerID]
+	if si == nil {
+		si = &serverInfo{}
+		m.servers[info.ServerID] = si
+	}
+	si.info = info
+	return nil
+}
+
+// ListServers returns all the known servers with the given instance tag
+func (m *Memory) ListServers(_ xcontext.Context, instanceTag string) ([]storage.ServerInfo, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var res []storage.ServerInfo
+	for _, si := range m.servers {
+		if si.info.InstanceTag == instanceTag {
+			res = append(res, si.info)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ServerID < res[j].ServerID })
+	return res, nil
+}
+
+// AcquireServerLease grants holder the exclusive right to operate on the jobs
+// of serverID until expiresAt, unless another holder has a valid lease
+func (m *Memory) AcquireServerLease(_ xcontext.Context, serverID, holder string, now, expiresAt time.Time) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	si := m.servers[serverID]
+	if si == nil {
+		return false, fmt.Errorf("unknown server %q", serverID)
+	}
+	if si.leaseHolder != "" && si.leaseHolder != holder && si.leaseExpiresAt.After(now) {
+		return false, nil
+	}
+	si.leaseHolder = holder
+	si.leaseExpiresAt = expiresAt
+	return true, nil
+}
+
+// RemoveDeadServer forgets serverID once holder took over its jobs, unless it
+// reported itself as alive since deadBefore or the lease changed hands
+func (m *Memory) RemoveDeadServer(_ xcontext.Context, serverID, holder string, deadBefore time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	si := m.servers[serverID]
+	if si != nil && si.leaseHolder == holder && si.info.Heartbeat.Before(deadBefore) {
+		delete(m.servers, serverID)
+	}
+	return nil
+}
+
+// UpdateJobServerID assigns the job to a different server
+func (m *Memory) UpdateJobServerID(_ xcontext.Context, jobID types.JobID, serverID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ji := m.jobInfo[jobID]
+	if ji == nil {
+		return fmt.Errorf("could not find job with id %v", jobID)
+	}
+	ji.request.ServerID = serverID
+	return nil
+}
+
 // Close flushes pending events and closes the database connection.
 func (m *Memory) Close() error {
 	m.lock.Lock()
@@ -330,6 +463,7 @@ func (m *Memory) Close() error {
 	m.testEvents = nil
 	m.frameworkEvents = nil
 	m.jobInfo = nil
+	m.servers = nil
 	return nil
 }
 
@@ -342,6 +476,7 @@ func (m *Memory) Version() (uint64, error) {
 func New() (storage.ResettableStorage, error) {
 	m := &Memory{
 		jobInfo:      make(map[types.JobID]*jobInfo),
+		servers:      make(map[string]*serverInfo),
 		jobIDCounter: 1,
 	}
 	return m, nil
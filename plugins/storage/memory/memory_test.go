@@ -6,11 +6,17 @@
 package memory
 
 import (
+	"encoding/json"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
 	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
 	"github.com/linuxboot/contest/pkg/xcontext/logger"
 	"github.com/stretchr/testify/require"
@@ -85,3 +91,89 @@ func TestMemory_GetTestEvents(t *testing.T) {
 	requireEqualExpectSequenceID(t, ev1, evs[0])
 	requireEqualExpectSequenceID(t, ev2, evs[1])
 }
+
+func TestMemory_ServerLease(t *testing.T) {
+	stor, err := New()
+	require.NoError(t, err)
+	ss := stor.(storage.ServerStorage)
+
+	now := time.Now()
+	require.NoError(t, ss.StoreServerHeartbeat(ctx, storage.ServerInfo{ServerID: "b", InstanceTag: "_tag", Heartbeat: now}))
+	require.NoError(t, ss.StoreServerHeartbeat(ctx, storage.ServerInfo{ServerID: "a", InstanceTag: "_tag", Heartbeat: now}))
+	require.NoError(t, ss.StoreServerHeartbeat(ctx, storage.ServerInfo{ServerID: "c", Heartbeat: now}))
+
+	servers, err := ss.ListServers(ctx, "_tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.Equal(t, "a", servers[0].ServerID)
+	require.Equal(t, "b", servers[1].ServerID)
+
+	acquired, err := ss.AcquireServerLease(ctx, "a", "b", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+	// Held by another server until it expires.
+	acquired, err = ss.AcquireServerLease(ctx, "a", "c", now.Add(time.Second), now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, acquired)
+	// The holder can renew it.
+	acquired, err = ss.AcquireServerLease(ctx, "a", "b", now.Add(time.Second), now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+	// Expired leases can be acquired.
+	acquired, err = ss.AcquireServerLease(ctx, "a", "c", now.Add(3*time.Minute), now.Add(4*time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	_, err = ss.AcquireServerLease(ctx, "unknown", "c", now, now.Add(time.Minute))
+	require.Error(t, err)
+
+	// Only the lease holder removes a server, and only if it is still dead.
+	require.NoError(t, ss.RemoveDeadServer(ctx, "a", "b", now.Add(time.Minute)))
+	require.NoError(t, ss.RemoveDeadServer(ctx, "a", "c", now))
+	servers, err = ss.ListServers(ctx, "_tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.NoError(t, ss.RemoveDeadServer(ctx, "a", "c", now.Add(time.Minute)))
+	servers, err = ss.ListServers(ctx, "_tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	require.Equal(t, "b", servers[0].ServerID)
+}
+
+func TestMemory_PurgeJob(t *testing.T) {
+	stor, err := New()
+	require.NoError(t, err)
+
+	now := time.Now()
+	desc, err := json.Marshal(job.Descriptor{JobName: "test"})
+	require.NoError(t, err)
+	var jobIDs []types.JobID
+	for _, requestTime := range []time.Time{now.Add(-time.Hour), now} {
+		jobID, err := stor.StoreJobRequest(ctx, &job.Request{JobName: "test", JobDescriptor: string(desc), RequestTime: requestTime})
+		require.NoError(t, err)
+		require.NoError(t, stor.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: jobID, EventName: job.EventJobStarted, EmitTime: now}))
+		require.NoError(t, stor.StoreTestEvent(ctx, testevent.Event{EmitTime: now, Header: &testevent.Header{JobID: jobID}, Data: &testevent.Data{}}))
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	old, err := stor.ListJobs(ctx, &storage.JobQuery{RequestedBefore: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, jobIDs[:1], old)
+
+	require.NoError(t, stor.(storage.PurgeStorage).PurgeJob(ctx, jobIDs[0]))
+	_, err = stor.GetJobRequest(ctx, jobIDs[0])
+	require.Error(t, err)
+	evs, err := stor.GetTestEvents(ctx, &testevent.Query{Query: event.Query{JobID: jobIDs[0]}})
+	require.NoError(t, err)
+	require.Empty(t, evs)
+	fevs, err := stor.GetFrameworkEvent(ctx, &frameworkevent.Query{Query: event.Query{JobID: jobIDs[1]}})
+	require.NoError(t, err)
+	require.Len(t, fevs, 1)
+
+	// Sequence IDs are not reused after a purge.
+	require.NoError(t, stor.StoreTestEvent(ctx, testevent.Event{EmitTime: now, Header: &testevent.Header{JobID: jobIDs[1]}, Data: &testevent.Data{}}))
+	evs, err = stor.GetTestEvents(ctx, &testevent.Query{Query: event.Query{JobID: jobIDs[1]}})
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	require.Equal(t, uint64(3), evs[1].SequenceID)
+}
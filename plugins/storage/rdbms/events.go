@@ -73,11 +73,27 @@ func buildEventQuery(baseQuery safesql.TrustedSQLString, eventQuery *event.Query
 	}
 	if eventQuery != nil && !eventQuery.EmittedEndTime.IsZero() {
 		selectClauses = append(selectClauses, safesql.New("emit_time<=?"))
-		fields = append(fields, eventQuery.EmittedStartTime)
+		fields = append(fields, eventQuery.EmittedEndTime)
+	}
+	if eventQuery != nil && eventQuery.SequenceIDAfter != 0 {
+		selectClauses = append(selectClauses, safesql.New("event_id>?"))
+		fields = append(fields, eventQuery.SequenceIDAfter)
 	}
 	return selectClauses, fields
 }
 
+// orderAndLimit returns the ordering and limit clauses to append to an event query.
+func orderAndLimit(eventQuery *event.Query) safesql.TrustedSQLString {
+	clause := safesql.New(" order by event_id")
+	if eventQuery.Order == event.OrderDescending {
+		clause = safesql.New(" order by event_id desc")
+	}
+	if eventQuery.Limit > 0 {
+		clause = safesql.TrustedSQLStringConcat(clause, safesql.New(" limit "), safesql.NewFromUint64(uint64(eventQuery.Limit)))
+	}
+	return clause
+}
+
 func buildFrameworkEventQuery(baseQuery safesql.TrustedSQLString, frameworkEventQuery *frameworkevent.Query) (safesql.TrustedSQLString, []interface{}, error) {
 	selectClauses, fields := buildEventQuery(baseQuery, &frameworkEventQuery.Query)
 	query, err := assembleQuery(baseQuery, selectClauses)
@@ -85,7 +101,7 @@ func buildFrameworkEventQuery(baseQuery safesql.TrustedSQLString, frameworkEvent
 		return safesql.New(""), nil, fmt.Errorf("could not assemble query for framework events: %v", err)
 
 	}
-	return query, fields, nil
+	return safesql.TrustedSQLStringConcat(query, orderAndLimit(&frameworkEventQuery.Query)), fields, nil
 }
 
 func buildTestEventQuery(baseQuery safesql.TrustedSQLString, testEventQuery *testevent.Query) (safesql.TrustedSQLString, []interface{}, error) {
@@ -113,7 +129,7 @@ func buildTestEventQuery(baseQuery safesql.TrustedSQLString, testEventQuery *tes
 		return safesql.New(""), nil, fmt.Errorf("could not assemble query for framework events: %v", err)
 
 	}
-	return query, fields, nil
+	return safesql.TrustedSQLStringConcat(query, orderAndLimit(&testEventQuery.Query)), fields, nil
 }
 
 // TestEventField is a function type which retrieves information from a TestEvent object.
@@ -188,6 +204,19 @@ func TestEventEmitTime(ev testevent.Event) interface{} {
 	return ev.EmitTime
 }
 
+// textValue converts JSON payloads into strings. Some drivers send byte
+// slices as binary data, which cannot be stored into TEXT columns. Empty
+// payloads are stored as NULL, since they are not valid JSON.
+func textValue(v interface{}) interface{} {
+	if payload, ok := v.(*json.RawMessage); ok {
+		if payload == nil || len(*payload) == 0 {
+			return nil
+		}
+		return string(*payload)
+	}
+	return v
+}
+
 // StoreTestEvent appends an event to the internal buffer and triggers a flush
 // when the internal storage utilization goes beyond `testEventsFlushSize`
 func (r *RDBMS) StoreTestEvent(_ xcontext.Context, event testevent.Event) error {
@@ -211,7 +240,7 @@ func (r *RDBMS) flushTestEventsLocked() error {
 
 	insertStatement := safesql.New("insert into test_events (job_id, run_id, test_name, test_attempt, test_step_label, event_name, target_id, payload, emit_time) values (?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	for _, event := range r.buffTestEvents {
-		_, err := r.db.Exec(
+		_, err := r.exec(
 			insertStatement,
 			TestEventJobID(event),
 			TestEventRunID(event),
@@ -220,7 +249,7 @@ func (r *RDBMS) flushTestEventsLocked() error {
 			TestEventTestStepLabel(event),
 			TestEventName(event),
 			TestEventTargetID(event),
-			TestEventPayload(event),
+			textValue(TestEventPayload(event)),
 			TestEventEmitTime(event))
 		if err != nil {
 			return fmt.Errorf("could not store event in database: %v", err)
@@ -259,7 +288,7 @@ func (r *RDBMS) GetTestEvents(ctx xcontext.Context, eventQuery *testevent.Query)
 
 	var results []testevent.Event
 	ctx.Debugf("Executing query: %s, fields: %v", query, fields)
-	rows, err := r.db.Query(query, fields...)
+	rows, err := r.query(query, fields...)
 	if err != nil {
 		return nil, err
 	}
@@ -364,11 +393,11 @@ func (r *RDBMS) flushFrameworkEventsLocked() error {
 	// TODO: put this into a transaction.
 	jobStateUpdates := map[types.JobID]job.State{}
 	for _, event := range r.buffFrameworkEvents {
-		_, err := r.db.Exec(
+		_, err := r.exec(
 			safesql.New(insertFEStmt),
 			FrameworkEventJobID(event),
 			FrameworkEventName(event),
-			FrameworkEventPayload(event),
+			textValue(FrameworkEventPayload(event)),
 			FrameworkEventEmitTime(event))
 		if err != nil {
 			return fmt.Errorf("could not store event in database: %v", err)
@@ -378,7 +407,7 @@ func (r *RDBMS) flushFrameworkEventsLocked() error {
 		}
 	}
 	for jobID, state := range jobStateUpdates {
-		if _, err := r.db.Exec(safesql.New(updateJobStateStmt), state, jobID); err != nil {
+		if _, err := r.exec(safesql.New(updateJobStateStmt), state, jobID); err != nil {
 			return fmt.Errorf("could not update state of job %d: %w", jobID, err)
 		}
 	}
@@ -412,7 +441,7 @@ func (r *RDBMS) GetFrameworkEvent(ctx xcontext.Context, eventQuery *frameworkeve
 	}
 	results := []frameworkevent.Event{}
 	ctx.Debugf("Executing query: %s, fields: %v", query, fields)
-	rows, err := r.db.Query(query, fields...)
+	rows, err := r.query(query, fields...)
 	if err != nil {
 		return nil, err
 	}
@@ -16,10 +16,10 @@ import (
 	"github.com/linuxboot/contest/pkg/storage"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/linuxboot/contest/tools/migration/rdbms/migrationlib"
 	"github.com/google/go-safeweb/safesql"
-	// this blank import registers the mysql driver
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/go-safeweb/safesql/uncheckedconversions"
+	"github.com/linuxboot/contest/db/rdbms/dialect"
+	"github.com/linuxboot/contest/tools/migration/rdbms/migrationlib"
 )
 
 // txbeginner defines an interface for a backend which supports beginning a transaction
@@ -42,14 +42,15 @@ type tx interface {
 }
 
 // RDBMS implements a storage engine which stores ConTest information in a relational
-// database via the database/sql package. With the current implementation, only MySQL
-// is officially supported. Within MySQL, the current limitations are the following:
+// database via the database/sql package. MySQL and PostgreSQL are supported, the
+// dialect is inferred from the database URI. The current limitations are the following:
 //
 // It's not possible to use prepared statements. Not all MySQL connectors
 // implementing database/sql support prepared statements, so the plugin cannot
 // depend on them.
 type RDBMS struct {
 	dbURI, driverName string
+	dialect           dialect.Dialect
 
 	buffTestEvents      []testevent.Event
 	buffFrameworkEvents []frameworkevent.Event
@@ -103,7 +104,26 @@ func (r *RDBMS) BeginTx() (storage.TransactionalStorage, error) {
 		return nil, err
 	}
 
-	return &RDBMS{db: tx, sqlDB: r.sqlDB, closeCh: r.closeCh, closeWG: r.closeWG}, nil
+	return &RDBMS{db: tx, sqlDB: r.sqlDB, dialect: r.dialect, closeCh: r.closeCh, closeWG: r.closeWG}, nil
+}
+
+// rebind adapts the placeholders of a query to the dialect of the database.
+func (r *RDBMS) rebind(query safesql.TrustedSQLString) safesql.TrustedSQLString {
+	if r.dialect == dialect.MySQL {
+		return query
+	}
+	// Only placeholders are replaced, so the query is as trusted as the original.
+	return uncheckedconversions.TrustedSQLStringFromStringKnownToSatisfyTypeContract(r.dialect.Rebind(query.String()))
+}
+
+// exec executes a statement written with "?" placeholders.
+func (r *RDBMS) exec(query safesql.TrustedSQLString, args ...interface{}) (sql.Result, error) {
+	return r.db.Exec(r.rebind(query), args...)
+}
+
+// query runs a query written with "?" placeholders.
+func (r *RDBMS) query(query safesql.TrustedSQLString, args ...interface{}) (*sql.Rows, error) {
+	return r.db.Query(r.rebind(query), args...)
 }
 
 // Commit persists the current transaction, if there is one active
@@ -148,8 +168,9 @@ func (r *RDBMS) Reset() error {
 		safesql.New("final_reports"),
 		safesql.New("test_events"),
 		safesql.New("framework_events"),
+		safesql.New("servers"),
 	} {
-		if _, err := r.db.Exec(safesql.TrustedSQLStringConcat(safesql.New("TRUNCATE TABLE "), t)); err != nil {
+		if _, err := r.exec(safesql.TrustedSQLStringConcat(safesql.New("TRUNCATE TABLE "), t)); err != nil {
 			return err
 		}
 	}
@@ -158,9 +179,12 @@ func (r *RDBMS) Reset() error {
 
 func (r *RDBMS) init() error {
 
-	driverName := "mysql"
-	if r.driverName != "" {
-		driverName = r.driverName
+	driverName := r.driverName
+	if driverName != "" {
+		r.dialect = dialect.FromDriverName(driverName)
+	} else {
+		r.dialect = dialect.FromURI(r.dbURI)
+		driverName = r.dialect.DriverName()
 	}
 	sqlDB, err := safesql.Open(driverName, r.dbURI)
 	if err != nil {
@@ -254,8 +278,9 @@ func FrameworkEventsFlushInterval(flushInterval time.Duration) Opt {
 	}
 }
 
-// DriverName allows using a mysql-compatible driver (e.g. a wrapper around mysql
-// or a syntax-compatible variant).
+// DriverName allows using a driver other than the default one of the dialect
+// (e.g. a wrapper around mysql or a syntax-compatible variant). Drivers other
+// than "postgres" are assumed to be mysql-compatible.
 func DriverName(name string) Opt {
 	return func(rdbms *RDBMS) {
 		rdbms.driverName = name
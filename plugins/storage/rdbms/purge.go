@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package rdbms
+
+import (
+	"fmt"
+
+	"github.com/google/go-safeweb/safesql"
+
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// PurgeJob deletes the request, tags, reports, test events and framework
+// events of a job.
+func (r *RDBMS) PurgeJob(_ xcontext.Context, jobID types.JobID) error {
+	// Buffered events of the job would otherwise be written after the purge.
+	if err := r.flushTestEvents(); err != nil {
+		return fmt.Errorf("could not flush test events before purging job %d: %w", jobID, err)
+	}
+	if err := r.flushFrameworkEvents(); err != nil {
+		return fmt.Errorf("could not flush framework events before purging job %d: %w", jobID, err)
+	}
+
+	r.lockTx()
+	defer r.unlockTx()
+
+	// The jobs row goes last, so that a failed purge can be retried: the job
+	// is still listed until all its data is gone.
+	for _, t := range []safesql.TrustedSQLString{
+		safesql.New("test_events"),
+		safesql.New("framework_events"),
+		safesql.New("run_reports"),
+		safesql.New("final_reports"),
+		safesql.New("job_tags"),
+		safesql.New("jobs"),
+	} {
+		stmt := safesql.TrustedSQLStringConcat(safesql.New("delete from "), t, safesql.New(" where job_id = ?"))
+		if _, err := r.exec(stmt, jobID); err != nil {
+			return fmt.Errorf("could not purge %s of job %d: %w", t, jobID, err)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"github.com/google/go-safeweb/safesql"
 
+	"github.com/linuxboot/contest/db/rdbms/dialect"
 	"github.com/linuxboot/contest/pkg/job"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
@@ -44,18 +45,27 @@ func (r *RDBMS) StoreJobRequest(_ xcontext.Context, request *job.Request) (types
 	}
 
 	// store job descriptor
-	result, err := r.db.Exec(safesql.New(insertJobStmt), request.JobName, request.JobDescriptor, extendedDescriptor, request.Requestor, request.ServerID, request.RequestTime)
-	if err != nil {
-		return jobID, fmt.Errorf("could not store job request in database: %w", err)
-	}
-	lastID, err := result.LastInsertId()
-	if err != nil {
-		return jobID, fmt.Errorf("could not extract id of last request inserted into db")
+	args := []interface{}{request.JobName, request.JobDescriptor, string(extendedDescriptor), request.Requestor, request.ServerID, request.RequestTime}
+	switch r.dialect {
+	case dialect.PostgreSQL:
+		// LastInsertId is not supported by the postgres driver.
+		if jobID, err = r.insertReturningJobID(safesql.New(insertJobStmt+" returning job_id"), args...); err != nil {
+			return jobID, fmt.Errorf("could not store job request in database: %w", err)
+		}
+	default:
+		result, err := r.exec(safesql.New(insertJobStmt), args...)
+		if err != nil {
+			return jobID, fmt.Errorf("could not store job request in database: %w", err)
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return jobID, fmt.Errorf("could not extract id of last request inserted into db")
+		}
+		jobID = types.JobID(lastID)
 	}
-	jobID = types.JobID(lastID)
 
 	for _, tag := range desc.Tags {
-		if _, err := r.db.Exec(safesql.New(insertJobTagStmt), jobID, tag); err != nil {
+		if _, err := r.exec(safesql.New(insertJobTagStmt), jobID, tag); err != nil {
 			return 0, fmt.Errorf("could not store job tag in the database: %w", err)
 		}
 	}
@@ -63,6 +73,29 @@ func (r *RDBMS) StoreJobRequest(_ xcontext.Context, request *job.Request) (types
 	return jobID, nil
 }
 
+// insertReturningJobID executes an insert statement which returns the ID of the
+// new row.
+func (r *RDBMS) insertReturningJobID(stmt safesql.TrustedSQLString, args ...interface{}) (types.JobID, error) {
+	rows, err := r.query(stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("no id returned")
+	}
+	var id types.JobID
+	if err := rows.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, rows.Close()
+}
+
 // GetJobRequest retrieves a JobRequest from the database
 func (r *RDBMS) GetJobRequest(ctx xcontext.Context, jobID types.JobID) (*job.Request, error) {
 
@@ -71,7 +104,7 @@ func (r *RDBMS) GetJobRequest(ctx xcontext.Context, jobID types.JobID) (*job.Req
 
 	selectStatement := safesql.New("select job_id, name, requestor, server_id, request_time, descriptor,  extended_descriptor from jobs where job_id = ?")
 	ctx.Debugf("Executing query: %s", selectStatement)
-	rows, err := r.db.Query(selectStatement, jobID)
+	rows, err := r.query(selectStatement, jobID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get job request with id %v: %v", jobID, err)
 	}
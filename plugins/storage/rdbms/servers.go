@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package rdbms
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/go-safeweb/safesql"
+
+	"github.com/linuxboot/contest/db/rdbms/dialect"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// StoreServerHeartbeat records the server as alive as of info.Heartbeat.
+func (r *RDBMS) StoreServerHeartbeat(_ xcontext.Context, info storage.ServerInfo) error {
+	r.lockTx()
+	defer r.unlockTx()
+
+	const insertServerStmt = "insert into servers (server_id, instance_tag, heartbeat_time) values (?, ?, ?)"
+	stmt := safesql.New(insertServerStmt + " on duplicate key update instance_tag = values(instance_tag), heartbeat_time = values(heartbeat_time)")
+	if r.dialect == dialect.PostgreSQL {
+		stmt = safesql.New(insertServerStmt + " on conflict (server_id) do update set instance_tag = excluded.instance_tag, heartbeat_time = excluded.heartbeat_time")
+	}
+	if _, err := r.exec(stmt, info.ServerID, info.InstanceTag, info.Heartbeat); err != nil {
+		return fmt.Errorf("could not store heartbeat for server %q: %w", info.ServerID, err)
+	}
+	return nil
+}
+
+// ListServers returns all the known servers with the given instance tag.
+func (r *RDBMS) ListServers(ctx xcontext.Context, instanceTag string) ([]storage.ServerInfo, error) {
+	r.lockTx()
+	defer r.unlockTx()
+
+	rows, err := r.query(
+		safesql.New("select server_id, instance_tag, heartbeat_time from servers where instance_tag = ? order by server_id"),
+		instanceTag)
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			ctx.Warnf("could not close rows for servers: %v", err)
+		}
+	}()
+	var res []storage.ServerInfo
+	for rows.Next() {
+		var info storage.ServerInfo
+		if err := rows.Scan(&info.ServerID, &info.InstanceTag, &info.Heartbeat); err != nil {
+			return nil, fmt.Errorf("could not read server row: %w", err)
+		}
+		res = append(res, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	return res, nil
+}
+
+// AcquireServerLease grants holder the exclusive right to operate on the jobs
+// of serverID until expiresAt, unless another holder has a valid lease.
+func (r *RDBMS) AcquireServerLease(_ xcontext.Context, serverID, holder string, now, expiresAt time.Time) (bool, error) {
+	r.lockTx()
+	defer r.unlockTx()
+
+	// The update is atomic, but it may affect no rows both when the lease is
+	// held by somebody else and when it is renewed with the same values,
+	// so the actual holder is read back.
+	if _, err := r.exec(
+		safesql.New("update servers set lease_holder = ?, lease_expires_at = ? where server_id = ? and (lease_holder is null or lease_holder = ? or lease_expires_at < ?)"),
+		holder, expiresAt, serverID, holder, now); err != nil {
+		return false, fmt.Errorf("could not acquire lease for server %q: %w", serverID, err)
+	}
+	rows, err := r.query(safesql.New("select lease_holder from servers where server_id = ?"), serverID)
+	if err != nil {
+		return false, fmt.Errorf("could not read lease for server %q: %w", serverID, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return false, fmt.Errorf("could not read lease for server %q: %w", serverID, err)
+		}
+		return false, fmt.Errorf("unknown server %q", serverID)
+	}
+	var leaseHolder sql.NullString
+	if err := rows.Scan(&leaseHolder); err != nil {
+		return false, fmt.Errorf("could not read lease for server %q: %w", serverID, err)
+	}
+	return leaseHolder.Valid && leaseHolder.String == holder, nil
+}
+
+// RemoveDeadServer forgets serverID once holder took over its jobs, unless it
+// reported itself as alive since deadBefore or the lease changed hands.
+func (r *RDBMS) RemoveDeadServer(_ xcontext.Context, serverID, holder string, deadBefore time.Time) error {
+	r.lockTx()
+	defer r.unlockTx()
+
+	if _, err := r.exec(
+		safesql.New("delete from servers where server_id = ? and lease_holder = ? and heartbeat_time < ?"),
+		serverID, holder, deadBefore); err != nil {
+		return fmt.Errorf("could not remove server %q: %w", serverID, err)
+	}
+	return nil
+}
+
+// UpdateJobServerID assigns the job to a different server.
+func (r *RDBMS) UpdateJobServerID(_ xcontext.Context, jobID types.JobID, serverID string) error {
+	r.lockTx()
+	defer r.unlockTx()
+
+	if _, err := r.exec(safesql.New("update jobs set server_id = ? where job_id = ?"), serverID, jobID); err != nil {
+		return fmt.Errorf("could not update server of job %d: %w", jobID, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// eventQuery accumulates the conditions of an event query.
+type eventQuery struct {
+	conds  []string
+	fields []interface{}
+}
+
+func (q *eventQuery) add(cond string, fields ...interface{}) {
+	q.conds = append(q.conds, cond)
+	q.fields = append(q.fields, fields...)
+}
+
+func (q *eventQuery) addCommon(eq *event.Query) {
+	if eq.JobID != 0 {
+		q.add("job_id = ?", eq.JobID)
+	}
+	if len(eq.EventNames) != 0 {
+		placeholders := make([]string, len(eq.EventNames))
+		names := make([]interface{}, len(eq.EventNames))
+		for i, name := range eq.EventNames {
+			placeholders[i] = "?"
+			names[i] = string(name)
+		}
+		q.add("event_name IN ("+strings.Join(placeholders, ", ")+")", names...)
+	}
+	if !eq.EmittedStartTime.IsZero() {
+		q.add("emit_time >= ?", eq.EmittedStartTime.UTC())
+	}
+	if !eq.EmittedEndTime.IsZero() {
+		q.add("emit_time <= ?", eq.EmittedEndTime.UTC())
+	}
+	if eq.SequenceIDAfter != 0 {
+		q.add("event_id > ?", eq.SequenceIDAfter)
+	}
+}
+
+// statement returns the query statement, which must have at least one
+// condition as the whole table is never returned.
+func (q *eventQuery) statement(baseQuery string, eq *event.Query) (string, error) {
+	if len(q.conds) == 0 {
+		return "", fmt.Errorf("no select clauses available, the query should specify at least one clause")
+	}
+	stmt := baseQuery + " where " + strings.Join(q.conds, " and ") + " order by event_id"
+	if eq.Order == event.OrderDescending {
+		stmt += " desc"
+	}
+	if eq.Limit > 0 {
+		stmt += fmt.Sprintf(" limit %d", eq.Limit)
+	}
+	return stmt, nil
+}
+
+// StoreTestEvent stores a test event in the database.
+func (s *SQLite) StoreTestEvent(_ xcontext.Context, ev testevent.Event) error {
+	if ev.Header == nil || ev.Data == nil {
+		return fmt.Errorf("test event must have both header and data")
+	}
+	var targetID interface{}
+	if ev.Data.Target != nil {
+		targetID = ev.Data.Target.ID
+	}
+	if _, err := s.db.Exec(
+		"insert into test_events (job_id, run_id, test_name, test_attempt, test_step_label, event_name, target_id, payload, emit_time) values (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		ev.Header.JobID,
+		ev.Header.RunID,
+		ev.Header.TestName,
+		ev.Header.TestAttempt,
+		ev.Header.TestStepLabel,
+		string(ev.Data.EventName),
+		targetID,
+		payloadValue(ev.Data.Payload),
+		ev.EmitTime.UTC(),
+	); err != nil {
+		return fmt.Errorf("could not store event in database: %w", err)
+	}
+	return nil
+}
+
+// GetTestEvents retrieves test events matching the query fields provided
+func (s *SQLite) GetTestEvents(ctx xcontext.Context, testEventQuery *testevent.Query) ([]testevent.Event, error) {
+	if testEventQuery == nil {
+		return nil, fmt.Errorf("cannot build empty testevent query")
+	}
+	var q eventQuery
+	q.addCommon(&testEventQuery.Query)
+	if testEventQuery.RunID != types.RunID(0) {
+		q.add("run_id = ?", testEventQuery.RunID)
+	}
+	if testEventQuery.TestName != "" {
+		q.add("test_name = ?", testEventQuery.TestName)
+	}
+	if testEventQuery.TestStepLabel != "" {
+		q.add("test_step_label = ?", testEventQuery.TestStepLabel)
+	}
+	query, err := q.statement("select event_id, job_id, run_id, test_name, test_attempt, test_step_label, event_name, target_id, payload, emit_time from test_events", &testEventQuery.Query)
+	if err != nil {
+		return nil, fmt.Errorf("could not build query for test events: %w", err)
+	}
+
+	ctx.Debugf("Executing query: %s, fields: %v", query, q.fields)
+	rows, err := s.db.Query(query, q.fields...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var results []testevent.Event
+	for rows.Next() {
+		data := testevent.Data{}
+		header := testevent.Header{}
+		ev := testevent.New(&header, &data)
+
+		// Test name, step label, target ID and payload might be null.
+		var testName, testStepLabel, targetID, payload sql.NullString
+		err := rows.Scan(
+			&ev.SequenceID,
+			&header.JobID,
+			&header.RunID,
+			&testName,
+			&header.TestAttempt,
+			&testStepLabel,
+			&data.EventName,
+			&targetID,
+			&payload,
+			&ev.EmitTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not read results from db: %w", err)
+		}
+		header.TestName = testName.String
+		header.TestStepLabel = testStepLabel.String
+		if targetID.Valid {
+			data.Target = &target.Target{ID: targetID.String}
+		}
+		data.Payload = payloadFromColumn(payload)
+		results = append(results, ev)
+	}
+	return results, rows.Err()
+}
+
+// StoreFrameworkEvent stores a framework event in the database and, if the
+// event represents a job state transition, updates the state of the job.
+func (s *SQLite) StoreFrameworkEvent(_ xcontext.Context, ev frameworkevent.Event) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.Exec(
+		"insert into framework_events (job_id, event_name, payload, emit_time) values (?, ?, ?, ?)",
+		ev.JobID, string(ev.EventName), payloadValue(ev.Payload), ev.EmitTime.UTC(),
+	); err != nil {
+		return fmt.Errorf("could not store event in database: %w", err)
+	}
+	if state, err := job.EventNameToJobState(ev.EventName); err == nil {
+		if _, err := tx.Exec("update jobs set state = ? where job_id = ?", state, ev.JobID); err != nil {
+			return fmt.Errorf("could not update state of job %d: %w", ev.JobID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not store event in database: %w", err)
+	}
+	return nil
+}
+
+// GetFrameworkEvent retrieves framework events matching the query fields provided
+func (s *SQLite) GetFrameworkEvent(ctx xcontext.Context, frameworkEventQuery *frameworkevent.Query) ([]frameworkevent.Event, error) {
+	if frameworkEventQuery == nil {
+		return nil, fmt.Errorf("cannot build empty frameworkevent query")
+	}
+	var q eventQuery
+	q.addCommon(&frameworkEventQuery.Query)
+	query, err := q.statement("select event_id, job_id, event_name, payload, emit_time from framework_events", &frameworkEventQuery.Query)
+	if err != nil {
+		return nil, fmt.Errorf("could not build query for framework events: %w", err)
+	}
+
+	ctx.Debugf("Executing query: %s, fields: %v", query, q.fields)
+	rows, err := s.db.Query(query, q.fields...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	results := []frameworkevent.Event{}
+	for rows.Next() {
+		ev := frameworkevent.New()
+		var (
+			eventName sql.NullString
+			payload   sql.NullString
+		)
+		if err := rows.Scan(&ev.SequenceID, &ev.JobID, &eventName, &payload, &ev.EmitTime); err != nil {
+			return nil, fmt.Errorf("could not read results from db: %w", err)
+		}
+		ev.EventName = event.Name(eventName.String)
+		ev.Payload = payloadFromColumn(payload)
+		results = append(results, ev)
+	}
+	return results, rows.Err()
+}
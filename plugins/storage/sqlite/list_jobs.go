@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// ListJobs returns the IDs of the jobs matching the query, in ascending order.
+func (s *SQLite) ListJobs(_ xcontext.Context, query *storage.JobQuery) ([]types.JobID, error) {
+	if err := job.CheckTags(query.Tags, true /* allowInternal */); err != nil {
+		return nil, err
+	}
+
+	// Tag filtering uses joins, 1 per tag. Only placeholders and indices are
+	// interpolated in the statement, values are always bound.
+	parts := []string{"SELECT jobs.job_id FROM jobs"}
+	var (
+		conds []string
+		qargs []interface{}
+	)
+	for i := range query.Tags {
+		parts = append(parts, fmt.Sprintf("INNER JOIN job_tags jt%d ON jobs.job_id = jt%d.job_id", i, i))
+	}
+	if len(query.ServerID) > 0 {
+		conds = append(conds, "jobs.server_id = ?")
+		qargs = append(qargs, query.ServerID)
+	}
+	if len(query.States) > 0 {
+		placeholders := make([]string, len(query.States))
+		for i, st := range query.States {
+			placeholders[i] = "?"
+			qargs = append(qargs, st)
+		}
+		conds = append(conds, "jobs.state IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if !query.RequestedBefore.IsZero() {
+		conds = append(conds, "jobs.request_time < ?")
+		qargs = append(qargs, query.RequestedBefore.UTC())
+	}
+	for i, tag := range query.Tags {
+		conds = append(conds, fmt.Sprintf("jt%d.tag = ?", i))
+		qargs = append(qargs, tag)
+	}
+	if len(conds) > 0 {
+		parts = append(parts, "WHERE", strings.Join(conds, " AND "))
+	}
+	parts = append(parts, "ORDER BY jobs.job_id")
+	stmt := strings.Join(parts, " ")
+
+	rows, err := s.db.Query(stmt, qargs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list jobs (sql: %q): %w", stmt, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := []types.JobID{}
+	for rows.Next() {
+		var jobID types.JobID
+		if err := rows.Scan(&jobID); err != nil {
+			return nil, fmt.Errorf("could not list jobs (sql: %q): %w", stmt, err)
+		}
+		res = append(res, jobID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list jobs (sql: %q): %w", stmt, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// PurgeJob deletes the request, tags, reports, test events and framework
+// events of a job.
+func (s *SQLite) PurgeJob(_ xcontext.Context, jobID types.JobID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, table := range []string{"test_events", "framework_events", "run_reports", "final_reports", "job_tags", "jobs"} {
+		if _, err := tx.Exec("delete from "+table+" where job_id = ?", jobID); err != nil {
+			return fmt.Errorf("could not purge %s of job %d: %w", table, jobID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not purge job %d: %w", jobID, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// StoreReport persists a run or final report in the internal storage.
+func (s *SQLite) StoreReport(_ xcontext.Context, report *job.Report) error {
+	reportJSON, err := report.ToJSON()
+	if err != nil {
+		return fmt.Errorf("could not serialize final report for job %v: %w", report.JobID, err)
+	}
+	if report.RunID > 0 {
+		if _, err := s.db.Exec(
+			"insert into run_reports (job_id, run_id, reporter_name, success, report_time, data) values (?, ?, ?, ?, ?, ?)",
+			report.JobID, report.RunID, report.ReporterName, report.Success, report.ReportTime.UTC(), string(reportJSON)); err != nil {
+			return fmt.Errorf("could not store run report for job %v: %w", report.JobID, err)
+		}
+	} else {
+		if _, err := s.db.Exec(
+			"insert into final_reports (job_id, reporter_name, success, report_time, data) values (?, ?, ?, ?, ?)",
+			report.JobID, report.ReporterName, report.Success, report.ReportTime.UTC(), string(reportJSON)); err != nil {
+			return fmt.Errorf("could not store final report for job %v: %w", report.JobID, err)
+		}
+	}
+	return nil
+}
+
+type reportRow struct {
+	report job.Report
+	runID  uint
+}
+
+// queryReports returns the reports matching the query, with the run ID as
+// the first column if withRunID is set.
+func (s *SQLite) queryReports(query string, withRunID bool, jobID types.JobID) ([]reportRow, error) {
+	rows, err := s.db.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []reportRow
+	for rows.Next() {
+		var (
+			row  reportRow
+			data string
+		)
+		dest := []interface{}{&row.report.Success, &row.report.ReportTime, &row.report.ReporterName, &data}
+		if withRunID {
+			dest = append([]interface{}{&row.runID}, dest...)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &row.report.Data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal report JSON data: %w", err)
+		}
+		// These struct fields are not serialized in data, populate them from columns.
+		row.report.JobID = jobID
+		row.report.RunID = types.RunID(row.runID)
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
+
+// GetJobReport retrieves a JobReport from the database
+func (s *SQLite) GetJobReport(_ xcontext.Context, jobID types.JobID) (*job.JobReport, error) {
+	// Don't change the order by asc, because the code below assumes sorted
+	// results by ascending run number.
+	runRows, err := s.queryReports(
+		"select run_id, success, report_time, reporter_name, data from run_reports where job_id = ? order by run_id asc, reporter_name asc",
+		true, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get run reports for job %v: %w", jobID, err)
+	}
+	var (
+		runReports        [][]*job.Report
+		currentRunReports []*job.Report
+		lastRunID         uint
+	)
+	for i := range runRows {
+		currentRunID := runRows[i].runID
+		// rows are sorted by ascending run_id, so if we find a
+		// non-monotonic run_id or a gap, we return an error.
+		if currentRunID == 0 {
+			return nil, errors.New("invalid run_id in database, cannot be zero")
+		}
+		if currentRunID < lastRunID || currentRunID > lastRunID+1 {
+			return nil, fmt.Errorf("invalid run_id retrieved from database: either it is not ordered, or there is a gap in run numbers in the database for job %d. Current run number: %d, last run number: %d",
+				jobID, currentRunID, lastRunID,
+			)
+		}
+		if currentRunID != lastRunID {
+			// this is the next run number
+			if lastRunID > 0 {
+				runReports = append(runReports, currentRunReports)
+				currentRunReports = nil
+			}
+			lastRunID = currentRunID
+		}
+		currentRunReports = append(currentRunReports, &runRows[i].report)
+	}
+	if len(currentRunReports) > 0 {
+		runReports = append(runReports, currentRunReports)
+	}
+
+	finalRows, err := s.queryReports(
+		"select success, report_time, reporter_name, data from final_reports where job_id = ? order by reporter_name asc",
+		false, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get final reports for job %v: %w", jobID, err)
+	}
+	var finalReports []*job.Report
+	for i := range finalRows {
+		finalReports = append(finalReports, &finalRows[i].report)
+	}
+	return &job.JobReport{
+		JobID:        jobID,
+		RunReports:   runReports,
+		FinalReports: finalReports,
+	}, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+const (
+	insertJobStmt    = "insert into jobs (name, descriptor, extended_descriptor, requestor, server_id, request_time) values (?, ?, ?, ?, ?, ?)"
+	insertJobTagStmt = "insert into job_tags (job_id, tag) values (?, ?)"
+)
+
+// StoreJobRequest stores a new job request in the database
+func (s *SQLite) StoreJobRequest(_ xcontext.Context, request *job.Request) (types.JobID, error) {
+	// Extract job tags for insertion.
+	var desc job.Descriptor
+	if err := json.Unmarshal([]byte(request.JobDescriptor), &desc); err != nil {
+		return 0, fmt.Errorf("invalid job descriptor: %w", err)
+	}
+	if err := job.CheckTags(desc.Tags, true /* allowInternal */); err != nil {
+		return 0, err
+	}
+
+	// serialize the extended descriptor
+	extendedDescriptor, err := json.Marshal(request.ExtendedDescriptor)
+	if err != nil {
+		return 0, fmt.Errorf("could not serialize extended job descriptor")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.Exec(insertJobStmt, request.JobName, request.JobDescriptor, string(extendedDescriptor), request.Requestor, request.ServerID, request.RequestTime.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("could not store job request in database: %w", err)
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("could not extract id of last request inserted into db")
+	}
+	jobID := types.JobID(lastID)
+
+	for _, tag := range desc.Tags {
+		if _, err := tx.Exec(insertJobTagStmt, jobID, tag); err != nil {
+			return 0, fmt.Errorf("could not store job tag in the database: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("could not store job request in database: %w", err)
+	}
+	return jobID, nil
+}
+
+// GetJobRequest retrieves a JobRequest from the database
+func (s *SQLite) GetJobRequest(_ xcontext.Context, jobID types.JobID) (*job.Request, error) {
+	var (
+		req                    job.Request
+		extendedDescriptorJSON sql.NullString
+	)
+	err := s.db.QueryRow(
+		"select job_id, name, requestor, server_id, request_time, descriptor, extended_descriptor from jobs where job_id = ?",
+		jobID,
+	).Scan(
+		&req.JobID,
+		&req.JobName,
+		&req.Requestor,
+		&req.ServerID,
+		&req.RequestTime,
+		&req.JobDescriptor,
+		&extendedDescriptorJSON,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("could not find request with JobID %d", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get job request with id %v: %w", jobID, err)
+	}
+
+	extendedDescriptor := job.ExtendedDescriptor{}
+	if err := json.Unmarshal([]byte(extendedDescriptorJSON.String), &extendedDescriptor); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal extended job descriptor: %w", err)
+	}
+	req.ExtendedDescriptor = &extendedDescriptor
+	return &req, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// StoreServerHeartbeat records the server as alive as of info.Heartbeat.
+func (s *SQLite) StoreServerHeartbeat(_ xcontext.Context, info storage.ServerInfo) error {
+	if _, err := s.db.Exec(
+		"insert into servers (server_id, instance_tag, heartbeat_time) values (?, ?, ?) on conflict (server_id) do update set instance_tag = excluded.instance_tag, heartbeat_time = excluded.heartbeat_time",
+		info.ServerID, info.InstanceTag, info.Heartbeat.UTC()); err != nil {
+		return fmt.Errorf("could not store heartbeat for server %q: %w", info.ServerID, err)
+	}
+	return nil
+}
+
+// ListServers returns all the known servers with the given instance tag.
+func (s *SQLite) ListServers(_ xcontext.Context, instanceTag string) ([]storage.ServerInfo, error) {
+	rows, err := s.db.Query(
+		"select server_id, instance_tag, heartbeat_time from servers where instance_tag = ? order by server_id",
+		instanceTag)
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res []storage.ServerInfo
+	for rows.Next() {
+		var info storage.ServerInfo
+		if err := rows.Scan(&info.ServerID, &info.InstanceTag, &info.Heartbeat); err != nil {
+			return nil, fmt.Errorf("could not read server row: %w", err)
+		}
+		res = append(res, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	return res, nil
+}
+
+// AcquireServerLease grants holder the exclusive right to operate on the jobs
+// of serverID until expiresAt, unless another holder has a valid lease.
+func (s *SQLite) AcquireServerLease(_ xcontext.Context, serverID, holder string, now, expiresAt time.Time) (bool, error) {
+	if _, err := s.db.Exec(
+		"update servers set lease_holder = ?, lease_expires_at = ? where server_id = ? and (lease_holder is null or lease_holder = ? or lease_expires_at < ?)",
+		holder, expiresAt.UTC(), serverID, holder, now.UTC()); err != nil {
+		return false, fmt.Errorf("could not acquire lease for server %q: %w", serverID, err)
+	}
+	var leaseHolder sql.NullString
+	err := s.db.QueryRow("select lease_holder from servers where server_id = ?", serverID).Scan(&leaseHolder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("unknown server %q", serverID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read lease for server %q: %w", serverID, err)
+	}
+	return leaseHolder.Valid && leaseHolder.String == holder, nil
+}
+
+// RemoveDeadServer forgets serverID once holder took over its jobs, unless it
+// reported itself as alive since deadBefore or the lease changed hands.
+func (s *SQLite) RemoveDeadServer(_ xcontext.Context, serverID, holder string, deadBefore time.Time) error {
+	if _, err := s.db.Exec(
+		"delete from servers where server_id = ? and lease_holder = ? and heartbeat_time < ?",
+		serverID, holder, deadBefore.UTC()); err != nil {
+		return fmt.Errorf("could not remove server %q: %w", serverID, err)
+	}
+	return nil
+}
+
+// UpdateJobServerID assigns the job to a different server.
+func (s *SQLite) UpdateJobServerID(_ xcontext.Context, jobID types.JobID, serverID string) error {
+	if _, err := s.db.Exec("update jobs set server_id = ? where job_id = ?", serverID, jobID); err != nil {
+		return fmt.Errorf("could not update server of job %d: %w", jobID, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package sqlite implements a storage engine which stores ConTest information
+// in a local SQLite database file. It requires no setup and is meant for
+// single-server deployments, e.g. small labs and development machines.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linuxboot/contest/db/sqlite/migration"
+	"github.com/linuxboot/contest/pkg/storage"
+
+	// this blank import registers the sqlite driver, which is pure Go so that
+	// the engine also works in binaries built with CGO_ENABLED=0
+	_ "modernc.org/sqlite"
+)
+
+// URIScheme is the prefix of database URIs handled by this engine, e.g.
+// sqlite:///var/lib/contest/contest.db
+const URIScheme = "sqlite://"
+
+// busyTimeout is how long a connection waits for a lock held by another
+// process accessing the same database file.
+const busyTimeout = 5 * time.Second
+
+// IsURI returns whether dbURI refers to an SQLite database.
+func IsURI(dbURI string) bool {
+	return strings.HasPrefix(dbURI, URIScheme)
+}
+
+// SQLite implements storage.Storage on top of an SQLite database.
+//
+// A single connection is used, as SQLite only allows one writer at a time
+// anyway. This serializes all the operations, so result rows must always be
+// consumed and closed before issuing another query.
+type SQLite struct {
+	db *sql.DB
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Version returns the current version of the database schema.
+func (s *SQLite) Version() (uint64, error) {
+	return migration.Version(s.db)
+}
+
+// Reset wipes entire database contents. Used in tests.
+func (s *SQLite) Reset() error {
+	for _, t := range []string{
+		"jobs",
+		"job_tags",
+		"run_reports",
+		"final_reports",
+		"test_events",
+		"framework_events",
+		"servers",
+	} {
+		if _, err := s.db.Exec("DELETE FROM " + t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dsn converts a sqlite:// URI into a data source name for the driver.
+// Everything after the scheme is the path of the database file, so
+// sqlite:///tmp/contest.db is an absolute path and sqlite://contest.db is
+// relative to the working directory.
+func dsn(dbURI string) (string, error) {
+	if !IsURI(dbURI) {
+		return "", fmt.Errorf("invalid database URI %q, must start with %q", dbURI, URIScheme)
+	}
+	path := strings.TrimPrefix(dbURI, URIScheme)
+	params := ""
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path, params = path[:idx], path[idx+1:]
+	}
+	if path == "" {
+		return "", fmt.Errorf("invalid database URI %q, no path specified", dbURI)
+	}
+	driverParams := fmt.Sprintf("_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", busyTimeout.Milliseconds())
+	if params != "" {
+		driverParams += "&" + params
+	}
+	return "file:" + path + "?" + driverParams, nil
+}
+
+// New opens the SQLite database at dbURI, creating it if it does not exist,
+// and brings its schema up to date.
+func New(dbURI string) (storage.Storage, error) {
+	dataSource, err := dsn(dbURI)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dataSource)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+	if err := migration.Up(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not migrate database: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// payloadValue converts an event payload into a value for a TEXT column. An
+// empty payload is stored as NULL, since it is not valid JSON.
+func payloadValue(payload *json.RawMessage) interface{} {
+	if payload == nil || len(*payload) == 0 {
+		return nil
+	}
+	return string(*payload)
+}
+
+// payloadFromColumn converts the value of a payload column back into a payload.
+func payloadFromColumn(payload sql.NullString) *json.RawMessage {
+	if !payload.Valid || payload.String == "" {
+		return nil
+	}
+	rawPayload := json.RawMessage(payload.String)
+	return &rawPayload
+}
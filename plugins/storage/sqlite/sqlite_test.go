@@ -0,0 +1,279 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/frameworkevent"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/job"
+	"github.com/linuxboot/contest/pkg/storage"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
+	"github.com/linuxboot/contest/pkg/xcontext/logger"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctx, _ = logrusctx.NewContext(logger.LevelDebug)
+)
+
+func newStorage(t *testing.T) storage.Storage {
+	s, err := New(URIScheme + filepath.Join(t.TempDir(), "contest.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func storeJob(t *testing.T, s storage.Storage, tags ...string) types.JobID {
+	return storeJobAt(t, s, time.Now(), tags...)
+}
+
+func storeJobAt(t *testing.T, s storage.Storage, requestTime time.Time, tags ...string) types.JobID {
+	desc, err := json.Marshal(job.Descriptor{JobName: "test", Tags: tags})
+	require.NoError(t, err)
+	jobID, err := s.StoreJobRequest(ctx, &job.Request{
+		JobName:            "test",
+		JobDescriptor:      string(desc),
+		ExtendedDescriptor: &job.ExtendedDescriptor{},
+		Requestor:          "tester",
+		ServerID:           "server",
+		RequestTime:        requestTime,
+	})
+	require.NoError(t, err)
+	return jobID
+}
+
+func TestDSN(t *testing.T) {
+	d, err := dsn("sqlite:///var/lib/contest.db")
+	require.NoError(t, err)
+	require.Equal(t, "file:/var/lib/contest.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", d)
+
+	d, err = dsn("sqlite://contest.db?cache=shared")
+	require.NoError(t, err)
+	require.Equal(t, "file:contest.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&cache=shared", d)
+
+	_, err = dsn("sqlite://")
+	require.Error(t, err)
+	_, err = dsn("contest:contest@tcp(localhost:3306)/contest")
+	require.Error(t, err)
+}
+
+func TestReopenKeepsData(t *testing.T) {
+	uri := URIScheme + filepath.Join(t.TempDir(), "contest.db")
+	s, err := New(uri)
+	require.NoError(t, err)
+	jobID := storeJob(t, s)
+	require.NoError(t, s.Close())
+
+	s, err = New(uri)
+	require.NoError(t, err)
+	defer s.Close()
+	ver, err := s.Version()
+	require.NoError(t, err)
+	require.NotZero(t, ver)
+	req, err := s.GetJobRequest(ctx, jobID)
+	require.NoError(t, err)
+	require.Equal(t, "tester", req.Requestor)
+	require.NotNil(t, req.ExtendedDescriptor)
+}
+
+func TestListJobs(t *testing.T) {
+	s := newStorage(t)
+	job1 := storeJob(t, s, "foo")
+	job2 := storeJob(t, s, "foo", "bar")
+	job3 := storeJob(t, s)
+
+	require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: job2, EventName: job.EventJobStarted, EmitTime: time.Now()}))
+	require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: job3, EventName: job.EventJobStarted, EmitTime: time.Now()}))
+	require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: job3, EventName: job.EventJobCompleted, EmitTime: time.Now()}))
+
+	for _, tc := range []struct {
+		query storage.JobQuery
+		exp   []types.JobID
+	}{
+		{storage.JobQuery{}, []types.JobID{job1, job2, job3}},
+		{storage.JobQuery{States: []job.State{job.JobStateUnknown}}, []types.JobID{job1}},
+		{storage.JobQuery{States: []job.State{job.JobStateStarted, job.JobStateCompleted}}, []types.JobID{job2, job3}},
+		{storage.JobQuery{Tags: []string{"foo"}}, []types.JobID{job1, job2}},
+		{storage.JobQuery{Tags: []string{"foo", "bar"}}, []types.JobID{job2}},
+		{storage.JobQuery{Tags: []string{"foo"}, States: []job.State{job.JobStateCompleted}}, []types.JobID{}},
+		{storage.JobQuery{ServerID: "other"}, []types.JobID{}},
+	} {
+		res, err := s.ListJobs(ctx, &tc.query)
+		require.NoError(t, err)
+		require.Equal(t, tc.exp, res, "%+v", tc.query)
+	}
+	_, err := s.ListJobs(ctx, &storage.JobQuery{Tags: []string{"bad tag"}})
+	require.Error(t, err)
+}
+
+func TestJobReport(t *testing.T) {
+	s := newStorage(t)
+	jobID := storeJob(t, s)
+	now := time.Now()
+	for _, r := range []*job.Report{
+		{JobID: jobID, RunID: 1, ReporterName: "b", ReportTime: now, Success: true, Data: "run1b"},
+		{JobID: jobID, RunID: 1, ReporterName: "a", ReportTime: now, Success: false, Data: "run1a"},
+		{JobID: jobID, RunID: 2, ReporterName: "a", ReportTime: now, Success: true, Data: "run2a"},
+		{JobID: jobID, ReporterName: "final", ReportTime: now, Success: true, Data: "final"},
+	} {
+		require.NoError(t, s.StoreReport(ctx, r))
+	}
+	report, err := s.GetJobReport(ctx, jobID)
+	require.NoError(t, err)
+	require.Len(t, report.RunReports, 2)
+	require.Len(t, report.RunReports[0], 2)
+	require.Equal(t, "run1a", report.RunReports[0][0].Data)
+	require.False(t, report.RunReports[0][0].Success)
+	require.Equal(t, types.RunID(2), report.RunReports[1][0].RunID)
+	require.Len(t, report.FinalReports, 1)
+	require.Equal(t, "final", report.FinalReports[0].Data)
+	require.True(t, report.FinalReports[0].ReportTime.Equal(now))
+}
+
+func TestTestEvents(t *testing.T) {
+	s := newStorage(t)
+	payload := json.RawMessage(`{"a":1}`)
+	emitTime := time.Now()
+	for _, ev := range []testevent.Event{
+		{
+			EmitTime: emitTime,
+			Header:   &testevent.Header{JobID: 1, RunID: 1, TestName: "t", TestAttempt: 0, TestStepLabel: "s1"},
+			Data:     &testevent.Data{EventName: "start", Target: &target.Target{ID: "T1"}, Payload: &payload},
+		},
+		{
+			EmitTime: emitTime.Add(time.Second),
+			Header:   &testevent.Header{JobID: 1, RunID: 1, TestName: "t", TestAttempt: 1, TestStepLabel: "s2"},
+			Data:     &testevent.Data{EventName: "end"},
+		},
+		{
+			EmitTime: emitTime,
+			Header:   &testevent.Header{JobID: 2, RunID: 1, TestName: "t"},
+			Data:     &testevent.Data{EventName: "start"},
+		},
+	} {
+		require.NoError(t, s.StoreTestEvent(ctx, ev))
+	}
+
+	_, err := s.GetTestEvents(ctx, &testevent.Query{})
+	require.Error(t, err)
+
+	events, err := s.GetTestEvents(ctx, &testevent.Query{Query: event.Query{JobID: 1}})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "T1", events[0].Data.Target.ID)
+	require.JSONEq(t, string(payload), string(*events[0].Data.Payload))
+	require.True(t, events[0].EmitTime.Equal(emitTime))
+	require.Nil(t, events[1].Data.Target)
+	require.Nil(t, events[1].Data.Payload)
+	require.Equal(t, uint32(1), events[1].Header.TestAttempt)
+	require.Less(t, events[0].SequenceID, events[1].SequenceID)
+
+	events, err = s.GetTestEvents(ctx, &testevent.Query{Query: event.Query{EventNames: []event.Name{"start"}}})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	events, err = s.GetTestEvents(ctx, &testevent.Query{Query: event.Query{EmittedStartTime: emitTime.Add(time.Millisecond)}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "s2", events[0].Header.TestStepLabel)
+
+	events, err = s.GetTestEvents(ctx, &testevent.Query{Query: event.Query{JobID: 1}, TestStepLabel: "s1"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+}
+
+func TestFrameworkEvents(t *testing.T) {
+	s := newStorage(t)
+	jobID := storeJob(t, s)
+	payload := json.RawMessage(`"paused"`)
+	require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: jobID, EventName: job.EventJobStarted, EmitTime: time.Now()}))
+	require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: jobID, EventName: job.EventJobPaused, Payload: &payload, EmitTime: time.Now()}))
+
+	events, err := s.GetFrameworkEvent(ctx, &frameworkevent.Query{Query: event.Query{JobID: jobID, EventNames: []event.Name{job.EventJobPaused}}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.JSONEq(t, string(payload), string(*events[0].Payload))
+
+	jobs, err := s.ListJobs(ctx, &storage.JobQuery{States: []job.State{job.JobStatePaused}})
+	require.NoError(t, err)
+	require.Equal(t, []types.JobID{jobID}, jobs)
+}
+
+func TestServerLease(t *testing.T) {
+	s := newStorage(t).(*SQLite)
+	now := time.Now()
+	require.NoError(t, s.StoreServerHeartbeat(ctx, storage.ServerInfo{ServerID: "a", InstanceTag: "tag", Heartbeat: now}))
+	require.NoError(t, s.StoreServerHeartbeat(ctx, storage.ServerInfo{ServerID: "b", InstanceTag: "tag", Heartbeat: now}))
+
+	servers, err := s.ListServers(ctx, "tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.True(t, servers[0].Heartbeat.Equal(now))
+
+	acquired, err := s.AcquireServerLease(ctx, "a", "b", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = s.AcquireServerLease(ctx, "a", "c", now.Add(time.Second), now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, acquired)
+	acquired, err = s.AcquireServerLease(ctx, "a", "c", now.Add(2*time.Minute), now.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.True(t, acquired)
+	_, err = s.AcquireServerLease(ctx, "unknown", "c", now, now)
+	require.Error(t, err)
+
+	require.NoError(t, s.RemoveDeadServer(ctx, "a", "b", now.Add(time.Minute)))
+	require.NoError(t, s.RemoveDeadServer(ctx, "a", "c", now))
+	servers, err = s.ListServers(ctx, "tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	require.NoError(t, s.RemoveDeadServer(ctx, "a", "c", now.Add(time.Minute)))
+	servers, err = s.ListServers(ctx, "tag")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	require.Equal(t, "b", servers[0].ServerID)
+}
+
+func TestPurgeJob(t *testing.T) {
+	s := newStorage(t)
+	now := time.Now()
+	oldJob := storeJobAt(t, s, now.Add(-time.Hour), "foo")
+	newJob := storeJobAt(t, s, now, "foo")
+	for _, jobID := range []types.JobID{oldJob, newJob} {
+		require.NoError(t, s.StoreFrameworkEvent(ctx, frameworkevent.Event{JobID: jobID, EventName: job.EventJobStarted, EmitTime: now}))
+		require.NoError(t, s.StoreTestEvent(ctx, testevent.Event{EmitTime: now, Header: &testevent.Header{JobID: jobID, RunID: 1, TestName: "t"}, Data: &testevent.Data{EventName: "start"}}))
+		require.NoError(t, s.StoreReport(ctx, &job.Report{JobID: jobID, ReporterName: "final", ReportTime: now, Data: "final"}))
+	}
+
+	jobs, err := s.ListJobs(ctx, &storage.JobQuery{Tags: []string{"foo"}, RequestedBefore: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, []types.JobID{oldJob}, jobs)
+
+	require.NoError(t, s.(storage.PurgeStorage).PurgeJob(ctx, oldJob))
+	_, err = s.GetJobRequest(ctx, oldJob)
+	require.Error(t, err)
+	report, err := s.GetJobReport(ctx, oldJob)
+	require.NoError(t, err)
+	require.Empty(t, report.FinalReports)
+	events, err := s.GetTestEvents(ctx, &testevent.Query{Query: event.Query{EventNames: []event.Name{"start"}}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, newJob, events[0].Header.JobID)
+	jobs, err = s.ListJobs(ctx, &storage.JobQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []types.JobID{newJob}, jobs)
+
+	// A new job does not reuse the ID of the purged one.
+	require.Greater(t, storeJob(t, s), newJob)
+}
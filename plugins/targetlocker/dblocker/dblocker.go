@@ -8,13 +8,13 @@ package dblocker
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
-	// this blank import registers the mysql driver
-	_ "github.com/go-sql-driver/mysql"
 
+	"github.com/linuxboot/contest/db/rdbms/dialect"
 	"github.com/linuxboot/contest/pkg/target"
 	"github.com/linuxboot/contest/pkg/types"
 	"github.com/linuxboot/contest/pkg/xcontext"
@@ -74,10 +74,11 @@ func listQueryString(length uint) string {
 }
 
 // DBLocker implements a simple target locker based on a relational database.
-// The current implementation only supports MySQL officially.
+// MySQL and PostgreSQL are supported, the dialect is inferred from the database URI.
 // All functions in DBLocker are safe for concurrent use by multiple goroutines.
 type DBLocker struct {
 	driverName   string
+	dialect      dialect.Dialect
 	db           *sql.DB
 	maxBatchSize int
 	// clock is used for measuring time
@@ -93,7 +94,7 @@ func (d *DBLocker) queryLocks(tx db, targets []string) (map[string]dblock, error
 		queryList = append(queryList, targetID)
 	}
 
-	rows, err := tx.Query(q, queryList...)
+	rows, err := tx.Query(d.dialect.Rebind(q), queryList...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read existing locks: %w", err)
 	}
@@ -187,7 +188,7 @@ func (d *DBLocker) handleLock(ctx xcontext.Context, jobID int64, targets []strin
 			if len(stmt) < d.maxBatchSize && i < len(toDelete)-1 {
 				continue
 			}
-			if _, err := tx.Exec(strings.Join(stmt, ""), args...); err != nil {
+			if _, err := tx.Exec(d.dialect.Rebind(strings.Join(stmt, "")), args...); err != nil {
 				return nil, fmt.Errorf("insert statement failed: %w", err)
 			}
 			stmt = nil
@@ -208,7 +209,7 @@ func (d *DBLocker) handleLock(ctx xcontext.Context, jobID int64, targets []strin
 			if len(stmt) == 0 {
 				// this can race with other transactions, acceptable for TryLock
 				if allowConflicts {
-					stmt = append(stmt, "INSERT IGNORE INTO locks (target_id, job_id, created_at, expires_at, valid) VALUES (?, ?, ?, ?, ?)")
+					stmt = append(stmt, d.dialect.InsertIgnore()+" locks (target_id, job_id, created_at, expires_at, valid) VALUES (?, ?, ?, ?, ?)")
 				} else {
 					stmt = append(stmt, "INSERT INTO locks (target_id, job_id, created_at, expires_at, valid) VALUES (?, ?, ?, ?, ?)")
 				}
@@ -219,7 +220,10 @@ func (d *DBLocker) handleLock(ctx xcontext.Context, jobID int64, targets []strin
 			if len(stmt) < d.maxBatchSize && i < len(toInsert)-1 {
 				continue
 			}
-			if _, err := tx.Exec(strings.Join(stmt, ""), args...); err != nil {
+			if allowConflicts {
+				stmt = append(stmt, d.dialect.OnConflictIgnore())
+			}
+			if _, err := tx.Exec(d.dialect.Rebind(strings.Join(stmt, "")), args...); err != nil {
 				return nil, fmt.Errorf("insert statement failed: %w", err)
 			}
 			stmt = nil
@@ -291,12 +295,87 @@ func (d *DBLocker) handleUnlock(ctx xcontext.Context, jobID int64, targets []str
 	for _, targetID := range targets {
 		queryList = append(queryList, targetID)
 	}
-	if _, err := tx.Exec(del, queryList...); err != nil {
+	if _, err := tx.Exec(d.dialect.Rebind(del), queryList...); err != nil {
 		return fmt.Errorf("unable to unlock targets %v, owner %d: %w", targets, jobID, err)
 	}
 	return tx.Commit()
 }
 
+// handleForceUnlock drops the locks on the given targets regardless of the
+// owner, and returns the dropped locks.
+func (d *DBLocker) handleForceUnlock(ctx xcontext.Context, targets []string) ([]dblock, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("unable to start database transaction: %w", err)
+	}
+	defer func() {
+		// this always fails if tx.Commit() was called before, ignore error
+		_ = tx.Rollback()
+	}()
+
+	locks, err := d.queryLocks(tx, targets)
+	if err != nil {
+		return nil, err
+	}
+	del := "DELETE FROM locks WHERE target_id IN " + listQueryString(uint(len(targets)))
+	queryList := make([]interface{}, 0, len(targets))
+	for _, targetID := range targets {
+		queryList = append(queryList, targetID)
+	}
+	if _, err := tx.Exec(d.dialect.Rebind(del), queryList...); err != nil {
+		return nil, fmt.Errorf("unable to force unlock targets %v: %w", targets, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	res := make([]dblock, 0, len(locks))
+	for _, lock := range locks {
+		res = append(res, lock)
+	}
+	return res, nil
+}
+
+// queryAllLocks returns all the locks in the database
+func (d *DBLocker) queryAllLocks() ([]dblock, error) {
+	rows, err := d.db.Query("SELECT target_id, job_id, created_at, expires_at FROM locks")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read existing locks: %w", err)
+	}
+	defer rows.Close()
+
+	var locks []dblock
+	for rows.Next() {
+		row := dblock{}
+		if err := rows.Scan(&row.targetID, &row.jobID, &row.createdAt, &row.expiresAt); err != nil {
+			return nil, fmt.Errorf("unexpected read from database: %w", err)
+		}
+		locks = append(locks, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unexpected error iterating db read results: %w", err)
+	}
+	return locks, nil
+}
+
+// lockInfos converts dblocks to a list of target.LockInfo sorted by target ID
+func lockInfos(locks []dblock) []target.LockInfo {
+	res := make([]target.LockInfo, 0, len(locks))
+	for _, lock := range locks {
+		res = append(res, target.LockInfo{
+			TargetID:  lock.targetID,
+			JobID:     types.JobID(lock.jobID),
+			CreatedAt: lock.createdAt,
+			ExpiresAt: lock.expiresAt,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].TargetID < res[j].TargetID })
+	return res
+}
+
 func validateTargets(targets []*target.Target) error {
 	for _, target := range targets {
 		if target.ID == "" {
@@ -365,6 +444,44 @@ func (d *DBLocker) RefreshLocks(ctx xcontext.Context, jobID types.JobID, duratio
 	return err
 }
 
+// ListLocks lists the locks on the given targets, or all the locks.
+// See target.Locker for API details
+func (d *DBLocker) ListLocks(ctx xcontext.Context, targets []*target.Target) ([]target.LockInfo, error) {
+	if err := validateTargets(targets); err != nil {
+		return nil, fmt.Errorf("invalid list request: %w", err)
+	}
+	if len(targets) == 0 {
+		locks, err := d.queryAllLocks()
+		if err != nil {
+			return nil, err
+		}
+		return lockInfos(locks), nil
+	}
+	locksMap, err := d.queryLocks(d.db, targetIDList(targets))
+	if err != nil {
+		return nil, err
+	}
+	locks := make([]dblock, 0, len(locksMap))
+	for _, lock := range locksMap {
+		locks = append(locks, lock)
+	}
+	return lockInfos(locks), nil
+}
+
+// ForceUnlock unlocks the given targets regardless of the owner.
+// See target.Locker for API details
+func (d *DBLocker) ForceUnlock(ctx xcontext.Context, targets []*target.Target) ([]target.LockInfo, error) {
+	if err := validateTargets(targets); err != nil {
+		return nil, fmt.Errorf("invalid force unlock request: %w", err)
+	}
+	locks, err := d.handleForceUnlock(ctx, targetIDList(targets))
+	ctx.Debugf("ForceUnlock %d targets, %d were locked: %v", len(targets), len(locks), err)
+	if err != nil {
+		return nil, err
+	}
+	return lockInfos(locks), nil
+}
+
 // Close closes the DB connection and releases resources.
 func (d *DBLocker) Close() error {
 	return d.db.Close()
@@ -382,8 +499,9 @@ func (d *DBLocker) ResetAllLocks(ctx xcontext.Context) error {
 // Opt is a function type that sets parameters on the DBLocker object
 type Opt func(dblocker *DBLocker)
 
-// WithDriverName option allows using a mysql-compatible driver (e.g. a wrapper around mysql
-// or a syntax-compatible variant).
+// WithDriverName option allows using a driver other than the default one of the dialect
+// (e.g. a wrapper around mysql or a syntax-compatible variant). Drivers other than
+// "postgres" are assumed to be mysql-compatible.
 func WithDriverName(name string) Opt {
 	return func(d *DBLocker) {
 		d.driverName = name
@@ -415,9 +533,12 @@ func New(dbURI string, opts ...Opt) (*DBLocker, error) {
 		Opt(res)
 	}
 
-	driverName := "mysql"
-	if res.driverName != "" {
-		driverName = res.driverName
+	driverName := res.driverName
+	if driverName != "" {
+		res.dialect = dialect.FromDriverName(driverName)
+	} else {
+		res.dialect = dialect.FromURI(dbURI)
+		driverName = res.dialect.DriverName()
 	}
 	db, err := sql.Open(driverName, dbURI)
 	if err != nil {
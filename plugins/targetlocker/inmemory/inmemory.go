@@ -10,6 +10,7 @@ package inmemory
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -35,6 +36,8 @@ type request struct {
 	// The in-memory locker enforces that the requests are validated against the
 	// right owner.
 	owner types.JobID
+	// force enables unlocking regardless of the owner, no owner is required.
+	force bool
 	// limit provides an upper limit on how many locks will be acquired
 	limit uint
 	// timeout is the initial lock duration when acquiring a new lock
@@ -43,6 +46,9 @@ type request struct {
 	timeout time.Duration
 	// locked is list of target IDs that were locked in this transaction (if any)
 	locked []string
+	// listed is the list of locks returned by list requests, or removed by
+	// forced unlock requests.
+	listed []target.LockInfo
 	// err reports whether there were errors in any lock-related operation.
 	err chan error
 }
@@ -53,11 +59,20 @@ type lock struct {
 	expiresAt time.Time
 }
 
+func (l lock) info(targetID string) target.LockInfo {
+	return target.LockInfo{
+		TargetID:  targetID,
+		JobID:     l.owner,
+		CreatedAt: l.createdAt,
+		ExpiresAt: l.expiresAt,
+	}
+}
+
 func validateRequest(req *request) error {
 	if req == nil {
 		return fmt.Errorf("got nil request")
 	}
-	if req.owner == 0 {
+	if req.owner == 0 && !req.force {
 		return fmt.Errorf("owner cannot be zero")
 	}
 	for _, target := range req.targets {
@@ -71,7 +86,7 @@ func validateRequest(req *request) error {
 // broker is the broker of locking requests, and it's the only goroutine with
 // access to the locks map, in accordance with Go's "share memory by
 // communicating" principle.
-func broker(clk clock.Clock, lockRequests, unlockRequests <-chan *request, done <-chan struct{}) {
+func broker(clk clock.Clock, lockRequests, unlockRequests, listRequests <-chan *request, done <-chan struct{}) {
 	locks := make(map[string]lock)
 	for {
 		select {
@@ -152,6 +167,16 @@ func broker(clk clock.Clock, lockRequests, unlockRequests <-chan *request, done
 				continue
 			}
 			req.ctx.Debugf("Requested to transactionally unlock %d targets: %v", len(req.targets), req.targets)
+			if req.force {
+				for _, t := range req.targets {
+					if l, ok := locks[t.ID]; ok {
+						req.listed = append(req.listed, l.info(t.ID))
+						delete(locks, t.ID)
+					}
+				}
+				req.err <- nil
+				continue
+			}
 			// validate
 			var unlockErr error
 			for _, t := range req.targets {
@@ -172,14 +197,28 @@ func broker(clk clock.Clock, lockRequests, unlockRequests <-chan *request, done
 				}
 			}
 			req.err <- unlockErr
+		case req := <-listRequests:
+			if len(req.targets) == 0 {
+				for id, l := range locks {
+					req.listed = append(req.listed, l.info(id))
+				}
+			} else {
+				for _, t := range req.targets {
+					if l, ok := locks[t.ID]; ok {
+						req.listed = append(req.listed, l.info(t.ID))
+					}
+				}
+			}
+			sort.Slice(req.listed, func(i, j int) bool { return req.listed[i].TargetID < req.listed[j].TargetID })
+			req.err <- nil
 		}
 	}
 }
 
 // InMemory locks targets in an in-memory map.
 type InMemory struct {
-	lockRequests, unlockRequests chan *request
-	done                         chan struct{}
+	lockRequests, unlockRequests, listRequests chan *request
+	done                                       chan struct{}
 }
 
 func newReq(ctx xcontext.Context, jobID types.JobID, targets []*target.Target) request {
@@ -243,6 +282,25 @@ func (tl *InMemory) RefreshLocks(ctx xcontext.Context, jobID types.JobID, durati
 	return err
 }
 
+// ListLocks returns the locks on the specified targets, or all the locks if
+// no target is specified.
+func (tl *InMemory) ListLocks(ctx xcontext.Context, targets []*target.Target) ([]target.LockInfo, error) {
+	req := newReq(ctx, 0, targets)
+	tl.listRequests <- &req
+	err := <-req.err
+	return req.listed, err
+}
+
+// ForceUnlock unlocks the specified targets regardless of the owner.
+func (tl *InMemory) ForceUnlock(ctx xcontext.Context, targets []*target.Target) ([]target.LockInfo, error) {
+	req := newReq(ctx, 0, targets)
+	req.force = true
+	tl.unlockRequests <- &req
+	err := <-req.err
+	ctx.Debugf("ForceUnlock %d targets, %d were locked: %v", len(targets), len(req.listed), err)
+	return req.listed, err
+}
+
 // Close stops the brokern and releases resources.
 func (tl *InMemory) Close() error {
 	close(tl.done)
@@ -253,11 +311,13 @@ func (tl *InMemory) Close() error {
 func New(clk clock.Clock) target.Locker {
 	lockRequests := make(chan *request)
 	unlockRequests := make(chan *request)
+	listRequests := make(chan *request)
 	done := make(chan struct{})
-	go broker(clk, lockRequests, unlockRequests, done)
+	go broker(clk, lockRequests, unlockRequests, listRequests, done)
 	return &InMemory{
 		lockRequests:   lockRequests,
 		unlockRequests: unlockRequests,
+		listRequests:   listRequests,
 		done:           done,
 	}
 }
@@ -50,6 +50,17 @@ func (tl Noop) RefreshLocks(ctx xcontext.Context, jobID types.JobID, _ time.Dura
 	return nil
 }
 
+// ListLocks returns no locks, since none was ever taken.
+func (tl Noop) ListLocks(ctx xcontext.Context, _ []*target.Target) ([]target.LockInfo, error) {
+	return nil, nil
+}
+
+// ForceUnlock unlocks the specified targets by doing nothing.
+func (tl Noop) ForceUnlock(ctx xcontext.Context, targets []*target.Target) ([]target.LockInfo, error) {
+	ctx.Infof("Force-unlocked %d targets by doing nothing", len(targets))
+	return nil, nil
+}
+
 func (tl Noop) Close() error {
 	return nil
 }
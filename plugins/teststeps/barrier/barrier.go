@@ -0,0 +1,280 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package barrier implements a test step that holds the targets until all of
+// them have reached it, so that the following steps start on all the targets
+// at the same time.
+package barrier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/teststeps"
+)
+
+// Name is the name used to look this plugin up.
+var Name = "Barrier"
+
+// event names for this plugin
+const (
+	EventBarrierReleased = event.Name("BarrierReleased")
+)
+
+// Events defines the events that a TestStep is allow to emit
+var Events = []event.Name{
+	EventBarrierReleased,
+}
+
+// reasons for releasing the targets
+const (
+	releasedAll     = "all"
+	releasedQuorum  = "quorum"
+	releasedTimeout = "timeout"
+)
+
+// eventBarrierReleasedPayload is the payload for EventBarrierReleased
+type eventBarrierReleasedPayload struct {
+	// Reason is why the targets were released: all, quorum or timeout
+	Reason string
+	// Arrived is the number of targets that reached the barrier before the release
+	Arrived int
+}
+
+// stateVersion is the version of the resume state of the step
+const stateVersion = 1
+
+// barrierState is the resume state of the step
+type barrierState struct {
+	Version int `json:"V"`
+	// Targets are the targets held at the barrier
+	Targets []*target.Target `json:"T,omitempty"`
+	// Arrived is the number of targets that reached the barrier
+	Arrived int `json:"A,omitempty"`
+	// Released is set once the targets are released, the later ones just go through
+	Released bool `json:"R,omitempty"`
+	// Failed is set if the barrier timed out before the quorum was reached
+	Failed bool `json:"F,omitempty"`
+	// WaitedMS is how long the barrier waited before the pause
+	WaitedMS int64 `json:"W,omitempty"`
+}
+
+// Barrier holds every target until all the targets still alive have reached
+// it, or a quorum of them did, or a timeout passes. Targets that failed in
+// the previous steps never reach the barrier and are not waited for.
+type Barrier struct {
+	// quorum is the number of targets that releases the barrier, 0 for all
+	quorum int
+	// timeout is how long to wait for the targets, 0 for no limit
+	timeout time.Duration
+}
+
+var _ test.RendezvousTestStep = (*Barrier)(nil)
+
+// Name returns the plugin name.
+func (b *Barrier) Name() string {
+	return Name
+}
+
+// WaitsForAllTargets marks the step as a rendezvous, the targets wait for
+// each other in it
+func (b *Barrier) WaitsForAllTargets() {}
+
+// Run executes the step
+func (b *Barrier) Run(
+	ctx xcontext.Context,
+	ch test.TestStepChannels,
+	ev testevent.Emitter,
+	stepsVars test.StepsVariables,
+	params test.TestStepParameters,
+	resumeState json.RawMessage,
+) (json.RawMessage, error) {
+	if err := b.setParams(params); err != nil {
+		return nil, err
+	}
+
+	var state barrierState
+	if len(resumeState) > 0 {
+		if err := json.Unmarshal(resumeState, &state); err != nil {
+			return nil, fmt.Errorf("invalid resume state: %w", err)
+		}
+		if state.Version != stateVersion {
+			return nil, fmt.Errorf("incompatible resume state: want %d, got %d", stateVersion, state.Version)
+		}
+		ctx.Debugf("resuming with %d targets held, %d arrived", len(state.Targets), state.Arrived)
+	}
+	start := time.Now()
+
+	// the time spent paused does not count towards the timeout
+	var timeout <-chan time.Time
+	if b.timeout > 0 && !state.Released && !state.Failed {
+		timer := time.NewTimer(b.timeout - time.Duration(state.WaitedMS)*time.Millisecond)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	send := func(tgt *target.Target, err error) error {
+		select {
+		case ch.Out <- test.TestStepResult{Target: tgt, Err: err}:
+			return nil
+		case <-ctx.Done():
+			return xcontext.ErrCanceled
+		}
+	}
+	timeoutErr := func() error {
+		return fmt.Errorf("barrier timed out after %s with %d of %d targets", b.timeout, state.Arrived, b.quorum)
+	}
+	release := func(reason string) error {
+		ctx.Infof("releasing %d targets, reason: %s", len(state.Targets), reason)
+		state.Released = true
+		for _, tgt := range state.Targets {
+			payload := eventBarrierReleasedPayload{Reason: reason, Arrived: state.Arrived}
+			if err := emitEvent(ctx, ev, tgt, EventBarrierReleased, payload); err != nil {
+				ctx.Warnf("Failed to emit event: %v", err)
+			}
+			if err := send(tgt, nil); err != nil {
+				return err
+			}
+		}
+		state.Targets = nil
+		return nil
+	}
+
+	if !state.Released && !state.Failed && b.quorum > 0 && state.Arrived >= b.quorum {
+		if err := release(releasedQuorum); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		select {
+		// no need to check for pause here, pausing closes the channel
+		case tgt, ok := <-ch.In:
+			if !ok {
+				if ctx.IsSignaledWith(xcontext.ErrPaused) {
+					state.WaitedMS += time.Since(start).Milliseconds()
+					ctx.Debugf("paused with %d targets held", len(state.Targets))
+					return teststeps.MarshalState(&state, stateVersion)
+				}
+				// all the live targets have arrived
+				if len(state.Targets) > 0 {
+					if err := release(releasedAll); err != nil {
+						return nil, err
+					}
+				}
+				return nil, nil
+			}
+
+			state.Arrived++
+			ctx.Debugf("target %s arrived, %d so far", tgt.ID, state.Arrived)
+			switch {
+			case state.Failed:
+				if err := send(tgt, timeoutErr()); err != nil {
+					return nil, err
+				}
+			case state.Released:
+				if err := send(tgt, nil); err != nil {
+					return nil, err
+				}
+			default:
+				state.Targets = append(state.Targets, tgt)
+				if b.quorum > 0 && state.Arrived >= b.quorum {
+					if err := release(releasedQuorum); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+		case <-timeout:
+			timeout = nil
+			if state.Released {
+				continue
+			}
+			if b.quorum == 0 {
+				// without a quorum, the targets that made it in time go on
+				if err := release(releasedTimeout); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			ctx.Warnf("timed out with %d of %d targets", state.Arrived, b.quorum)
+			state.Failed = true
+			for _, tgt := range state.Targets {
+				if err := send(tgt, timeoutErr()); err != nil {
+					return nil, err
+				}
+			}
+			state.Targets = nil
+
+		case <-ctx.Done():
+			ctx.Debugf("canceled with %d targets held", len(state.Targets))
+			return nil, xcontext.ErrCanceled
+		}
+	}
+}
+
+func emitEvent(ctx xcontext.Context, emitter testevent.Emitter, tgt *target.Target, name event.Name, payload interface{}) error {
+	jsonstr, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot encode payload for event '%s': %w", name, err)
+	}
+
+	jsonPayload := json.RawMessage(jsonstr)
+	data := testevent.Data{
+		EventName: name,
+		Target:    tgt,
+		Payload:   &jsonPayload,
+	}
+	if err := emitter.Emit(ctx, data); err != nil {
+		return fmt.Errorf("cannot emit event: %w", err)
+	}
+	return nil
+}
+
+func (b *Barrier) setParams(params test.TestStepParameters) error {
+	b.quorum = 0
+	if q := params.GetOne("quorum"); !q.IsEmpty() {
+		quorum, err := strconv.Atoi(q.String())
+		if err != nil || quorum <= 0 {
+			return fmt.Errorf("invalid quorum %q: must be a positive number of targets", q.String())
+		}
+		b.quorum = quorum
+	}
+
+	b.timeout = 0
+	if t := params.GetOne("timeout"); !t.IsEmpty() {
+		timeout, err := time.ParseDuration(t.String())
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", t.String(), err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout %q: must be positive", t.String())
+		}
+		b.timeout = timeout
+	}
+	return nil
+}
+
+// ValidateParameters validates the parameters associated to the TestStep
+func (b *Barrier) ValidateParameters(_ xcontext.Context, params test.TestStepParameters) error {
+	return b.setParams(params)
+}
+
+// New initializes and returns a new Barrier test step.
+func New() test.TestStep {
+	return &Barrier{}
+}
+
+// Load returns the name, factory and events which are needed to register the step.
+func Load() (string, test.TestStepFactory, []event.Name) {
+	return Name, New, Events
+}
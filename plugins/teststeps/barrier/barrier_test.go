@@ -0,0 +1,192 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package barrier
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []testevent.Data
+}
+
+func (r *eventRecorder) Emit(ctx xcontext.Context, data testevent.Data) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, data)
+	return nil
+}
+
+type barrierRun struct {
+	ctx   xcontext.Context
+	pause func()
+	in    chan *target.Target
+	out   chan test.TestStepResult
+	done  chan struct{}
+	state json.RawMessage
+	err   error
+}
+
+func startBarrier(t *testing.T, params test.TestStepParameters, resumeState json.RawMessage) *barrierRun {
+	ctx, pause := xcontext.WithNotify(xcontext.Background(), xcontext.ErrPaused)
+	ctx, cancel := xcontext.WithCancel(ctx)
+	t.Cleanup(cancel)
+
+	r := &barrierRun{
+		ctx:   ctx,
+		pause: pause,
+		in:    make(chan *target.Target),
+		out:   make(chan test.TestStepResult, 10),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		ch := test.TestStepChannels{In: r.in, Out: r.out}
+		r.state, r.err = New().Run(ctx, ch, &eventRecorder{}, nil, params, resumeState)
+	}()
+	return r
+}
+
+func (r *barrierRun) arrive(ids ...string) {
+	for _, id := range ids {
+		r.in <- &target.Target{ID: id}
+	}
+}
+
+func (r *barrierRun) wait(t *testing.T) {
+	select {
+	case <-r.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("barrier did not return")
+	}
+}
+
+func requireHeld(t *testing.T, r *barrierRun) {
+	select {
+	case res := <-r.out:
+		t.Fatalf("target %s was not held", res.Target.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func requireReleased(t *testing.T, r *barrierRun, fail bool, ids ...string) {
+	var released []string
+	for range ids {
+		select {
+		case res := <-r.out:
+			if fail {
+				require.Error(t, res.Err)
+			} else {
+				require.NoError(t, res.Err)
+			}
+			released = append(released, res.Target.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("targets %v were not released, got %v", ids, released)
+		}
+	}
+	require.ElementsMatch(t, ids, released)
+}
+
+func params(kv ...string) test.TestStepParameters {
+	p := test.TestStepParameters{}
+	for i := 0; i < len(kv); i += 2 {
+		p[kv[i]] = []test.Param{*test.NewParam(kv[i+1])}
+	}
+	return p
+}
+
+func TestBarrierAll(t *testing.T) {
+	r := startBarrier(t, params(), nil)
+	r.arrive("T1", "T2", "T3")
+	requireHeld(t, r)
+
+	// the channel is closed once the targets that did not fail have arrived
+	close(r.in)
+	requireReleased(t, r, false, "T1", "T2", "T3")
+	r.wait(t)
+	require.NoError(t, r.err)
+}
+
+func TestBarrierQuorum(t *testing.T) {
+	r := startBarrier(t, params("quorum", "2"), nil)
+	r.arrive("T1")
+	requireHeld(t, r)
+	r.arrive("T2")
+	requireReleased(t, r, false, "T1", "T2")
+
+	// the late targets go through
+	r.arrive("T3")
+	requireReleased(t, r, false, "T3")
+	close(r.in)
+	r.wait(t)
+	require.NoError(t, r.err)
+}
+
+func TestBarrierTimeout(t *testing.T) {
+	// without a quorum, the targets that arrived in time go on
+	r := startBarrier(t, params("timeout", "100ms"), nil)
+	r.arrive("T1")
+	requireReleased(t, r, false, "T1")
+	r.arrive("T2")
+	requireReleased(t, r, false, "T2")
+	close(r.in)
+	r.wait(t)
+	require.NoError(t, r.err)
+
+	// with a quorum, they fail
+	r = startBarrier(t, params("timeout", "100ms", "quorum", "2"), nil)
+	r.arrive("T1")
+	requireReleased(t, r, true, "T1")
+	r.arrive("T2")
+	requireReleased(t, r, true, "T2")
+	close(r.in)
+	r.wait(t)
+	require.NoError(t, r.err)
+}
+
+func TestBarrierPauseResume(t *testing.T) {
+	r := startBarrier(t, params("quorum", "3"), nil)
+	r.arrive("T1", "T2")
+	requireHeld(t, r)
+
+	// pausing closes the channel
+	r.pause()
+	close(r.in)
+	r.wait(t)
+	require.Equal(t, xcontext.ErrPaused, r.err)
+	require.NotEmpty(t, r.state)
+
+	// the held targets are not injected again on resume
+	r = startBarrier(t, params("quorum", "3"), r.state)
+	requireHeld(t, r)
+	r.arrive("T3")
+	requireReleased(t, r, false, "T1", "T2", "T3")
+	close(r.in)
+	r.wait(t)
+	require.NoError(t, r.err)
+}
+
+func TestBarrierInvalidParameters(t *testing.T) {
+	for _, p := range []test.TestStepParameters{
+		params("quorum", "0"),
+		params("quorum", "all"),
+		params("timeout", "soon"),
+		params("timeout", "-1s"),
+	} {
+		require.Error(t, New().ValidateParameters(xcontext.Background(), p), fmt.Sprint(p))
+	}
+}
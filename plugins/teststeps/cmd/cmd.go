@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -68,6 +69,12 @@ type Cmd struct {
 	// ConTest database, and that it might be a very long string. Depending on
 	// the output length, it could be truncated in order to store it.
 	emitStdout, emitStderr bool
+	// stream configures the emission of the output while the command runs,
+	// in size capped and rate limited EventCmdStdout and EventCmdStderr events
+	// with teststeps.OutputChunk payloads
+	stream *teststeps.OutputStreamConfig
+	// outputs are the step variables extracted from the stdout
+	outputs teststeps.Outputs
 }
 
 // Name returns the plugin name.
@@ -124,6 +131,18 @@ func (ts *Cmd) Run(
 		cmd.Dir = pwd
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+		var streams []*teststeps.OutputStream
+		if ts.stream != nil && ts.stream.Stdout {
+			s := teststeps.NewOutputStream(ctx, ev, target, EventCmdStdout, *ts.stream)
+			cmd.Stdout = io.MultiWriter(&stdout, s)
+			streams = append(streams, s)
+		}
+		if ts.stream != nil && ts.stream.Stderr {
+			s := teststeps.NewOutputStream(ctx, ev, target, EventCmdStderr, *ts.stream)
+			cmd.Stderr = io.MultiWriter(&stderr, s)
+			streams = append(streams, s)
+		}
 		cmd.SysProcAttr = &syscall.SysProcAttr{
 			// Put the command into a separate session (and group) so signals do not propagate directly to it.
 			Setsid: true,
@@ -140,6 +159,11 @@ func (ts *Cmd) Run(
 		}
 
 		runErr := cmd.Run()
+		for _, s := range streams {
+			if err := s.Close(); err != nil {
+				log.Warnf("Failed to flush output stream: %v", err)
+			}
+		}
 
 		if err := emitEvent(ctx, EventCmdEnd, nil, target, ev); err != nil {
 			log.Warnf("Failed to emit event: %v", err)
@@ -159,7 +183,10 @@ func (ts *Cmd) Run(
 
 		log.Infof("Command's '%s' with args '%s' stdout '%s', stderr is '%s', run err: '%v'",
 			cmd.Path, cmd.Args, stdout.Bytes(), stderr.Bytes(), runErr)
-		return runErr
+		if runErr != nil {
+			return runErr
+		}
+		return ts.outputs.Publish(stepsVars, target.ID, stdout.Bytes())
 	}
 	return teststeps.ForEachTarget(Name, ctx, ch, f)
 }
@@ -201,6 +228,24 @@ func (ts *Cmd) validateAndPopulate(params test.TestStepParameters) error {
 		}
 		ts.emitStderr = v
 	}
+	// validate stream
+	streamParam := params.GetOne("stream")
+	if !streamParam.IsEmpty() {
+		var config teststeps.OutputStreamConfig
+		if err := json.Unmarshal(streamParam.JSON(), &config); err != nil {
+			return fmt.Errorf("invalid `stream` parameter: %v", err)
+		}
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("invalid `stream` parameter: %v", err)
+		}
+		ts.stream = &config
+	}
+	// validate outputs
+	outputs, err := teststeps.ParseOutputs(params.GetOne("outputs"))
+	if err != nil {
+		return fmt.Errorf("invalid `outputs` parameter: %v", err)
+	}
+	ts.outputs = outputs
 	return nil
 }
 
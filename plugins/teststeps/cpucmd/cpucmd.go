@@ -59,9 +59,12 @@ type CPUCmd struct {
 	Executable      *test.Param
 	Args            []test.Param
 	Expect          *test.Param
+	Outputs         *test.Param
 	Timeout         *test.Param
 	SkipIfEmptyHost *test.Param
 	*cpu.Cmd
+
+	outputs teststeps.Outputs
 }
 
 // Name returns the plugin name.
@@ -232,9 +235,9 @@ func (ts *CPUCmd) Run(
 							return fmt.Errorf("match for %s not found for target %v", expect, target)
 						}
 					}
-				} else {
-					ctx.Warnf("Stderr of command '%v' is '%s'", c, stderr.Bytes())
+					return ts.outputs.Publish(stepsVars, target.ID, stdout.Bytes())
 				}
+				ctx.Warnf("Stderr of command '%v' is '%s'", c, stderr.Bytes())
 				return err
 			case <-ctx.Done():
 				return c.Signal(ssh.SIGKILL)
@@ -244,7 +247,7 @@ func (ts *CPUCmd) Run(
 					matches := re.FindAll(stdout.Bytes(), -1)
 					if len(matches) > 0 {
 						log.Infof("match for regex '%s' found", expect)
-						return nil
+						return ts.outputs.Publish(stepsVars, target.ID, stdout.Bytes())
 					}
 				}
 				if time.Now().After(timeTimeout) {
@@ -295,6 +298,12 @@ func (ts *CPUCmd) validateAndPopulate(params test.TestStepParameters) error {
 	}
 	ts.Args = params.Get("args")
 	ts.Expect = params.GetOne("expect")
+	ts.Outputs = params.GetOne("outputs")
+	outputs, err := teststeps.ParseOutputs(ts.Outputs)
+	if err != nil {
+		return fmt.Errorf("invalid 'outputs' parameter: %v", err)
+	}
+	ts.outputs = outputs
 
 	if params.GetOne("timeout").IsEmpty() {
 		ts.Timeout = test.NewParam(defaultTimeoutParameter)
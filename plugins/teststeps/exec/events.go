@@ -20,19 +20,38 @@ const (
 	TestStartEvent = event.Name("TestStart")
 	TestEndEvent   = event.Name("TestEnd")
 	TestLogEvent   = event.Name("TestLog")
+	TestErrorEvent = event.Name("TestError")
 
 	StepStartEvent = event.Name("StepStart")
 	StepEndEvent   = event.Name("StepEnd")
 	StepLogEvent   = event.Name("StepLog")
+	StepErrorEvent = event.Name("StepError")
+
+	MeasurementEvent              = event.Name("Measurement")
+	MeasurementSeriesStartEvent   = event.Name("MeasurementSeriesStart")
+	MeasurementSeriesElementEvent = event.Name("MeasurementSeriesElement")
+	MeasurementSeriesEndEvent     = event.Name("MeasurementSeriesEnd")
+	DiagnosisEvent                = event.Name("Diagnosis")
+	FileEvent                     = event.Name("File")
+	ExtensionEvent                = event.Name("Extension")
+
+	StdoutEvent = event.Name("Stdout")
+	StderrEvent = event.Name("Stderr")
 )
 
 // Events defines the events that a TestStep is allow to emit. Emitting an event
 // that is not registered here will cause the plugin to terminate with an error.
 var Events = []event.Name{
 	TestStartEvent, TestEndEvent,
-	TestLogEvent,
+	TestLogEvent, TestErrorEvent,
 	StepStartEvent, StepEndEvent,
-	StepLogEvent,
+	StepLogEvent, StepErrorEvent,
+	MeasurementEvent,
+	MeasurementSeriesStartEvent, MeasurementSeriesElementEvent, MeasurementSeriesEndEvent,
+	DiagnosisEvent,
+	FileEvent,
+	ExtensionEvent,
+	StdoutEvent, StderrEvent,
 }
 
 type testStartEventPayload struct {
@@ -80,6 +99,106 @@ type stepLogEventPayload struct {
 	Message        string `json:"text,omitempty"`
 }
 
+type testErrorEventPayload struct {
+	SequenceNumber  int      `json:"sequenceNumber"`
+	Timestamp       string   `json:"timestamp"`
+	Symptom         string   `json:"symptom,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	SoftwareInfoIds []string `json:"softwareInfoIds,omitempty"`
+}
+
+type stepErrorEventPayload struct {
+	SequenceNumber  int      `json:"sequenceNumber"`
+	Timestamp       string   `json:"timestamp"`
+	StepId          string   `json:"stepId"`
+	Symptom         string   `json:"symptom,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	SoftwareInfoIds []string `json:"softwareInfoIds,omitempty"`
+}
+
+type validatorResultPayload struct {
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type"`
+	Value   Value  `json:"value"`
+	Pass    bool   `json:"pass"`
+	Message string `json:"message,omitempty"`
+}
+
+type measurementEventPayload struct {
+	SequenceNumber int                      `json:"sequenceNumber"`
+	Timestamp      string                   `json:"timestamp"`
+	StepId         string                   `json:"stepId"`
+	Name           string                   `json:"name"`
+	Value          Value                    `json:"value"`
+	Unit           string                   `json:"unit,omitempty"`
+	Validators     []validatorResultPayload `json:"validators,omitempty"`
+	HardwareInfoId string                   `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent            `json:"subcomponent,omitempty"`
+	Result         string                   `json:"result"`
+}
+
+type measurementSeriesStartEventPayload struct {
+	SequenceNumber int           `json:"sequenceNumber"`
+	Timestamp      string        `json:"timestamp"`
+	StepId         string        `json:"stepId"`
+	SeriesId       string        `json:"measurementSeriesId"`
+	Name           string        `json:"name"`
+	Unit           string        `json:"unit,omitempty"`
+	Validators     []Validator   `json:"validators,omitempty"`
+	HardwareInfoId string        `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent `json:"subcomponent,omitempty"`
+}
+
+type measurementSeriesElementEventPayload struct {
+	SequenceNumber   int                      `json:"sequenceNumber"`
+	Timestamp        string                   `json:"timestamp"`
+	StepId           string                   `json:"stepId"`
+	SeriesId         string                   `json:"measurementSeriesId"`
+	Index            int                      `json:"index"`
+	Value            Value                    `json:"value"`
+	ElementTimestamp string                   `json:"elementTimestamp,omitempty"`
+	Validators       []validatorResultPayload `json:"validators,omitempty"`
+	Result           string                   `json:"result"`
+}
+
+type measurementSeriesEndEventPayload struct {
+	SequenceNumber int    `json:"sequenceNumber"`
+	Timestamp      string `json:"timestamp"`
+	StepId         string `json:"stepId"`
+	SeriesId       string `json:"measurementSeriesId"`
+	TotalCount     int    `json:"totalCount"`
+}
+
+type diagnosisEventPayload struct {
+	SequenceNumber int           `json:"sequenceNumber"`
+	Timestamp      string        `json:"timestamp"`
+	StepId         string        `json:"stepId"`
+	Verdict        string        `json:"verdict"`
+	Type           string        `json:"type"`
+	Message        string        `json:"message,omitempty"`
+	HardwareInfoId string        `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent `json:"subcomponent,omitempty"`
+}
+
+type fileEventPayload struct {
+	SequenceNumber int    `json:"sequenceNumber"`
+	Timestamp      string `json:"timestamp"`
+	StepId         string `json:"stepId"`
+	DisplayName    string `json:"displayName"`
+	URI            string `json:"uri"`
+	IsSnapshot     bool   `json:"isSnapshot"`
+	Description    string `json:"description,omitempty"`
+	ContentType    string `json:"contentType,omitempty"`
+}
+
+type extensionEventPayload struct {
+	SequenceNumber int             `json:"sequenceNumber"`
+	Timestamp      string          `json:"timestamp"`
+	StepId         string          `json:"stepId"`
+	Name           string          `json:"name"`
+	Content        json.RawMessage `json:"content,omitempty"`
+}
+
 func emitEvent(ctx xcontext.Context, name event.Name, payload interface{}, tgt *target.Target, ev testevent.Emitter) error {
 	payloadData, err := json.Marshal(payload)
 	if err != nil {
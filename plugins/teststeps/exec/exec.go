@@ -31,16 +31,30 @@ type stepParams struct {
 
 	OCPOutput bool `json:"ocp_output"`
 
+	Stream *teststeps.OutputStreamConfig `json:"stream,omitempty"`
+
 	Constraints struct {
 		TimeQuota xjson.Duration `json:"time_quota,omitempty"`
 	} `json:"constraints,omitempty"`
 
 	ExitCodeMap map[int]string `json:"exitcode_map,omitempty"`
+
+	Expect *expectations `json:"expect,omitempty"`
+
+	Outputs teststeps.Outputs `json:"outputs,omitempty"`
+}
+
+// keepsOutput returns true if the stdout of the process is needed once it ended
+func (p stepParams) keepsOutput() bool {
+	return p.Expect.checksOutput() || len(p.Outputs) > 0
 }
 
 // Name is the name used to look this plugin up.
 var Name = "Exec"
 
+// stepStateVersion is the version of the resume state of the step
+const stepStateVersion = 1
+
 // TestStep implementation for the exec plugin
 type TestStep struct {
 	stepParams
@@ -65,7 +79,7 @@ func (ts *TestStep) Run(
 	}
 
 	tr := NewTargetRunner(ts, ev, stepsVars)
-	return teststeps.ForEachTarget(Name, ctx, ch, tr.Run)
+	return teststeps.ForEachTargetWithResume(ctx, ch, resumeState, stepStateVersion, tr.Run)
 }
 
 func (ts *TestStep) populateParams(stepParams test.TestStepParameters) error {
@@ -75,6 +89,27 @@ func (ts *TestStep) populateParams(stepParams test.TestStepParameters) error {
 		return fmt.Errorf("failed to deserialize parameters")
 	}
 
+	if ts.Stream != nil {
+		if err := ts.Stream.Validate(); err != nil {
+			return fmt.Errorf("invalid stream parameters: %w", err)
+		}
+	}
+
+	if ts.Expect != nil {
+		if err := ts.Expect.compile(); err != nil {
+			return fmt.Errorf("invalid expect parameters: %w", err)
+		}
+	}
+
+	if err := ts.Outputs.Validate(); err != nil {
+		return fmt.Errorf("invalid outputs parameters: %w", err)
+	}
+	for name := range ts.Expect.variables() {
+		if _, ok := ts.Outputs[name]; ok {
+			return fmt.Errorf("invalid outputs parameters: variable '%s' is also set by the expectations", name)
+		}
+	}
+
 	return nil
 }
 
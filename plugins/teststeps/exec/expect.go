@@ -0,0 +1,217 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/linuxboot/contest/pkg/lib/comparison"
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/linuxboot/contest/plugins/teststeps"
+	exec_transport "github.com/linuxboot/contest/plugins/teststeps/exec/transport"
+)
+
+// expectations are checks on the exit code and the stdout of the process that
+// decide whether the test passed, on top of the OCP output if any
+type expectations struct {
+	// ExitCodes are the exit codes of a successful process, instead of just 0
+	ExitCodes []int `json:"exit_codes,omitempty"`
+	// Match are the regexes the stdout must match; the named groups of the
+	// matches are saved as step variables
+	Match []string `json:"match,omitempty"`
+	// NotMatch are the regexes the stdout must not match
+	NotMatch []string `json:"not_match,omitempty"`
+	// JSON are the checks on the values of the stdout parsed as a JSON document
+	JSON []jsonExpectation `json:"json,omitempty"`
+
+	match    []*regexp.Regexp
+	notMatch []*regexp.Regexp
+	// vars are how the step variables are extracted from the stdout
+	vars teststeps.Outputs
+}
+
+// jsonExpectation checks a value in the stdout of the process parsed as JSON
+type jsonExpectation struct {
+	// Path is the JSONPath of the value, like "$.results[0].temp"
+	Path string `json:"path"`
+	// Cmp is a comparison expression the numeric value must satisfy, like "<80"
+	Cmp string `json:"cmp,omitempty"`
+	// Equal is the value it must be equal to
+	Equal interface{} `json:"equal,omitempty"`
+	// Var is the name of the step variable the value is saved as
+	Var string `json:"var,omitempty"`
+
+	path teststeps.JSONPath
+	cmp  *comparison.Expression
+}
+
+// compile checks the expectations and prepares them for use
+func (e *expectations) compile() error {
+	e.vars = make(teststeps.Outputs)
+
+	e.match = e.match[:0]
+	for _, expr := range e.Match {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid match regex %q: %w", expr, err)
+		}
+		for i, name := range re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if err := test.CheckIdentifier(name); err != nil {
+				return fmt.Errorf("invalid variable name %q in match regex %q: %w", name, expr, err)
+			}
+			group := i
+			e.vars[name] = teststeps.OutputVariable{Regex: expr, Group: &group}
+		}
+		e.match = append(e.match, re)
+	}
+
+	e.notMatch = e.notMatch[:0]
+	for _, expr := range e.NotMatch {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid not_match regex %q: %w", expr, err)
+		}
+		e.notMatch = append(e.notMatch, re)
+	}
+
+	for i := range e.JSON {
+		je := &e.JSON[i]
+
+		path, err := teststeps.ParseJSONPath(je.Path)
+		if err != nil {
+			return fmt.Errorf("invalid json path %q: %w", je.Path, err)
+		}
+		je.path = path
+
+		if je.Cmp != "" {
+			cmp, err := comparison.ParseExpression(je.Cmp)
+			if err != nil {
+				return fmt.Errorf("invalid comparison for json path %q: %w", je.Path, err)
+			}
+			if cmp.Type != comparison.TypeValue {
+				return fmt.Errorf("invalid comparison for json path %q: percentages are not supported", je.Path)
+			}
+			je.cmp = cmp
+		}
+		if je.Var != "" {
+			if err := test.CheckIdentifier(je.Var); err != nil {
+				return fmt.Errorf("invalid variable name %q for json path %q: %w", je.Var, je.Path, err)
+			}
+			e.vars[je.Var] = teststeps.OutputVariable{JSONPath: je.Path}
+		}
+	}
+	return nil
+}
+
+// checksOutput returns true if the stdout of the process is needed for the checks
+func (e *expectations) checksOutput() bool {
+	return e != nil && (len(e.Match) > 0 || len(e.NotMatch) > 0 || len(e.JSON) > 0)
+}
+
+// checkExitCode returns the outcome of the process given the error returned
+// by waiting on it, as decided by the expected exit codes
+func (e *expectations) checkExitCode(err error) error {
+	if e == nil || len(e.ExitCodes) == 0 {
+		return err
+	}
+
+	code := 0
+	if err != nil {
+		var ee *exec_transport.ExitError
+		if !errors.As(err, &ee) {
+			return err
+		}
+		code = ee.ExitCode
+	}
+
+	for _, c := range e.ExitCodes {
+		if c == code {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("exit code %d is not one of %v: %w", code, e.ExitCodes, err)
+	}
+	return fmt.Errorf("exit code 0 is not one of %v", e.ExitCodes)
+}
+
+// variables returns how the named groups of the match regexes and the json
+// values with a var are saved as step variables, once the output passed
+func (e *expectations) variables() teststeps.Outputs {
+	if e == nil {
+		return nil
+	}
+	return e.vars
+}
+
+// checkOutput checks the stdout of the process
+func (e *expectations) checkOutput(stdout []byte) error {
+	if !e.checksOutput() {
+		return nil
+	}
+
+	var failures []string
+
+	for _, re := range e.match {
+		if !re.Match(stdout) {
+			failures = append(failures, fmt.Sprintf("output does not match %q", re))
+		}
+	}
+
+	for _, re := range e.notMatch {
+		if m := re.Find(stdout); m != nil {
+			failures = append(failures, fmt.Sprintf("output matches %q: %q", re, m))
+		}
+	}
+
+	if len(e.JSON) > 0 {
+		var doc interface{}
+		if err := json.Unmarshal(stdout, &doc); err != nil {
+			failures = append(failures, fmt.Sprintf("output is not a json document: %v", err))
+		} else {
+			for _, je := range e.JSON {
+				if err := je.check(doc); err != nil {
+					failures = append(failures, err.Error())
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("unexpected output: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// check checks the value at the path in the document
+func (je jsonExpectation) check(doc interface{}) error {
+	value, err := je.path.Lookup(doc)
+	if err != nil {
+		return fmt.Errorf("json path %q: %w", je.Path, err)
+	}
+
+	if je.cmp != nil {
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("json path %q: value %v is not a number", je.Path, value)
+		}
+		if !je.cmp.Cmp.Compare(n, je.cmp.RHS) {
+			return fmt.Errorf("json path %q: %v is not %s", je.Path, n, je.Cmp)
+		}
+	}
+	if je.Equal != nil && !reflect.DeepEqual(value, je.Equal) {
+		return fmt.Errorf("json path %q: %v is not equal to %v", je.Path, value, je.Equal)
+	}
+	return nil
+}
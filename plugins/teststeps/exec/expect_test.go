@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	exec_transport "github.com/linuxboot/contest/plugins/teststeps/exec/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func parseExpectations(t *testing.T, data string) *expectations {
+	var e expectations
+	require.NoError(t, json.Unmarshal([]byte(data), &e))
+	require.NoError(t, e.compile())
+	return &e
+}
+
+func TestExpectExitCode(t *testing.T) {
+	var none *expectations
+	require.NoError(t, none.checkExitCode(nil))
+	require.Error(t, none.checkExitCode(&exec_transport.ExitError{ExitCode: 1}))
+
+	e := parseExpectations(t, `{"exit_codes": [1, 3]}`)
+	require.NoError(t, e.checkExitCode(&exec_transport.ExitError{ExitCode: 3}))
+	require.Error(t, e.checkExitCode(nil))
+
+	err := e.checkExitCode(&exec_transport.ExitError{ExitCode: 2})
+	var ee *exec_transport.ExitError
+	require.True(t, errors.As(err, &ee))
+	require.Equal(t, 2, ee.ExitCode)
+
+	// errors other than the exit code are kept
+	require.Error(t, e.checkExitCode(errors.New("connection lost")))
+}
+
+func TestExpectOutput(t *testing.T) {
+	e := parseExpectations(t, `{
+		"match": ["version (?P<major>\\d+)\\.(?P<minor>\\d+)", "ready"],
+		"not_match": ["(?i)error"]
+	}`)
+
+	stdout := []byte("starting\nversion 5.12\nready\n")
+	require.NoError(t, e.checkOutput(stdout))
+	vars, err := e.variables().Extract(stdout)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"major": "5", "minor": "12"}, vars)
+
+	err = e.checkOutput([]byte("version 5.12\nERROR: not ready\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ERROR")
+
+	err = e.checkOutput([]byte("ready\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "version")
+}
+
+func TestExpectJSON(t *testing.T) {
+	e := parseExpectations(t, `{
+		"json": [
+			{"path": "$.status", "equal": "ok"},
+			{"path": "$.sensors[1].temp", "cmp": "<80", "var": "temp"},
+			{"path": "$['fan speed']", "cmp": ">=1000"}
+		]
+	}`)
+
+	stdout := []byte(`{"status": "ok", "sensors": [{"temp": 90}, {"temp": 65.5}], "fan speed": 1200}`)
+	require.NoError(t, e.checkOutput(stdout))
+	vars, err := e.variables().Extract(stdout)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"temp": 65.5}, vars)
+
+	require.Error(t, e.checkOutput([]byte(`{"status": "ok", "sensors": [{"temp": 90}, {"temp": 85}], "fan speed": 1200}`)))
+	require.Error(t, e.checkOutput([]byte(`{"status": "ok", "sensors": [], "fan speed": 1200}`)))
+	require.Error(t, e.checkOutput([]byte(`not json`)))
+}
+
+func TestExpectInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"match": ["("]}`,
+		`{"match": ["(?P<1st>a)"]}`,
+		`{"json": [{"path": "status"}]}`,
+		`{"json": [{"path": "$.a[x]"}]}`,
+		`{"json": [{"path": "$.a", "cmp": ">50%"}]}`,
+		`{"json": [{"path": "$.a", "var": "a.b"}]}`,
+	} {
+		var e expectations
+		require.NoError(t, json.Unmarshal([]byte(data), &e))
+		require.Error(t, e.compile(), data)
+	}
+}
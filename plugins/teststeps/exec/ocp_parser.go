@@ -6,7 +6,9 @@
 package exec
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/linuxboot/contest/pkg/event/testevent"
 	"github.com/linuxboot/contest/pkg/target"
@@ -40,6 +42,14 @@ const (
 	ResultNA   = Result("NOT_APPLICABLE")
 )
 
+type DiagnosisType string
+
+const (
+	DiagnosisPass    = DiagnosisType("PASS")
+	DiagnosisFail    = DiagnosisType("FAIL")
+	DiagnosisUnknown = DiagnosisType("UNKNOWN")
+)
+
 // TODO: these should just be temporary until the go:generate tool
 // TODO: this should also mean refactoring all the parser code
 type Log struct {
@@ -58,10 +68,17 @@ type RunEnd struct {
 	Result Result `json:"result,omitempty"`
 }
 
+type OCPError struct {
+	Symptom         string   `json:"symptom,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	SoftwareInfoIds []string `json:"softwareInfoIds,omitempty"`
+}
+
 type RunArtifact struct {
 	RunStart *RunStart `json:"testRunStart,omitempty"`
 	RunEnd   *RunEnd   `json:"testRunEnd,omitempty"`
 	Log      *Log      `json:"log,omitempty"`
+	Error    *OCPError `json:"error,omitempty"`
 }
 
 type StepStart struct {
@@ -73,12 +90,82 @@ type StepEnd struct {
 	Status Status `json:"status,omitempty"`
 }
 
+type Subcomponent struct {
+	Type     string `json:"type,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Location string `json:"location,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+// Value is any json value: float64, string, bool, nil or a list of these
+type Value = interface{}
+
+type Measurement struct {
+	Name           string        `json:"name,omitempty"`
+	Value          Value         `json:"value"`
+	Unit           string        `json:"unit,omitempty"`
+	Validators     []Validator   `json:"validators,omitempty"`
+	HardwareInfoId string        `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent `json:"subcomponent,omitempty"`
+}
+
+type MeasurementSeriesStart struct {
+	SeriesId       string        `json:"measurementSeriesId,omitempty"`
+	Name           string        `json:"name,omitempty"`
+	Unit           string        `json:"unit,omitempty"`
+	Validators     []Validator   `json:"validators,omitempty"`
+	HardwareInfoId string        `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent `json:"subcomponent,omitempty"`
+}
+
+type MeasurementSeriesElement struct {
+	Index     int    `json:"index"`
+	SeriesId  string `json:"measurementSeriesId,omitempty"`
+	Value     Value  `json:"value"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+type MeasurementSeriesEnd struct {
+	SeriesId   string `json:"measurementSeriesId,omitempty"`
+	TotalCount int    `json:"totalCount"`
+}
+
+type Diagnosis struct {
+	Verdict        string        `json:"verdict,omitempty"`
+	Type           DiagnosisType `json:"type,omitempty"`
+	Message        string        `json:"message,omitempty"`
+	HardwareInfoId string        `json:"hardwareInfoId,omitempty"`
+	Subcomponent   *Subcomponent `json:"subcomponent,omitempty"`
+}
+
+type File struct {
+	DisplayName string `json:"displayName,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	IsSnapshot  bool   `json:"isSnapshot"`
+	Description string `json:"description,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type Extension struct {
+	Name    string          `json:"name,omitempty"`
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
 type StepArtifact struct {
 	StepId string `json:"testStepId,omitempty"`
 
-	StepStart *StepStart `json:"testStepStart,omitempty"`
-	StepEnd   *StepEnd   `json:"testStepEnd,omitempty"`
-	Log       *Log       `json:"log,omitempty"`
+	StepStart                *StepStart                `json:"testStepStart,omitempty"`
+	StepEnd                  *StepEnd                  `json:"testStepEnd,omitempty"`
+	Log                      *Log                      `json:"log,omitempty"`
+	Measurement              *Measurement              `json:"measurement,omitempty"`
+	MeasurementSeriesStart   *MeasurementSeriesStart   `json:"measurementSeriesStart,omitempty"`
+	MeasurementSeriesElement *MeasurementSeriesElement `json:"measurementSeriesElement,omitempty"`
+	MeasurementSeriesEnd     *MeasurementSeriesEnd     `json:"measurementSeriesEnd,omitempty"`
+	Diagnosis                *Diagnosis                `json:"diagnosis,omitempty"`
+	Error                    *OCPError                 `json:"error,omitempty"`
+	File                     *File                     `json:"file,omitempty"`
+	Extension                *Extension                `json:"extension,omitempty"`
 }
 
 type OCPRoot struct {
@@ -90,7 +177,13 @@ type OCPRoot struct {
 
 // TODO: check if there can be multiple runs in the same output
 type OCPState struct {
-	RunEnd *RunEnd
+	RunEnd *RunEnd `json:"runEnd,omitempty"`
+
+	// Failures describes the failed diagnoses and measurements, in output order
+	Failures []string `json:"failures,omitempty"`
+
+	// Series holds the measurement series that were started, by id
+	Series map[string]*MeasurementSeriesStart `json:"series,omitempty"`
 }
 
 func (s OCPState) Error() error {
@@ -98,8 +191,11 @@ func (s OCPState) Error() error {
 		return fmt.Errorf("did not see a complete run")
 	}
 
+	if len(s.Failures) > 0 {
+		return fmt.Errorf("test failed: %s", strings.Join(s.Failures, "; "))
+	}
+
 	if s.RunEnd.Result != ResultPass {
-		// TODO: add a concat log of errors?
 		return fmt.Errorf("test failed")
 	}
 
@@ -113,10 +209,20 @@ type OCPEventParser struct {
 }
 
 func NewOCPEventParser(target *target.Target, ev testevent.Emitter) *OCPEventParser {
+	return NewOCPEventParserWithState(target, ev, OCPState{})
+}
+
+// NewOCPEventParserWithState returns a parser continuing from the state of a
+// parser that was interrupted, eg. when the job was paused
+func NewOCPEventParserWithState(target *target.Target, ev testevent.Emitter, state OCPState) *OCPEventParser {
+	if state.Series == nil {
+		state.Series = make(map[string]*MeasurementSeriesStart)
+	}
+
 	return &OCPEventParser{
 		target: target,
 		ev:     ev,
-		state:  OCPState{},
+		state:  state,
 	}
 }
 
@@ -156,6 +262,17 @@ func (p *OCPEventParser) parseRun(ctx xcontext.Context, node *RunArtifact, root
 		return emitEvent(ctx, TestLogEvent, payload, p.target, p.ev)
 	}
 
+	if node.Error != nil {
+		payload := testErrorEventPayload{
+			SequenceNumber:  root.SequenceNumber,
+			Timestamp:       root.Timestamp,
+			Symptom:         node.Error.Symptom,
+			Message:         node.Error.Message,
+			SoftwareInfoIds: node.Error.SoftwareInfoIds,
+		}
+		return emitEvent(ctx, TestErrorEvent, payload, p.target, p.ev)
+	}
+
 	return nil
 }
 
@@ -192,6 +309,152 @@ func (p *OCPEventParser) parseStep(ctx xcontext.Context, node *StepArtifact, roo
 		return emitEvent(ctx, StepLogEvent, payload, p.target, p.ev)
 	}
 
+	if node.Measurement != nil {
+		m := node.Measurement
+		validators, pass := validate(m.Value, m.Validators)
+		if !pass {
+			p.state.Failures = append(p.state.Failures,
+				fmt.Sprintf("measurement %q in step %q failed validation", m.Name, node.StepId))
+		}
+
+		payload := measurementEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			Name:           m.Name,
+			Value:          m.Value,
+			Unit:           m.Unit,
+			Validators:     validators,
+			HardwareInfoId: m.HardwareInfoId,
+			Subcomponent:   m.Subcomponent,
+			Result:         string(resultOf(pass)),
+		}
+		return emitEvent(ctx, MeasurementEvent, payload, p.target, p.ev)
+	}
+
+	if node.MeasurementSeriesStart != nil {
+		ms := node.MeasurementSeriesStart
+		p.state.Series[ms.SeriesId] = ms
+
+		payload := measurementSeriesStartEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			SeriesId:       ms.SeriesId,
+			Name:           ms.Name,
+			Unit:           ms.Unit,
+			Validators:     ms.Validators,
+			HardwareInfoId: ms.HardwareInfoId,
+			Subcomponent:   ms.Subcomponent,
+		}
+		return emitEvent(ctx, MeasurementSeriesStartEvent, payload, p.target, p.ev)
+	}
+
+	if node.MeasurementSeriesElement != nil {
+		me := node.MeasurementSeriesElement
+
+		// the elements are validated against the validators of their series
+		var (
+			name       string
+			validators []Validator
+		)
+		if ms, ok := p.state.Series[me.SeriesId]; ok {
+			name, validators = ms.Name, ms.Validators
+		} else {
+			ctx.Warnf("measurement series element for unknown series %q", me.SeriesId)
+		}
+
+		results, pass := validate(me.Value, validators)
+		if !pass {
+			p.state.Failures = append(p.state.Failures,
+				fmt.Sprintf("element %d of measurement series %q in step %q failed validation", me.Index, name, node.StepId))
+		}
+
+		payload := measurementSeriesElementEventPayload{
+			SequenceNumber:   root.SequenceNumber,
+			Timestamp:        root.Timestamp,
+			StepId:           node.StepId,
+			SeriesId:         me.SeriesId,
+			Index:            me.Index,
+			Value:            me.Value,
+			ElementTimestamp: me.Timestamp,
+			Validators:       results,
+			Result:           string(resultOf(pass)),
+		}
+		return emitEvent(ctx, MeasurementSeriesElementEvent, payload, p.target, p.ev)
+	}
+
+	if node.MeasurementSeriesEnd != nil {
+		me := node.MeasurementSeriesEnd
+		delete(p.state.Series, me.SeriesId)
+
+		payload := measurementSeriesEndEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			SeriesId:       me.SeriesId,
+			TotalCount:     me.TotalCount,
+		}
+		return emitEvent(ctx, MeasurementSeriesEndEvent, payload, p.target, p.ev)
+	}
+
+	if node.Diagnosis != nil {
+		d := node.Diagnosis
+		if d.Type == DiagnosisFail {
+			p.state.Failures = append(p.state.Failures,
+				fmt.Sprintf("diagnosis %q in step %q: %s", d.Verdict, node.StepId, d.Message))
+		}
+
+		payload := diagnosisEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			Verdict:        d.Verdict,
+			Type:           string(d.Type),
+			Message:        d.Message,
+			HardwareInfoId: d.HardwareInfoId,
+			Subcomponent:   d.Subcomponent,
+		}
+		return emitEvent(ctx, DiagnosisEvent, payload, p.target, p.ev)
+	}
+
+	if node.Error != nil {
+		payload := stepErrorEventPayload{
+			SequenceNumber:  root.SequenceNumber,
+			Timestamp:       root.Timestamp,
+			StepId:          node.StepId,
+			Symptom:         node.Error.Symptom,
+			Message:         node.Error.Message,
+			SoftwareInfoIds: node.Error.SoftwareInfoIds,
+		}
+		return emitEvent(ctx, StepErrorEvent, payload, p.target, p.ev)
+	}
+
+	if node.File != nil {
+		payload := fileEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			DisplayName:    node.File.DisplayName,
+			URI:            node.File.URI,
+			IsSnapshot:     node.File.IsSnapshot,
+			Description:    node.File.Description,
+			ContentType:    node.File.ContentType,
+		}
+		return emitEvent(ctx, FileEvent, payload, p.target, p.ev)
+	}
+
+	if node.Extension != nil {
+		payload := extensionEventPayload{
+			SequenceNumber: root.SequenceNumber,
+			Timestamp:      root.Timestamp,
+			StepId:         node.StepId,
+			Name:           node.Extension.Name,
+			Content:        node.Extension.Content,
+		}
+		return emitEvent(ctx, ExtensionEvent, payload, p.target, p.ev)
+	}
+
 	return nil
 }
 
@@ -214,3 +477,8 @@ func (ep *OCPEventParser) Parse(ctx xcontext.Context, root *OCPRoot) error {
 func (ep *OCPEventParser) Error() error {
 	return ep.state.Error()
 }
+
+// State returns the state of the parser, used to resume parsing after a pause
+func (ep *OCPEventParser) State() OCPState {
+	return ep.state
+}
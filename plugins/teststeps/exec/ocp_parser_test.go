@@ -8,6 +8,7 @@ package exec
 import (
 	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/linuxboot/contest/pkg/event/testevent"
@@ -19,11 +20,14 @@ import (
 type mockEmitter struct {
 	mock.Mock
 
+	mu    sync.Mutex
 	Calls []testevent.Data
 }
 
 func (e *mockEmitter) Emit(ctx xcontext.Context, data testevent.Data) error {
+	e.mu.Lock()
 	e.Calls = append(e.Calls, data)
+	e.mu.Unlock()
 
 	args := e.Called(ctx, data)
 	return args.Error(0)
@@ -58,3 +62,129 @@ func TestOCPEventParser(t *testing.T) {
 
 	require.Equal(t, payload.Result, "PASS")
 }
+
+func parseOCP(t *testing.T, data string) (*OCPEventParser, *mockEmitter) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	p := NewOCPEventParser(nil, ev)
+	dec := json.NewDecoder(strings.NewReader(data))
+	for dec.More() {
+		var root *OCPRoot
+		require.NoError(t, dec.Decode(&root))
+		require.NoError(t, p.Parse(ctx, root))
+	}
+	return p, ev
+}
+
+func TestOCPEventParserMeasurement(t *testing.T) {
+	data := `
+{"testStepArtifact":{"testStepId":"0","measurement":{"name":"voltage","value":3.3,"unit":"V","validators":[{"name":"min","type":"GREATER_THAN","value":3.0},{"name":"max","type":"LESS_THAN","value":3.2}]}},"sequenceNumber":1,"timestamp":"ts"}
+{"testRunArtifact":{"testRunEnd":{"name":"Power","status":"COMPLETE","result":"PASS"}},"sequenceNumber":2,"timestamp":"ts"}
+`
+	p, ev := parseOCP(t, data)
+
+	require.Equal(t, 2, len(ev.Calls))
+	require.Equal(t, MeasurementEvent, ev.Calls[0].EventName)
+
+	var payload measurementEventPayload
+	require.NoError(t, json.Unmarshal(*ev.Calls[0].Payload, &payload))
+	require.Equal(t, "voltage", payload.Name)
+	require.Equal(t, 3.3, payload.Value)
+	require.Equal(t, "V", payload.Unit)
+	require.Equal(t, "FAIL", payload.Result)
+	require.Equal(t, 2, len(payload.Validators))
+	require.True(t, payload.Validators[0].Pass)
+	require.False(t, payload.Validators[1].Pass)
+
+	// the run reported PASS, but the failed validator fails the step
+	require.Error(t, p.Error())
+	require.Contains(t, p.Error().Error(), "voltage")
+}
+
+func TestOCPEventParserMeasurementSeries(t *testing.T) {
+	data := `
+{"testStepArtifact":{"testStepId":"0","measurementSeriesStart":{"measurementSeriesId":"0_0","name":"fan","unit":"RPM","validators":[{"type":"GREATER_THAN_OR_EQUAL","value":1000}]}},"sequenceNumber":1,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"0","measurementSeriesElement":{"index":0,"measurementSeriesId":"0_0","value":1200,"timestamp":"ts"}},"sequenceNumber":2,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"0","measurementSeriesElement":{"index":1,"measurementSeriesId":"0_0","value":1000,"timestamp":"ts"}},"sequenceNumber":3,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"0","measurementSeriesEnd":{"measurementSeriesId":"0_0","totalCount":2}},"sequenceNumber":4,"timestamp":"ts"}
+{"testRunArtifact":{"testRunEnd":{"name":"Fans","status":"COMPLETE","result":"PASS"}},"sequenceNumber":5,"timestamp":"ts"}
+`
+	p, ev := parseOCP(t, data)
+
+	require.Equal(t, 5, len(ev.Calls))
+	require.Equal(t, MeasurementSeriesStartEvent, ev.Calls[0].EventName)
+	require.Equal(t, MeasurementSeriesElementEvent, ev.Calls[1].EventName)
+	require.Equal(t, MeasurementSeriesEndEvent, ev.Calls[3].EventName)
+
+	var payload measurementSeriesElementEventPayload
+	require.NoError(t, json.Unmarshal(*ev.Calls[2].Payload, &payload))
+	require.Equal(t, 1, payload.Index)
+	require.Equal(t, "PASS", payload.Result)
+
+	require.NoError(t, p.Error())
+}
+
+func TestOCPEventParserDiagnosis(t *testing.T) {
+	data := `
+{"testStepArtifact":{"testStepId":"0","diagnosis":{"verdict":"mem-ok","type":"PASS"}},"sequenceNumber":1,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"1","diagnosis":{"verdict":"mem-dimm-bad","type":"FAIL","message":"DIMM A1 failed"}},"sequenceNumber":2,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"1","error":{"symptom":"ecc","message":"uncorrectable error"}},"sequenceNumber":3,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"1","file":{"displayName":"dmesg","uri":"file:///tmp/dmesg","isSnapshot":true}},"sequenceNumber":4,"timestamp":"ts"}
+{"testRunArtifact":{"testRunEnd":{"name":"Memory","status":"COMPLETE","result":"PASS"}},"sequenceNumber":5,"timestamp":"ts"}
+`
+	p, ev := parseOCP(t, data)
+
+	require.Equal(t, 5, len(ev.Calls))
+	require.Equal(t, DiagnosisEvent, ev.Calls[0].EventName)
+	require.Equal(t, DiagnosisEvent, ev.Calls[1].EventName)
+	require.Equal(t, StepErrorEvent, ev.Calls[2].EventName)
+	require.Equal(t, FileEvent, ev.Calls[3].EventName)
+
+	var payload diagnosisEventPayload
+	require.NoError(t, json.Unmarshal(*ev.Calls[1].Payload, &payload))
+	require.Equal(t, "mem-dimm-bad", payload.Verdict)
+	require.Equal(t, "FAIL", payload.Type)
+
+	require.Error(t, p.Error())
+	require.Equal(t, 1, len(p.state.Failures))
+	require.Contains(t, p.state.Failures[0], "mem-dimm-bad")
+}
+
+func TestValidator(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator Validator
+		value     Value
+		pass      bool
+	}{
+		{"equal", Validator{Type: ValidatorEqual, Value: "ok"}, "ok", true},
+		{"equal_mismatch", Validator{Type: ValidatorEqual, Value: 1.0}, 2.0, false},
+		{"not_equal", Validator{Type: ValidatorNotEqual, Value: 1.0}, 2.0, true},
+		{"less_than", Validator{Type: ValidatorLessThan, Value: 10.0}, 9.5, true},
+		{"less_than_or_equal", Validator{Type: ValidatorLessThanOrEqual, Value: 10.0}, 10.0, true},
+		{"greater_than", Validator{Type: ValidatorGreaterThan, Value: 10.0}, 10.0, false},
+		{"greater_than_or_equal", Validator{Type: ValidatorGreaterThanOrEqual, Value: 10.0}, 10.0, true},
+		{"greater_than_not_number", Validator{Type: ValidatorGreaterThan, Value: 10.0}, "11", false},
+		{"regex_match", Validator{Type: ValidatorRegexMatch, Value: "^v[0-9]+$"}, "v12", true},
+		{"regex_match_list", Validator{Type: ValidatorRegexMatch, Value: []interface{}{"^v", "2$"}}, "v13", false},
+		{"regex_no_match", Validator{Type: ValidatorRegexNoMatch, Value: "error"}, "all good", true},
+		{"regex_invalid", Validator{Type: ValidatorRegexMatch, Value: "("}, "(", false},
+		{"in_set", Validator{Type: ValidatorInSet, Value: []interface{}{1.0, 2.0}}, 2.0, true},
+		{"not_in_set", Validator{Type: ValidatorNotInSet, Value: []interface{}{"a", "b"}}, "b", false},
+		{"unknown", Validator{Type: "ALMOST_EQUAL", Value: 1.0}, 1.0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Validate(tt.value)
+			if tt.pass {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
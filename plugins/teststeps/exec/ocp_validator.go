@@ -0,0 +1,153 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/linuxboot/contest/pkg/lib/comparison"
+)
+
+type ValidatorType string
+
+const (
+	ValidatorEqual              = ValidatorType("EQUAL")
+	ValidatorNotEqual           = ValidatorType("NOT_EQUAL")
+	ValidatorLessThan           = ValidatorType("LESS_THAN")
+	ValidatorLessThanOrEqual    = ValidatorType("LESS_THAN_OR_EQUAL")
+	ValidatorGreaterThan        = ValidatorType("GREATER_THAN")
+	ValidatorGreaterThanOrEqual = ValidatorType("GREATER_THAN_OR_EQUAL")
+	ValidatorRegexMatch         = ValidatorType("REGEX_MATCH")
+	ValidatorRegexNoMatch       = ValidatorType("REGEX_NO_MATCH")
+	ValidatorInSet              = ValidatorType("IN_SET")
+	ValidatorNotInSet           = ValidatorType("NOT_IN_SET")
+)
+
+var comparators = map[ValidatorType]comparison.Comparator{
+	ValidatorLessThan:           comparison.Lt{},
+	ValidatorLessThanOrEqual:    comparison.Le{},
+	ValidatorGreaterThan:        comparison.Gt{},
+	ValidatorGreaterThanOrEqual: comparison.Ge{},
+}
+
+type Validator struct {
+	Name  string        `json:"name,omitempty"`
+	Type  ValidatorType `json:"type"`
+	Value Value         `json:"value"`
+}
+
+// Validate checks a measured value against the validator; it returns nil if the
+// value is valid, otherwise an error describing why it's not
+func (v Validator) Validate(value Value) error {
+	switch v.Type {
+	case ValidatorEqual:
+		if !reflect.DeepEqual(value, v.Value) {
+			return fmt.Errorf("%v is not equal to %v", value, v.Value)
+		}
+
+	case ValidatorNotEqual:
+		if reflect.DeepEqual(value, v.Value) {
+			return fmt.Errorf("%v is equal to %v", value, v.Value)
+		}
+
+	case ValidatorLessThan, ValidatorLessThanOrEqual, ValidatorGreaterThan, ValidatorGreaterThanOrEqual:
+		lhs, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("measured value %v is not a number", value)
+		}
+		rhs, ok := v.Value.(float64)
+		if !ok {
+			return fmt.Errorf("validator value %v is not a number", v.Value)
+		}
+		c := comparators[v.Type]
+		if !c.Compare(lhs, rhs) {
+			return fmt.Errorf("%v %s %v is false", lhs, c.Operator(), rhs)
+		}
+
+	case ValidatorRegexMatch, ValidatorRegexNoMatch:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("measured value %v is not a string", value)
+		}
+		// the validator value is either a single pattern or a list of patterns
+		patterns, ok := v.Value.([]interface{})
+		if !ok {
+			patterns = []interface{}{v.Value}
+		}
+		for _, p := range patterns {
+			pattern, ok := p.(string)
+			if !ok {
+				return fmt.Errorf("validator pattern %v is not a string", p)
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid validator pattern %q: %w", pattern, err)
+			}
+			matched := re.MatchString(s)
+			if v.Type == ValidatorRegexMatch && !matched {
+				return fmt.Errorf("%q does not match %q", s, pattern)
+			}
+			if v.Type == ValidatorRegexNoMatch && matched {
+				return fmt.Errorf("%q matches %q", s, pattern)
+			}
+		}
+
+	case ValidatorInSet, ValidatorNotInSet:
+		set, ok := v.Value.([]interface{})
+		if !ok {
+			return fmt.Errorf("validator value %v is not a list", v.Value)
+		}
+		found := false
+		for _, item := range set {
+			if reflect.DeepEqual(value, item) {
+				found = true
+				break
+			}
+		}
+		if v.Type == ValidatorInSet && !found {
+			return fmt.Errorf("%v is not in %v", value, set)
+		}
+		if v.Type == ValidatorNotInSet && found {
+			return fmt.Errorf("%v is in %v", value, set)
+		}
+
+	default:
+		return fmt.Errorf("unknown validator type %q", v.Type)
+	}
+
+	return nil
+}
+
+// validate runs all the validators on a measured value and returns the outcome of each
+// of them, along with whether the value passed all of them
+func validate(value Value, validators []Validator) ([]validatorResultPayload, bool) {
+	pass := true
+	results := make([]validatorResultPayload, 0, len(validators))
+	for _, v := range validators {
+		res := validatorResultPayload{
+			Name:  v.Name,
+			Type:  string(v.Type),
+			Value: v.Value,
+			Pass:  true,
+		}
+		if err := v.Validate(value); err != nil {
+			res.Pass = false
+			res.Message = err.Error()
+			pass = false
+		}
+		results = append(results, res)
+	}
+	return results, pass
+}
+
+func resultOf(pass bool) Result {
+	if pass {
+		return ResultPass
+	}
+	return ResultFail
+}
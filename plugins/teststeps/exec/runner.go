@@ -6,38 +6,219 @@
 package exec
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
+	"github.com/linuxboot/contest/pkg/event"
 	"github.com/linuxboot/contest/pkg/event/testevent"
 	"github.com/linuxboot/contest/pkg/target"
 	"github.com/linuxboot/contest/pkg/test"
 	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/teststeps"
 	exec_transport "github.com/linuxboot/contest/plugins/teststeps/exec/transport"
 )
 
 type outcome error
 
+// targetState is what is saved per target when the job is paused, so that the
+// step can reattach to the running process on resume
+type targetState struct {
+	Process *exec_transport.ProcessState `json:"process,omitempty"`
+
+	// Stdout is the output received but not parsed yet when the job was paused
+	Stdout []byte `json:"stdout,omitempty"`
+	// OCP is the state of the OCP output parser
+	OCP *OCPState `json:"ocp,omitempty"`
+
+	// Output is the start of the stdout received so far, kept when the
+	// expectations check it or variables are extracted from it
+	Output []byte `json:"output,omitempty"`
+
+	// ElapsedMS is how long the target ran before the pause, which counts
+	// towards the time quota
+	ElapsedMS int64 `json:"elapsed_ms,omitempty"`
+}
+
 type TargetRunner struct {
 	ts        *TestStep
 	ev        testevent.Emitter
-	stepsVars test.StepsVariablesReader
+	stepsVars test.StepsVariables
 }
 
-func NewTargetRunner(ts *TestStep, ev testevent.Emitter, stepsVars test.StepsVariablesReader) *TargetRunner {
+func NewTargetRunner(ts *TestStep, ev testevent.Emitter, stepsVars test.StepsVariables) *TargetRunner {
 	return &TargetRunner{
-		ts: ts,
-		ev: ev,
+		ts:        ts,
+		ev:        ev,
+		stepsVars: stepsVars,
+	}
+}
+
+// pendingReader keeps the data read from the underlying reader that wasn't
+// consumed yet, up to the offset last passed to consumed
+type pendingReader struct {
+	r io.Reader
+
+	buf      bytes.Buffer
+	consumed int64
+}
+
+func (pr *pendingReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.buf.Write(b[:n])
+	return n, err
+}
+
+// consume drops the data up to the given offset from the start of the reader
+func (pr *pendingReader) consume(offset int64) {
+	pr.buf.Next(int(offset - pr.consumed))
+	pr.consumed = offset
+}
+
+func (pr *pendingReader) pending() []byte {
+	return pr.buf.Bytes()
+}
+
+// outputBuffer keeps the first limit bytes of the output, the rest is dropped
+type outputBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func newOutputBuffer(output []byte, params stepParams) *outputBuffer {
+	limit := teststeps.DefaultOutputStreamConfig().MaxTotalSize
+	if params.Stream != nil && params.Stream.MaxTotalSize > 0 {
+		limit = params.Stream.MaxTotalSize
+	}
+
+	b := &outputBuffer{limit: limit}
+	b.buf.Write(output)
+	return b
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.limit - int64(b.buf.Len()); int64(n) > room {
+		b.truncated = true
+		if room < 0 {
+			room = 0
+		}
+		p = p[:room]
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// Bytes returns the output kept, warning if some of it was dropped
+func (b *outputBuffer) Bytes(ctx xcontext.Context) []byte {
+	if b.truncated {
+		ctx.Warnf("Output over %d bytes is not checked nor used for the step variables", b.limit)
+	}
+	return b.buf.Bytes()
+}
+
+// outputStreams emits the output of a process as events while it runs
+type outputStreams struct {
+	streams []*teststeps.OutputStream
+	copies  []func(ctx xcontext.Context)
+	wg      sync.WaitGroup
+}
+
+// stream returns a new output stream, which is flushed on close
+func (s *outputStreams) stream(
+	ctx xcontext.Context, ev testevent.Emitter, target *target.Target,
+	eventName event.Name, config teststeps.OutputStreamConfig,
+) *teststeps.OutputStream {
+	out := teststeps.NewOutputStream(ctx, ev, target, eventName, config)
+	s.streams = append(s.streams, out)
+	return out
+}
+
+// copy writes the output read from r to w, until EOF, once the process is started
+func (s *outputStreams) copy(r io.Reader, w io.Writer) {
+	s.copies = append(s.copies, func(ctx xcontext.Context) {
+		defer s.wg.Done()
+		if _, err := io.Copy(w, r); err != nil {
+			ctx.Warnf("failed to read process output: %v", err)
+		}
+	})
+}
+
+// start starts copying the output, it must only be called once the process
+// is started, as the output pipes are not closed otherwise
+func (s *outputStreams) start(ctx xcontext.Context) {
+	for _, c := range s.copies {
+		s.wg.Add(1)
+		go c(ctx)
+	}
+}
+
+// close waits for the output to be read, then emits what's left of it
+func (s *outputStreams) close(ctx xcontext.Context) {
+	s.wg.Wait()
+	for _, out := range s.streams {
+		if err := out.Close(); err != nil {
+			ctx.Warnf("failed to flush output stream: %v", err)
+		}
+	}
+}
+
+// stdoutWriters returns where the stdout of the process is copied to, besides
+// the OCP parser; output collects it for the expectations and outputs
+func (r *TargetRunner) stdoutWriters(
+	ctx xcontext.Context, target *target.Target, params stepParams,
+	streams *outputStreams, output *outputBuffer,
+) []io.Writer {
+	var writers []io.Writer
+	if params.Stream != nil && params.Stream.Stdout {
+		writers = append(writers, streams.stream(ctx, r.ev, target, StdoutEvent, *params.Stream))
+	}
+	if params.keepsOutput() {
+		writers = append(writers, output)
+	}
+	return writers
+}
+
+func (r *TargetRunner) newProcess(
+	ctx xcontext.Context,
+	transport exec_transport.Transport, params stepParams,
+	state *targetState,
+) (exec_transport.Process, error) {
+	if state.Process == nil {
+		return transport.NewProcess(ctx, params.Bin.Path, params.Bin.Args)
+	}
+
+	rt, ok := transport.(exec_transport.ResumableTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport %q cannot resume processes", params.Transport.Proto)
+	}
+	return rt.ResumeProcess(ctx, *state.Process)
+}
+
+// pause saves the state of the process in the target state; it returns
+// xcontext.ErrPaused, unless the process cannot be resumed
+func (r *TargetRunner) pause(proc exec_transport.Process, state *targetState) error {
+	rp, ok := proc.(exec_transport.ResumableProcess)
+	if !ok {
+		return fmt.Errorf("process paused but it cannot be resumed")
 	}
+
+	ps := rp.State()
+	state.Process = &ps
+	return xcontext.ErrPaused
 }
 
 func (r *TargetRunner) runWithOCP(
 	ctx xcontext.Context, target *target.Target,
 	transport exec_transport.Transport, params stepParams,
+	state *targetState,
 ) (outcome, error) {
-	proc, err := transport.NewProcess(ctx, params.Bin.Path, params.Bin.Args)
+	proc, err := r.newProcess(ctx, transport, params, state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proc: %w", err)
 	}
@@ -47,26 +228,61 @@ func (r *TargetRunner) runWithOCP(
 		return nil, fmt.Errorf("failed to pipe stout: %w", err)
 	}
 
+	var streams outputStreams
+	output := newOutputBuffer(state.Output, params)
+	if writers := r.stdoutWriters(ctx, target, params, &streams, output); len(writers) > 0 {
+		stdout = io.TeeReader(stdout, io.MultiWriter(writers...))
+	}
+	if params.Stream != nil && params.Stream.Stderr {
+		stderr, err := proc.StderrPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pipe stderr: %w", err)
+		}
+		streams.copy(stderr, streams.stream(ctx, r.ev, target, StderrEvent, *params.Stream))
+	}
+
 	if err := proc.Start(ctx); err != nil {
+		streams.close(ctx)
 		return nil, err
 	}
+	streams.start(ctx)
 
-	p := NewOCPEventParser(target, r.ev)
-	dec := json.NewDecoder(stdout)
+	var p *OCPEventParser
+	if state.OCP != nil {
+		p = NewOCPEventParserWithState(target, r.ev, *state.OCP)
+	} else {
+		p = NewOCPEventParser(target, r.ev)
+	}
+
+	// the output that wasn't parsed before a pause comes first
+	pr := &pendingReader{r: io.MultiReader(bytes.NewReader(state.Stdout), stdout)}
+	dec := json.NewDecoder(pr)
 	for dec.More() {
 		var root *OCPRoot
 		if err := dec.Decode(&root); err != nil {
-			ctx.Warnf("failed to decode ocp json: %w", err)
+			if !ctx.IsSignaledWith(xcontext.ErrPaused) {
+				ctx.Warnf("failed to decode ocp json: %v", err)
+			}
 			break
 		}
+		pr.consume(dec.InputOffset())
 
 		if err := p.Parse(ctx, root); err != nil {
-			ctx.Warnf("failed to parse ocp root: %w", err)
+			ctx.Warnf("failed to parse ocp root: %v", err)
 			break
 		}
 	}
+	streams.close(ctx)
+	state.Output = output.Bytes(ctx)
 
-	if err := proc.Wait(ctx); err != nil {
+	err = proc.Wait(ctx)
+	if errors.Is(err, xcontext.ErrPaused) {
+		ocpState := p.State()
+		state.OCP = &ocpState
+		state.Stdout = pr.pending()
+		return nil, r.pause(proc, state)
+	}
+	if err := params.Expect.checkExitCode(err); err != nil {
 		return fmt.Errorf("failed to wait on transport: %w", err), nil
 	}
 
@@ -76,19 +292,42 @@ func (r *TargetRunner) runWithOCP(
 func (r *TargetRunner) runAny(
 	ctx xcontext.Context, target *target.Target,
 	transport exec_transport.Transport, params stepParams,
+	state *targetState,
 ) (outcome, error) {
-	proc, err := transport.NewProcess(ctx, params.Bin.Path, params.Bin.Args)
+	resumed := state.Process != nil
+
+	proc, err := r.newProcess(ctx, transport, params, state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proc: %w", err)
 	}
 
-	var startPayload struct {
-		cmd string
+	// the start event was emitted before the pause
+	if !resumed {
+		var startPayload struct {
+			cmd string
+		}
+		startPayload.cmd = proc.String()
+
+		if err := emitEvent(ctx, TestStartEvent, startPayload, target, r.ev); err != nil {
+			return nil, fmt.Errorf("cannot emit event: %w", err)
+		}
 	}
-	startPayload.cmd = proc.String()
 
-	if err := emitEvent(ctx, TestStartEvent, startPayload, target, r.ev); err != nil {
-		return nil, fmt.Errorf("cannot emit event: %w", err)
+	var streams outputStreams
+	output := newOutputBuffer(state.Output, params)
+	if writers := r.stdoutWriters(ctx, target, params, &streams, output); len(writers) > 0 {
+		stdout, err := proc.StdoutPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pipe stdout: %w", err)
+		}
+		streams.copy(stdout, io.MultiWriter(writers...))
+	}
+	if params.Stream != nil && params.Stream.Stderr {
+		stderr, err := proc.StderrPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pipe stderr: %w", err)
+		}
+		streams.copy(stderr, streams.stream(ctx, r.ev, target, StderrEvent, *params.Stream))
 	}
 
 	// try to start the process, if that succeeds then the outcome is the result of
@@ -96,7 +335,18 @@ func (r *TargetRunner) runAny(
 	// between "an error occured while launching" and "this was the outcome of the execution"
 	outcome := proc.Start(ctx)
 	if outcome == nil {
+		streams.start(ctx)
+		// the output must be read before waiting on the process
+		streams.close(ctx)
+		state.Output = output.Bytes(ctx)
+
 		outcome = proc.Wait(ctx)
+		if errors.Is(outcome, xcontext.ErrPaused) {
+			return nil, r.pause(proc, state)
+		}
+		outcome = params.Expect.checkExitCode(outcome)
+	} else {
+		streams.close(ctx)
 	}
 
 	if err := emitEvent(ctx, TestEndEvent, nil, target, r.ev); err != nil {
@@ -109,26 +359,50 @@ func (r *TargetRunner) runAny(
 func (r *TargetRunner) run(
 	ctx xcontext.Context, target *target.Target,
 	transport exec_transport.Transport, params stepParams,
+	state *targetState,
 ) (outcome, error) {
 	if params.OCPOutput {
-		return r.runWithOCP(ctx, target, transport, params)
+		return r.runWithOCP(ctx, target, transport, params, state)
 	}
 
-	return r.runAny(ctx, target, transport, params)
+	return r.runAny(ctx, target, transport, params, state)
 }
 
-func (r *TargetRunner) Run(ctx xcontext.Context, target *target.Target) error {
-	ctx.Infof("Executing on target %s", target)
+// remainingTimeQuota is what is left of the time quota of the step, once the
+// time the target ran before a pause is taken out; zero means no limit
+func (r *TargetRunner) remainingTimeQuota(state *targetState) time.Duration {
+	timeQuota := time.Duration(r.ts.Constraints.TimeQuota)
+	if timeQuota == 0 {
+		return 0
+	}
+	remaining := timeQuota - time.Duration(state.ElapsedMS)*time.Millisecond
+	if remaining <= 0 {
+		// the quota ran out before the pause, time out right away
+		return time.Nanosecond
+	}
+	return remaining
+}
 
-	// limit the execution time if specified
-	timeQuota := r.ts.Constraints.TimeQuota
-	if timeQuota != 0 {
+func (r *TargetRunner) Run(ctx xcontext.Context, target *teststeps.TargetWithData) error {
+	ctx.Infof("Executing on target %s", target.Target)
+
+	// a target has data if it was paused while its process was running
+	var state targetState
+	if target.Data != nil {
+		if err := json.Unmarshal(target.Data, &state); err != nil {
+			return fmt.Errorf("invalid resume state: %w", err)
+		}
+	}
+
+	// limit the execution time if specified, the time spent paused does not count
+	start := time.Now()
+	if timeQuota := r.remainingTimeQuota(&state); timeQuota != 0 {
 		var cancel xcontext.CancelFunc
-		ctx, cancel = xcontext.WithTimeout(ctx, time.Duration(timeQuota))
+		ctx, cancel = xcontext.WithTimeout(ctx, timeQuota)
 		defer cancel()
 	}
 
-	pe := test.NewParamExpander(target, r.stepsVars)
+	pe := test.NewParamExpander(target.Target, r.stepsVars)
 
 	var params stepParams
 	if err := pe.ExpandObject(r.ts.stepParams, &params); err != nil {
@@ -142,8 +416,17 @@ func (r *TargetRunner) Run(ctx xcontext.Context, target *target.Target) error {
 
 	// for any ambiguity, outcome is an error interface, but it encodes whether the process
 	// was launched sucessfully and it resulted in a failure; err means the launch failed
-	outcome, err := r.run(ctx, target, transport, params)
+	outcome, err := r.run(ctx, target.Target, transport, params, &state)
 
+	if err == xcontext.ErrPaused {
+		ctx.Debugf("Paused while the process is running on target %s", target.Target)
+		state.ElapsedMS += time.Since(start).Milliseconds()
+		target.Data, err = json.Marshal(&state)
+		if err != nil {
+			return fmt.Errorf("cannot marshal resume state: %w", err)
+		}
+		return xcontext.ErrPaused
+	}
 	if err != nil {
 		return err
 	}
@@ -156,5 +439,15 @@ func (r *TargetRunner) Run(ctx xcontext.Context, target *target.Target) error {
 			return fmt.Errorf("exit code mapped error: %s", mappedError)
 		}
 	}
-	return outcome
+	if outcome != nil {
+		return outcome
+	}
+
+	if err := params.Expect.checkOutput(state.Output); err != nil {
+		return err
+	}
+	if err := params.Expect.variables().Publish(r.stepsVars, target.Target.ID, state.Output); err != nil {
+		return err
+	}
+	return params.Outputs.Publish(r.stepsVars, target.Target.ID, state.Output)
 }
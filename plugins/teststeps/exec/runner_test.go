@@ -0,0 +1,243 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package exec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/xjson"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/plugins/teststeps"
+	exec_transport "github.com/linuxboot/contest/plugins/teststeps/exec/transport"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcess outputs stdout and stderr then exits with the wait error,
+// unless it fails to start
+type fakeProcess struct {
+	stdout   string
+	stderr   string
+	startErr error
+	waitErr  error
+	state    exec_transport.ProcessState
+}
+
+func (p *fakeProcess) Start(ctx xcontext.Context) error { return p.startErr }
+func (p *fakeProcess) Wait(ctx xcontext.Context) error  { return p.waitErr }
+func (p *fakeProcess) StdoutPipe() (io.Reader, error) {
+	return p.pipe(p.stdout), nil
+}
+func (p *fakeProcess) StderrPipe() (io.Reader, error) {
+	return p.pipe(p.stderr), nil
+}
+
+// pipe returns a reader of the output, which blocks forever if the process
+// was not started, like the pipes of a process that is not running
+func (p *fakeProcess) pipe(output string) io.Reader {
+	if p.startErr != nil {
+		r, _ := io.Pipe()
+		return r
+	}
+	return strings.NewReader(output)
+}
+func (p *fakeProcess) String() string                     { return "fake" }
+func (p *fakeProcess) State() exec_transport.ProcessState { return p.state }
+
+// fakeTransport returns its process on start and its resumed process on resume
+type fakeTransport struct {
+	process *fakeProcess
+	resumed *fakeProcess
+
+	resumeState *exec_transport.ProcessState
+}
+
+func (t *fakeTransport) NewProcess(ctx xcontext.Context, bin string, args []string) (exec_transport.Process, error) {
+	return t.process, nil
+}
+
+func (t *fakeTransport) ResumeProcess(ctx xcontext.Context, state exec_transport.ProcessState) (exec_transport.Process, error) {
+	t.resumeState = &state
+	return t.resumed, nil
+}
+
+func TestRunWithOCPResume(t *testing.T) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	transport := &fakeTransport{
+		// paused in the middle of the diagnosis
+		process: &fakeProcess{
+			stdout: `{"testRunArtifact":{"testRunStart":{"name":"Memory"}},"sequenceNumber":1,"timestamp":"ts"}
+{"testStepArtifact":{"testStepId":"0","diagnosis":{"verdict":"mem-dimm-bad","ty`,
+			waitErr: xcontext.ErrPaused,
+			state:   exec_transport.ProcessState{SessionID: "42", Agent: "/tmp/agent"},
+		},
+		resumed: &fakeProcess{
+			stdout: `pe":"FAIL"}},"sequenceNumber":2,"timestamp":"ts"}
+{"testRunArtifact":{"testRunEnd":{"name":"Memory","status":"COMPLETE","result":"PASS"}},"sequenceNumber":3,"timestamp":"ts"}`,
+		},
+	}
+	params := stepParams{OCPOutput: true}
+
+	r := NewTargetRunner(&TestStep{}, ev, nil)
+
+	var state targetState
+	_, err := r.run(ctx, nil, transport, params, &state)
+	require.Equal(t, xcontext.ErrPaused, err)
+	require.Equal(t, 1, len(ev.Calls))
+	require.NotNil(t, state.Process)
+	require.Equal(t, "42", state.Process.SessionID)
+	require.Contains(t, string(state.Stdout), "mem-dimm-bad")
+
+	// the state goes thru serialization in the resume state
+	data, err := json.Marshal(&state)
+	require.NoError(t, err)
+	var resumeState targetState
+	require.NoError(t, json.Unmarshal(data, &resumeState))
+
+	outcome, err := r.run(ctx, nil, transport, params, &resumeState)
+	require.NoError(t, err)
+	require.Equal(t, "42", transport.resumeState.SessionID)
+	require.Equal(t, 3, len(ev.Calls))
+	require.Equal(t, DiagnosisEvent, ev.Calls[1].EventName)
+
+	// the diagnosis split by the pause fails the test
+	require.Error(t, outcome)
+	require.Contains(t, outcome.Error(), "mem-dimm-bad")
+}
+
+func TestRunStream(t *testing.T) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	transport := &fakeTransport{
+		process: &fakeProcess{
+			stdout: `{"testRunArtifact":{"testRunEnd":{"name":"Memory","status":"COMPLETE","result":"PASS"}},"sequenceNumber":1,"timestamp":"ts"}`,
+			stderr: "first\nsecond\n",
+		},
+	}
+	params := stepParams{
+		OCPOutput: true,
+		Stream:    &teststeps.OutputStreamConfig{Stderr: true, RateLimit: 1000},
+	}
+
+	r := NewTargetRunner(&TestStep{}, ev, nil)
+
+	var state targetState
+	outcome, err := r.run(ctx, nil, transport, params, &state)
+	require.NoError(t, err)
+	require.NoError(t, outcome)
+
+	var lines []string
+	for _, call := range ev.Calls {
+		if call.EventName != StderrEvent {
+			continue
+		}
+		var chunk teststeps.OutputChunk
+		require.NoError(t, json.Unmarshal(*call.Payload, &chunk))
+		lines = append(lines, chunk.Msg)
+	}
+	require.Equal(t, "first\nsecond\n", strings.Join(lines, ""))
+}
+
+func TestRunExpect(t *testing.T) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	transport := &fakeTransport{
+		process: &fakeProcess{
+			stdout:  "version 5.12\n",
+			waitErr: &exec_transport.ExitError{ExitCode: 3},
+		},
+	}
+	params := stepParams{Expect: parseExpectations(t, `{"exit_codes": [3], "match": ["version (?P<major>\\d+)"]}`)}
+
+	r := NewTargetRunner(&TestStep{}, ev, nil)
+
+	var state targetState
+	outcome, err := r.run(ctx, nil, transport, params, &state)
+	require.NoError(t, err)
+	require.NoError(t, outcome)
+	require.Equal(t, "version 5.12\n", string(state.Output))
+
+	require.NoError(t, params.Expect.checkOutput(state.Output))
+	vars, err := params.Expect.variables().Extract(state.Output)
+	require.NoError(t, err)
+	require.Equal(t, "5", vars["major"])
+}
+
+func TestRunOutputLimit(t *testing.T) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	transport := &fakeTransport{process: &fakeProcess{stdout: "0123456789"}}
+	params := stepParams{
+		Stream:  &teststeps.OutputStreamConfig{MaxTotalSize: 4},
+		Outputs: teststeps.Outputs{"digits": {Regex: "\\d+"}},
+	}
+
+	r := NewTargetRunner(&TestStep{}, ev, nil)
+
+	// the output kept after a pause counts towards the limit
+	state := targetState{Output: []byte("ab")}
+	outcome, err := r.run(ctx, nil, transport, params, &state)
+	require.NoError(t, err)
+	require.NoError(t, outcome)
+	require.Equal(t, "ab01", string(state.Output))
+}
+
+func TestRunStartFailure(t *testing.T) {
+	ctx := xcontext.Background()
+
+	ev := &mockEmitter{}
+	ev.On("Emit", ctx, mock.Anything).Return(nil)
+
+	startErr := errors.New("no such file")
+	transport := &fakeTransport{process: &fakeProcess{startErr: startErr}}
+	stream := &teststeps.OutputStreamConfig{Stdout: true, Stderr: true}
+
+	r := NewTargetRunner(&TestStep{}, ev, nil)
+	for _, params := range []stepParams{
+		{Stream: stream},
+		{Stream: stream, OCPOutput: true},
+	} {
+		var state targetState
+		outcome, err := r.run(ctx, nil, transport, params, &state)
+		if params.OCPOutput {
+			require.Equal(t, startErr, err)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, startErr, outcome)
+		}
+	}
+}
+
+func TestRemainingTimeQuota(t *testing.T) {
+	ts := &TestStep{}
+	r := NewTargetRunner(ts, nil, nil)
+	require.Zero(t, r.remainingTimeQuota(&targetState{ElapsedMS: 1000}))
+
+	ts.Constraints.TimeQuota = xjson.Duration(time.Minute)
+	require.Equal(t, time.Minute, r.remainingTimeQuota(&targetState{}))
+	// the time run before a pause is taken out of the quota
+	require.Equal(t, 45*time.Second, r.remainingTimeQuota(&targetState{ElapsedMS: 15000}))
+	// a quota used up before the pause times out right away
+	require.Equal(t, time.Nanosecond, r.remainingTimeQuota(&targetState{ElapsedMS: 60000}))
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/insomniacslk/xjson"
@@ -26,6 +27,13 @@ type sshProcessAsync struct {
 	cmd          string
 	agent        string
 
+	// files are removed from the remote once the process ended
+	files []string
+
+	// sid is set when reattaching to a process started before a pause
+	sid string
+	mon *asyncMonitor
+
 	outWriter io.WriteCloser
 	errWriter io.WriteCloser
 
@@ -38,15 +46,19 @@ type sshProcessAsync struct {
 func newSSHProcessAsync(
 	ctx xcontext.Context,
 	addr string, clientConfig *ssh.ClientConfig,
-	agent string, timeQuota xjson.Duration,
+	agent string, timeQuota xjson.Duration, maxOutput int64,
 	bin string, args []string,
+	files []string,
 	stack *deferedStack,
 ) (Process, error) {
-	// build the command to run remotely
-	agentArgs := []string{agent}
+	// build the command to run remotely, in a session of the agent daemon
+	agentArgs := []string{agent, "--daemon"}
 	if timeQuota != 0 {
 		agentArgs = append(agentArgs, fmt.Sprintf("--time-quota=%s", timeQuota.String()))
 	}
+	if maxOutput != 0 {
+		agentArgs = append(agentArgs, fmt.Sprintf("--max-output=%d", maxOutput))
+	}
 	agentArgs = append(agentArgs, "start", bin)
 	agentArgs = append(agentArgs, args...)
 
@@ -58,13 +70,51 @@ func newSSHProcessAsync(
 		clientConfig: clientConfig,
 		cmd:          cmd,
 		agent:        agent,
+		files:        files,
 		closeOnWait:  []io.Closer{},
 		exitChan:     exitChan,
 		stack:        stack,
 	}, nil
 }
 
+func resumeSSHProcessAsync(
+	ctx xcontext.Context,
+	addr string, clientConfig *ssh.ClientConfig,
+	state ProcessState,
+	stack *deferedStack,
+) (Process, error) {
+	if state.SessionID == "" || state.Agent == "" {
+		return nil, fmt.Errorf("invalid process state, missing session id or agent")
+	}
+
+	return &sshProcessAsync{
+		addr:         addr,
+		clientConfig: clientConfig,
+		cmd:          shellquote.Join(state.Agent, "--daemon", "poll", state.SessionID),
+		agent:        state.Agent,
+		files:        state.Files,
+		sid:          state.SessionID,
+		mon: &asyncMonitor{
+			addr:         addr,
+			clientConfig: clientConfig,
+			agent:        state.Agent,
+			sid:          state.SessionID,
+			stdoutOffset: state.StdoutOffset,
+			stderrOffset: state.StderrOffset,
+		},
+		closeOnWait: []io.Closer{},
+		exitChan:    make(chan error, 1),
+		stack:       stack,
+	}, nil
+}
+
 func (spa *sshProcessAsync) Start(ctx xcontext.Context) error {
+	if spa.mon != nil {
+		// the agent is already running the process, just resume monitoring it
+		ctx.Debugf("reattaching to remote sid: %s", spa.sid)
+		return spa.startMonitor(ctx)
+	}
+
 	errChan := make(chan error, 1)
 	resChan := make(chan string, 1)
 
@@ -112,27 +162,14 @@ func (spa *sshProcessAsync) Start(ctx xcontext.Context) error {
 	case sid := <-resChan:
 		ctx.Debugf("remote sid: %s", sid)
 
-		outWriter := spa.outWriter
-		if outWriter == nil {
-			var err error
-			outWriter, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
-			if err != nil {
-				return err
-			}
-		}
-
-		errWriter := spa.errWriter
-		if errWriter == nil {
-			var err error
-			errWriter, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
-			if err != nil {
-				return err
-			}
+		spa.sid = sid
+		spa.mon = &asyncMonitor{
+			addr:         spa.addr,
+			clientConfig: spa.clientConfig,
+			agent:        spa.agent,
+			sid:          sid,
 		}
-
-		mon := &asyncMonitor{spa.addr, spa.clientConfig, spa.agent, sid}
-		go mon.Start(ctx, outWriter, errWriter, spa.exitChan)
-		return nil
+		return spa.startMonitor(ctx)
 
 	case <-time.After(5 * time.Second):
 		return fmt.Errorf("timeout while starting agent")
@@ -142,12 +179,46 @@ func (spa *sshProcessAsync) Start(ctx xcontext.Context) error {
 	}
 }
 
-func (spa *sshProcessAsync) Wait(_ xcontext.Context) error {
+func (spa *sshProcessAsync) startMonitor(ctx xcontext.Context) error {
+	outWriter := spa.outWriter
+	if outWriter == nil {
+		var err error
+		outWriter, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	errWriter := spa.errWriter
+	if errWriter == nil {
+		var err error
+		errWriter, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	go spa.mon.Start(ctx, outWriter, errWriter, spa.exitChan)
+	return nil
+}
+
+func (spa *sshProcessAsync) Wait(ctx xcontext.Context) error {
 	defer spa.stack.Done()
 
 	// wait for process
 	err := <-spa.exitChan
 
+	if errors.Is(err, xcontext.ErrPaused) {
+		// the process keeps running remotely, so its files are kept too
+		return err
+	}
+
+	if len(spa.files) > 0 {
+		if err := spa.cleanup(ctx); err != nil {
+			ctx.Warnf("failed to cleanup remote files: %v", err)
+		}
+	}
+
 	var e *ssh.ExitError
 	if errors.As(err, &e) {
 		return fmt.Errorf("process exited with error: %w", e)
@@ -179,12 +250,43 @@ func (spa *sshProcessAsync) String() string {
 	return spa.cmd
 }
 
+// State returns what is needed to reattach to the process, it must only be
+// called after Wait returned
+func (spa *sshProcessAsync) State() ProcessState {
+	state := ProcessState{
+		SessionID: spa.sid,
+		Agent:     spa.agent,
+		Files:     spa.files,
+	}
+	if spa.mon != nil {
+		state.StdoutOffset = spa.mon.stdoutOffset
+		state.StderrOffset = spa.mon.stderrOffset
+	}
+	return state
+}
+
+func (spa *sshProcessAsync) cleanup(ctx xcontext.Context) error {
+	ctx.Debugf("cleaning remote files: %v", spa.files)
+
+	client, err := ssh.Dial("tcp", spa.addr, spa.clientConfig)
+	if err != nil {
+		return fmt.Errorf("cannot connect to SSH server %s: %v", spa.addr, err)
+	}
+	defer client.Close()
+
+	return unlinkFiles(client, spa.files)
+}
+
 type asyncMonitor struct {
 	addr         string
 	clientConfig *ssh.ClientConfig
 
 	agent string
 	sid   string
+
+	// count the output bytes received so far
+	stdoutOffset int64
+	stderrOffset int64
 }
 
 func (m *asyncMonitor) Start(
@@ -195,12 +297,15 @@ func (m *asyncMonitor) Start(
 	defer outWriter.Close()
 	defer errWriter.Close()
 
+	paused := ctx.Until(xcontext.ErrPaused)
 	for {
 		select {
 		case <-time.After(time.Second):
 			ctx.Debugf("polling remote process: %s", m.sid)
 
-			stdout, err, runerr := m.runAgent(ctx, "poll")
+			// the daemon returns the output after what was already received
+			stdout, err, runerr := m.runAgent(ctx, "poll",
+				strconv.FormatInt(m.stdoutOffset, 10), strconv.FormatInt(m.stderrOffset, 10))
 			if err != nil {
 				ctx.Warnf("failed to run agent: %w", err)
 				continue
@@ -222,19 +327,25 @@ func (m *asyncMonitor) Start(
 			}
 
 			// append stdout, stderr; blocking until read
+			m.stdoutOffset += int64(len(msg.Stdout))
 			if _, err := outWriter.Write([]byte(msg.Stdout)); err != nil {
 				ctx.Warnf("failed to write to stdout pipe: %w", err)
 				continue
 			}
 
+			m.stderrOffset += int64(len(msg.Stderr))
 			if _, err := errWriter.Write([]byte(msg.Stderr)); err != nil {
 				ctx.Warnf("failed to write to stderr pipe: %w", err)
 				continue
 			}
 
 			if msg.Error != "" {
-				// remote agent killed itself due to time quota or other error
-				exitChan <- fmt.Errorf(msg.Error)
+				// remote process exceeded the time quota, or the agent daemon died
+				if err := m.reap(ctx); err != nil {
+					ctx.Warnf("monitor error: %v", err)
+				}
+
+				exitChan <- errors.New(msg.Error)
 				return
 			}
 
@@ -253,6 +364,13 @@ func (m *asyncMonitor) Start(
 				return
 			}
 
+		case <-paused:
+			// leave the remote process running, it's reattached to on resume
+			ctx.Debugf("detaching from remote process %s, reason: pause", m.sid)
+
+			exitChan <- xcontext.ErrPaused
+			return
+
 		case <-ctx.Done():
 			ctx.Debugf("killing remote process, reason: cancellation")
 
@@ -296,7 +414,7 @@ func (m *asyncMonitor) reap(ctx xcontext.Context) error {
 	return nil
 }
 
-func (m *asyncMonitor) runAgent(ctx xcontext.Context, verb string) ([]byte, error, error) {
+func (m *asyncMonitor) runAgent(ctx xcontext.Context, verb string, args ...string) ([]byte, error, error) {
 	client, err := ssh.Dial("tcp", m.addr, m.clientConfig)
 	if err != nil {
 		return nil, fmt.Errorf("cannot connect to SSH server %s: %v", m.addr, err), nil
@@ -313,7 +431,7 @@ func (m *asyncMonitor) runAgent(ctx xcontext.Context, verb string) ([]byte, erro
 	session.Stdout = &stdout
 	session.Stderr = &stderr
 
-	cmd := shellquote.Join(m.agent, verb, m.sid)
+	cmd := shellquote.Join(append([]string{m.agent, "--daemon", verb, m.sid}, args...)...)
 	ctx.Debugf("starting agent command: %s", cmd)
 	if err := session.Start(cmd); err != nil {
 		return nil, fmt.Errorf("failed to start remote agent: %w", err), nil
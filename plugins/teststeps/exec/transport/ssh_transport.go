@@ -34,6 +34,7 @@ type SSHTransportConfig struct {
 	Async *struct {
 		Agent     string         `json:"agent,omitempty"`
 		TimeQuota xjson.Duration `json:"time_quota,omitempty"`
+		MaxOutput int64          `json:"max_output,omitempty"`
 	} `json:"async,omitempty"`
 }
 
@@ -52,7 +53,7 @@ func NewSSHTransport(config SSHTransportConfig) Transport {
 	return &SSHTransport{config}
 }
 
-func (st *SSHTransport) NewProcess(ctx xcontext.Context, bin string, args []string) (Process, error) {
+func (st *SSHTransport) clientConfig() (*ssh.ClientConfig, error) {
 	var signer ssh.Signer
 	if st.IdentityFile != "" {
 		key, err := os.ReadFile(st.IdentityFile)
@@ -73,15 +74,22 @@ func (st *SSHTransport) NewProcess(ctx xcontext.Context, bin string, args []stri
 		auth = append(auth, ssh.Password(st.Password))
 	}
 
-	addr := net.JoinHostPort(st.Host, strconv.Itoa(st.Port))
-	clientConfig := &ssh.ClientConfig{
+	return &ssh.ClientConfig{
 		User: st.User,
 		Auth: auth,
 		// TODO expose this in the plugin arguments
 		//HostKeyCallback: ssh.FixedHostKey(hostKey),
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 		Timeout:         time.Duration(st.Timeout),
+	}, nil
+}
+
+func (st *SSHTransport) NewProcess(ctx xcontext.Context, bin string, args []string) (Process, error) {
+	clientConfig, err := st.clientConfig()
+	if err != nil {
+		return nil, err
 	}
+	addr := net.JoinHostPort(st.Host, strconv.Itoa(st.Port))
 
 	// stack mechanism similar to defer, but run after the exec process ends
 	stack := newDeferedStack()
@@ -108,6 +116,12 @@ func (st *SSHTransport) NewProcess(ctx xcontext.Context, bin string, args []stri
 			return nil, fmt.Errorf("cannot send binary to remote ssh: %w", err)
 		}
 
+		// the async processes outlive this client when the job is paused,
+		// so they cleanup the sent file themselves
+		if st.Async != nil {
+			return st.newAsync(ctx, client, addr, clientConfig, bin, args, []string{bin}, stack)
+		}
+
 		// cleanup the sent file so we don't leave hanging files around
 		stack.Add(func() {
 			ctx.Debugf("cleaning remote file: %s", bin)
@@ -118,11 +132,26 @@ func (st *SSHTransport) NewProcess(ctx xcontext.Context, bin string, args []stri
 	}
 
 	if st.Async != nil {
-		return st.newAsync(ctx, client, addr, clientConfig, bin, args, stack)
+		return st.newAsync(ctx, client, addr, clientConfig, bin, args, nil, stack)
 	}
 	return st.new(ctx, client, bin, args, stack)
 }
 
+// ResumeProcess reattaches to an async process that kept running while the job was paused
+func (st *SSHTransport) ResumeProcess(ctx xcontext.Context, state ProcessState) (Process, error) {
+	if st.Async == nil {
+		return nil, fmt.Errorf("only async ssh processes can be resumed")
+	}
+
+	clientConfig, err := st.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	addr := net.JoinHostPort(st.Host, strconv.Itoa(st.Port))
+
+	return resumeSSHProcessAsync(ctx, addr, clientConfig, state, newDeferedStack())
+}
+
 func (st *SSHTransport) new(ctx xcontext.Context, client *ssh.Client, bin string, args []string, stack *deferedStack) (Process, error) {
 	return newSSHProcess(ctx, client, bin, args, stack)
 }
@@ -131,6 +160,7 @@ func (st *SSHTransport) newAsync(
 	ctx xcontext.Context,
 	client *ssh.Client, addr string, clientConfig *ssh.ClientConfig,
 	bin string, args []string,
+	files []string,
 	stack *deferedStack,
 ) (Process, error) {
 	// we always need the agent for the async case
@@ -139,14 +169,9 @@ func (st *SSHTransport) newAsync(
 		return nil, fmt.Errorf("failed to send agent: %w", err)
 	}
 
-	stack.Add(func() {
-		ctx.Debugf("cleaning async agent: %s", agent)
-		if err := st.unlinkFile(ctx, client, agent); err != nil {
-			ctx.Warnf("failed to cleanup asyng agent: %w", err)
-		}
-	})
-
-	return newSSHProcessAsync(ctx, addr, clientConfig, agent, st.Async.TimeQuota, bin, args, stack)
+	// the agent is cleaned up by the process, after the files it runs
+	files = append([]string{agent}, files...)
+	return newSSHProcessAsync(ctx, addr, clientConfig, agent, st.Async.TimeQuota, st.Async.MaxOutput, bin, args, files, stack)
 }
 
 func (st *SSHTransport) sendFile(ctx xcontext.Context, client *ssh.Client, bin string, mode os.FileMode) (string, error) {
@@ -179,11 +204,20 @@ func (st *SSHTransport) sendFile(ctx xcontext.Context, client *ssh.Client, bin s
 }
 
 func (st *SSHTransport) unlinkFile(ctx xcontext.Context, client *ssh.Client, bin string) error {
+	return unlinkFiles(client, []string{bin})
+}
+
+func unlinkFiles(client *ssh.Client, files []string) error {
 	sftp, err := sftp.NewClient(client)
 	if err != nil {
 		return fmt.Errorf("failed to create sftp client: %w", err)
 	}
 	defer sftp.Close()
 
-	return sftp.Remove(bin)
+	for _, f := range files {
+		if err := sftp.Remove(f); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f, err)
+		}
+	}
+	return nil
 }
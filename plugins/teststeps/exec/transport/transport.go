@@ -38,6 +38,38 @@ type Process interface {
 	String() string
 }
 
+// ProcessState identifies a process that kept running while the job was paused,
+// along with what is needed to reattach to it when the job is resumed
+type ProcessState struct {
+	SessionID string `json:"sid"`
+	Agent     string `json:"agent"`
+
+	// Files are the remote files to remove once the process ended
+	Files []string `json:"files,omitempty"`
+
+	// StdoutOffset and StderrOffset count the output bytes received from the process
+	StdoutOffset int64 `json:"stdout_offset"`
+	StderrOffset int64 `json:"stderr_offset"`
+}
+
+// ResumableProcess is a Process that survives a pause of the job. Its Wait method
+// returns xcontext.ErrPaused when the job is paused, after which State returns
+// what is needed to reattach to the process
+type ResumableProcess interface {
+	Process
+
+	State() ProcessState
+}
+
+// ResumableTransport is a Transport that can reattach to a process that was started
+// before the job was paused; the returned Process is started by its Start method
+// without launching the binary again
+type ResumableTransport interface {
+	Transport
+
+	ResumeProcess(ctx xcontext.Context, state ProcessState) (Process, error)
+}
+
 func NewTransport(proto string, configSource json.RawMessage, expander *test.ParamExpander) (Transport, error) {
 	switch proto {
 	case "local":
@@ -38,8 +38,9 @@ var Events = []event.Name{
 
 // eventCmdStartPayload is the payload for EventCmdStart
 type eventCmdStartPayload struct {
-	Path string
-	Args []string
+	Path    string
+	Args    []string
+	Targets []string
 }
 
 // eventCmdEndPayload is the payload for EventCmdEnd
@@ -49,7 +50,8 @@ type eventCmdEndPayload struct {
 }
 
 // GatherCmd is used to run arbitrary commands as test steps but only once
-// for all the targets. This can be used as test setup/teardown.
+// for all the targets. This can be used as test setup/teardown. It is a group
+// step: if the command fails, all the targets fail.
 type GatherCmd struct {
 	// binary to execute; will consult $PATH
 	binary string
@@ -58,6 +60,8 @@ type GatherCmd struct {
 	args []string
 }
 
+var _ test.GroupTestStep = (*GatherCmd)(nil)
+
 // Name returns the plugin name.
 func (ts GatherCmd) Name() string {
 	return Name
@@ -96,36 +100,7 @@ func truncate(in string, maxsize uint) string {
 	return in[:size]
 }
 
-func (ts *GatherCmd) acquireTargets(ctx xcontext.Context, ch test.TestStepChannels) ([]*target.Target, error) {
-	var targets []*target.Target
-
-	for {
-		select {
-		case target, ok := <-ch.In:
-			if !ok {
-				ctx.Debugf("acquired %d targets", len(targets))
-				return targets, nil
-			}
-			targets = append(targets, target)
-
-		case <-ctx.Until(xcontext.ErrPaused):
-			ctx.Debugf("paused during target acquisition, acquired %d", len(targets))
-			return nil, xcontext.ErrPaused
-
-		case <-ctx.Done():
-			ctx.Debugf("canceled during target acquisition, acquired %d", len(targets))
-			return nil, ctx.Err()
-		}
-	}
-}
-
-func (ts *GatherCmd) returnTargets(ctx xcontext.Context, ch test.TestStepChannels, targets []*target.Target) {
-	for _, target := range targets {
-		ch.Out <- test.TestStepResult{Target: target}
-	}
-}
-
-// Run executes the step
+// Run is not called for group steps, the TestRunner calls RunGroup instead.
 func (ts *GatherCmd) Run(
 	ctx xcontext.Context,
 	ch test.TestStepChannels,
@@ -134,28 +109,27 @@ func (ts *GatherCmd) Run(
 	params test.TestStepParameters,
 	resumeState json.RawMessage,
 ) (json.RawMessage, error) {
-	log := ctx.Logger()
+	return nil, fmt.Errorf("%s is a group step, it can only be run with RunGroup", Name)
+}
 
-	if err := ts.setParams(params); err != nil {
-		return nil, err
-	}
+// WaitsForAllTargets marks the step as a rendezvous, as all group steps
+func (ts *GatherCmd) WaitsForAllTargets() {}
 
-	// acquire all targets and hold them hostage until the cmd is done
-	targets, err := ts.acquireTargets(ctx, ch)
-	if err != nil {
-		return nil, err
-	}
-	defer ts.returnTargets(ctx, ch, targets)
+// RunGroup executes the step once for all the targets
+func (ts *GatherCmd) RunGroup(
+	ctx xcontext.Context,
+	targets []*target.Target,
+	emitter testevent.Emitter,
+	stepsVars test.StepsVariables,
+	params test.TestStepParameters,
+	resumeState json.RawMessage,
+) (test.TestStepGroupResults, json.RawMessage, error) {
+	log := ctx.Logger()
 
-	if len(targets) == 0 {
-		return nil, nil
+	if err := ts.setParams(params); err != nil {
+		return nil, nil, err
 	}
 
-	// arbitrarily choose first target to associate events with, anyone would work
-	// but it is unnecessary to have the same event on all targets since this is a
-	// "gather" type plugin
-	eventTarget := targets[0]
-
 	// used to manually cancel the exec if step becomes paused
 	ctx, cancel := xcontext.WithCancel(ctx)
 	defer cancel()
@@ -182,11 +156,17 @@ func (ts *GatherCmd) Run(
 
 	log.Debugf("Running command: %+v", cmd)
 
-	err = emitEvent(
+	targetIDs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		targetIDs = append(targetIDs, target.ID)
+	}
+
+	// the events are about all the targets, so they are not associated with any
+	err := emitEvent(
 		ctx,
-		emitter, eventTarget,
+		emitter, nil,
 		EventCmdStart,
-		eventCmdStartPayload{Path: cmd.Path, Args: cmd.Args},
+		eventCmdStartPayload{Path: cmd.Path, Args: cmd.Args, Targets: targetIDs},
 	)
 	if err != nil {
 		log.Warnf("Failed to emit event: %v", err)
@@ -195,8 +175,8 @@ func (ts *GatherCmd) Run(
 	if cmdErr := cmd.Run(); cmdErr != nil {
 		// the command may have been canceled as a result of the step pausing
 		if ctx.IsSignaledWith(xcontext.ErrPaused) {
-			// nothing to save, just continue pausing
-			return nil, xcontext.ErrPaused
+			// nothing to save, the command runs again on resume
+			return nil, nil, xcontext.ErrPaused
 		}
 
 		// output the failure event
@@ -216,19 +196,24 @@ func (ts *GatherCmd) Run(
 			}
 		}
 
-		if err := emitEvent(ctx, emitter, eventTarget, EventCmdEnd, payload); err != nil {
+		if err := emitEvent(ctx, emitter, nil, EventCmdEnd, payload); err != nil {
 			log.Warnf("Failed to emit event: %v", err)
 		}
 
-		return nil, cmdErr
+		// the command is run for all the targets, so they all fail
+		results := make(test.TestStepGroupResults, len(targets))
+		for _, target := range targets {
+			results[target.ID] = cmdErr
+		}
+		return results, nil, nil
 	}
 
-	if err := emitEvent(ctx, emitter, eventTarget, EventCmdEnd, nil); err != nil {
+	if err := emitEvent(ctx, emitter, nil, EventCmdEnd, nil); err != nil {
 		log.Warnf("Failed to emit event: %v", err)
 	}
 
 	// TODO: make step output with stdout/err when PR #83 gets merged
-	return nil, nil
+	return nil, nil, nil
 }
 
 func (ts *GatherCmd) setParams(params test.TestStepParameters) error {
@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package teststeps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a parsed JSONPath expression; its elements are object member
+// names as strings and array indexes as ints
+type JSONPath []interface{}
+
+// ParseJSONPath parses the subset of JSONPath made of a "$" root followed by
+// ".name", "['name']" and "[index]" elements
+func ParseJSONPath(path string) (JSONPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+
+	var elems JSONPath
+	for rest := path[1:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty name at %q", rest)
+			}
+			elems = append(elems, name)
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ at %q", rest)
+			}
+			sel := rest[1:end]
+			if len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0] {
+				elems = append(elems, sel[1:len(sel)-1])
+			} else {
+				index, err := strconv.Atoi(sel)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %q", sel)
+				}
+				elems = append(elems, index)
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("unexpected %q", rest)
+		}
+	}
+	return elems, nil
+}
+
+// Lookup returns the value at the path in a document decoded by encoding/json
+func (p JSONPath) Lookup(doc interface{}) (interface{}, error) {
+	value := doc
+	for _, elem := range p {
+		switch elem := elem.(type) {
+		case string:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot get %q of a non object", elem)
+			}
+			if value, ok = obj[elem]; !ok {
+				return nil, fmt.Errorf("no %q in object", elem)
+			}
+
+		case int:
+			arr, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot get [%d] of a non array", elem)
+			}
+			if elem >= len(arr) {
+				return nil, fmt.Errorf("index %d out of array of %d", elem, len(arr))
+			}
+			value = arr[elem]
+		}
+	}
+	return value, nil
+}
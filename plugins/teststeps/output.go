@@ -0,0 +1,301 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package teststeps
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event"
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+// output stream modes
+const (
+	// OutputStreamLine emits events with whole lines only, except for lines
+	// longer than the maximum event size which are split
+	OutputStreamLine = "line"
+	// OutputStreamChunk emits events with whatever output is available
+	OutputStreamChunk = "chunk"
+)
+
+// OutputStreamConfig configures how the output of a process is streamed into test events
+type OutputStreamConfig struct {
+	Stdout bool `json:"stdout,omitempty"`
+	Stderr bool `json:"stderr,omitempty"`
+
+	Mode string `json:"mode,omitempty"`
+	// MaxEventSize is the maximum number of output bytes in a single event
+	MaxEventSize int `json:"max_event_size,omitempty"`
+	// MaxTotalSize is the maximum number of output bytes emitted as events for
+	// each stream; the output over it is dropped or written to the overflow file
+	MaxTotalSize int64 `json:"max_total_size,omitempty"`
+	// RateLimit is the maximum number of events per second for each stream, the
+	// output is merged into fewer events when it's produced faster
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	// OverflowDir is the directory where the output over MaxTotalSize is written,
+	// in a file announced by an event; if empty, that output is dropped
+	OverflowDir string `json:"overflow_dir,omitempty"`
+}
+
+// DefaultOutputStreamConfig returns the configuration used for the options that are not set
+func DefaultOutputStreamConfig() OutputStreamConfig {
+	return OutputStreamConfig{
+		Mode:         OutputStreamLine,
+		MaxEventSize: 4 << 10,
+		MaxTotalSize: 1 << 20,
+		RateLimit:    10,
+	}
+}
+
+// Validate checks the configuration options
+func (c OutputStreamConfig) Validate() error {
+	if c.Mode != "" && c.Mode != OutputStreamLine && c.Mode != OutputStreamChunk {
+		return fmt.Errorf("invalid output stream mode %q, must be %q or %q", c.Mode, OutputStreamLine, OutputStreamChunk)
+	}
+	if c.MaxEventSize < 0 || c.MaxTotalSize < 0 || c.RateLimit < 0 {
+		return fmt.Errorf("output stream limits cannot be negative")
+	}
+	return nil
+}
+
+// OutputChunk is the payload of the events emitted by an OutputStream
+type OutputChunk struct {
+	Msg string
+	// Offset is the position of Msg in the output of the process
+	Offset int64
+	// Truncated is set on the last event when the rest of the output is dropped
+	Truncated bool `json:",omitempty"`
+	// OverflowFile is set on the last event when the rest of the output is
+	// written to this file
+	OverflowFile string `json:",omitempty"`
+}
+
+// OutputStream is a writer that emits the output of a process as test events while
+// the process runs, according to its configuration. It must be closed once the
+// process ended, to emit the remaining output.
+type OutputStream struct {
+	ctx       xcontext.Context
+	ev        testevent.Emitter
+	target    *target.Target
+	eventName event.Name
+	config    OutputStreamConfig
+
+	// pending is the output not emitted yet, starting at offset
+	pending bytes.Buffer
+	offset  int64
+	// size is the number of output bytes written so far
+	size int64
+
+	lastEmit time.Time
+	timer    *time.Timer
+	overflow *os.File
+	closed   bool
+
+	mu sync.Mutex
+}
+
+// NewOutputStream returns a stream emitting the output as events with the given name;
+// the options of config that are not set take their default values
+func NewOutputStream(
+	ctx xcontext.Context, ev testevent.Emitter, target *target.Target,
+	eventName event.Name, config OutputStreamConfig,
+) *OutputStream {
+	def := DefaultOutputStreamConfig()
+	if config.Mode == "" {
+		config.Mode = def.Mode
+	}
+	if config.MaxEventSize == 0 {
+		config.MaxEventSize = def.MaxEventSize
+	}
+	if config.MaxTotalSize == 0 {
+		config.MaxTotalSize = def.MaxTotalSize
+	}
+	if config.RateLimit == 0 {
+		config.RateLimit = def.RateLimit
+	}
+
+	return &OutputStream{
+		ctx:       ctx,
+		ev:        ev,
+		target:    target,
+		eventName: eventName,
+		config:    config,
+	}
+}
+
+// Write queues the output for emission, it never fails so that the process
+// isn't affected by emission errors
+func (s *OutputStream) Write(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return len(data), nil
+	}
+
+	n := len(data)
+	if room := s.config.MaxTotalSize - s.size; int64(len(data)) > room {
+		if room > 0 {
+			s.pending.Write(data[:room])
+		}
+		s.writeOverflow(data[max64(room, 0):])
+	} else {
+		s.pending.Write(data)
+	}
+	s.size += int64(n)
+
+	s.emitReady()
+	return n, nil
+}
+
+// Close emits the remaining output, regardless of the rate limit
+func (s *OutputStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	for s.pending.Len() > 0 {
+		n := s.emittableLen()
+		if n == 0 {
+			// the last line is not terminated
+			n = s.pending.Len()
+		}
+		s.emit(n)
+	}
+
+	if s.size > s.config.MaxTotalSize {
+		chunk := OutputChunk{Offset: s.config.MaxTotalSize, Truncated: s.overflow == nil}
+		if s.overflow != nil {
+			chunk.OverflowFile = s.overflow.Name()
+			if err := s.overflow.Close(); err != nil {
+				s.ctx.Warnf("Failed to close output overflow file: %v", err)
+			}
+		}
+		s.emitChunk(chunk)
+	}
+	return nil
+}
+
+func (s *OutputStream) writeOverflow(data []byte) {
+	if s.config.OverflowDir == "" {
+		return
+	}
+
+	if s.overflow == nil {
+		var err error
+		pattern := strings.ReplaceAll(fmt.Sprintf("%s_%s_*.log", s.target.ID, s.eventName), string(os.PathSeparator), "_")
+		s.overflow, err = os.CreateTemp(s.config.OverflowDir, pattern)
+		if err != nil {
+			s.ctx.Warnf("Failed to create output overflow file: %v", err)
+			s.config.OverflowDir = ""
+			return
+		}
+	}
+
+	if _, err := s.overflow.Write(data); err != nil {
+		s.ctx.Warnf("Failed to write output overflow file %s: %v", s.overflow.Name(), err)
+	}
+}
+
+// emitReady emits the pending output that can be emitted now, as allowed by the
+// rate limit; if some is left, it schedules its emission
+func (s *OutputStream) emitReady() {
+	interval := time.Duration(float64(time.Second) / s.config.RateLimit)
+
+	for s.pending.Len() > 0 {
+		if wait := interval - time.Since(s.lastEmit); wait > 0 {
+			if s.timer == nil {
+				s.timer = time.AfterFunc(wait, func() {
+					s.mu.Lock()
+					defer s.mu.Unlock()
+
+					s.timer = nil
+					if !s.closed {
+						s.emitReady()
+					}
+				})
+			}
+			return
+		}
+
+		if !s.emit(s.emittableLen()) {
+			return
+		}
+	}
+}
+
+// emittableLen returns how many pending bytes can be emitted in the next event
+func (s *OutputStream) emittableLen() int {
+	data := s.pending.Bytes()
+	n := len(data)
+	if n > s.config.MaxEventSize {
+		n = s.config.MaxEventSize
+	}
+	if s.config.Mode == OutputStreamChunk {
+		return n
+	}
+	if i := bytes.LastIndexByte(data[:n], '\n'); i >= 0 {
+		return i + 1
+	}
+	if n == s.config.MaxEventSize {
+		// the line is too long for an event, split it
+		return n
+	}
+	return 0
+}
+
+// emit emits an event with the next n pending bytes, it returns false if there was nothing to emit
+func (s *OutputStream) emit(n int) bool {
+	if n <= 0 {
+		return false
+	}
+
+	chunk := OutputChunk{Msg: string(s.pending.Next(n)), Offset: s.offset}
+	s.offset += int64(len(chunk.Msg))
+	s.emitChunk(chunk)
+	return true
+}
+
+func (s *OutputStream) emitChunk(chunk OutputChunk) {
+	s.lastEmit = time.Now()
+
+	payload, err := json.Marshal(chunk)
+	if err != nil {
+		s.ctx.Warnf("Failed to marshal output event: %v", err)
+		return
+	}
+	rm := json.RawMessage(payload)
+	data := testevent.Data{
+		EventName: s.eventName,
+		Target:    s.target,
+		Payload:   &rm,
+	}
+	if err := s.ev.Emit(s.ctx, data); err != nil {
+		s.ctx.Warnf("Failed to emit output event: %v", err)
+	}
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package teststeps
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linuxboot/contest/pkg/event/testevent"
+	"github.com/linuxboot/contest/pkg/target"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+type chunkRecorder struct {
+	mu     sync.Mutex
+	chunks []OutputChunk
+}
+
+func (r *chunkRecorder) Emit(ctx xcontext.Context, data testevent.Data) error {
+	var chunk OutputChunk
+	if err := json.Unmarshal(*data.Payload, &chunk); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunks = append(r.chunks, chunk)
+	return nil
+}
+
+func (r *chunkRecorder) msgs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var msgs []string
+	for _, c := range r.chunks {
+		msgs = append(msgs, c.Msg)
+	}
+	return msgs
+}
+
+func TestOutputStreamLines(t *testing.T) {
+	ev := &chunkRecorder{}
+	s := NewOutputStream(xcontext.Background(), ev, &target.Target{ID: "T1"}, "Stdout", OutputStreamConfig{
+		MaxEventSize: 8,
+		RateLimit:    1000,
+	})
+
+	// partial lines are held until complete
+	_, _ = s.Write([]byte("one\ntw"))
+	require.Equal(t, []string{"one\n"}, ev.msgs())
+
+	time.Sleep(5 * time.Millisecond)
+	_, _ = s.Write([]byte("o\n"))
+	require.Equal(t, []string{"one\n", "two\n"}, ev.msgs())
+
+	// lines longer than an event are split
+	time.Sleep(5 * time.Millisecond)
+	_, _ = s.Write([]byte("0123456789\nend"))
+	require.NoError(t, s.Close())
+	require.Equal(t, []string{"one\n", "two\n", "01234567", "89\n", "end"}, ev.msgs())
+	require.Equal(t, int64(len("one\ntwo\n01234567")), ev.chunks[3].Offset)
+}
+
+func TestOutputStreamRateLimit(t *testing.T) {
+	ev := &chunkRecorder{}
+	s := NewOutputStream(xcontext.Background(), ev, &target.Target{ID: "T1"}, "Stdout", OutputStreamConfig{
+		Mode:      OutputStreamChunk,
+		RateLimit: 10,
+	})
+
+	// writes faster than the rate limit are merged
+	for _, w := range []string{"a", "b", "c"} {
+		_, _ = s.Write([]byte(w))
+	}
+	require.Equal(t, []string{"a"}, ev.msgs())
+
+	require.Eventually(t, func() bool {
+		return len(ev.msgs()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"a", "bc"}, ev.msgs())
+	require.NoError(t, s.Close())
+}
+
+func TestOutputStreamOverflow(t *testing.T) {
+	dir := t.TempDir()
+
+	ev := &chunkRecorder{}
+	s := NewOutputStream(xcontext.Background(), ev, &target.Target{ID: "T1"}, "Stdout", OutputStreamConfig{
+		Mode:         OutputStreamChunk,
+		MaxTotalSize: 4,
+		OverflowDir:  dir,
+	})
+	_, _ = s.Write([]byte("0123"))
+	_, _ = s.Write([]byte("456789"))
+	require.NoError(t, s.Close())
+
+	require.Equal(t, 2, len(ev.chunks))
+	require.Equal(t, "0123", ev.chunks[0].Msg)
+	require.Equal(t, int64(4), ev.chunks[1].Offset)
+	require.False(t, ev.chunks[1].Truncated)
+	require.NotEmpty(t, ev.chunks[1].OverflowFile)
+
+	overflow, err := os.ReadFile(ev.chunks[1].OverflowFile)
+	require.NoError(t, err)
+	require.Equal(t, "456789", string(overflow))
+
+	// without an overflow dir the rest is dropped
+	ev = &chunkRecorder{}
+	s = NewOutputStream(xcontext.Background(), ev, &target.Target{ID: "T1"}, "Stdout", OutputStreamConfig{
+		Mode:         OutputStreamChunk,
+		MaxTotalSize: 4,
+	})
+	_, _ = s.Write([]byte("0123456789"))
+	require.NoError(t, s.Close())
+	require.Equal(t, 2, len(ev.chunks))
+	require.True(t, ev.chunks[1].Truncated)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package teststeps
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/linuxboot/contest/pkg/test"
+)
+
+// OutputVariable is how the value of a step variable is extracted from the
+// stdout of a command, either with a regex or with a JSON path. In the step
+// parameters, a plain string is a regex.
+type OutputVariable struct {
+	// Regex is matched against the output; the value is its capture group Group,
+	// by default the first one, or the whole match if the regex has no groups
+	Regex string `json:"regex,omitempty"`
+	Group *int   `json:"group,omitempty"`
+	// JSONPath is the path of the value in the output parsed as a JSON document
+	JSONPath string `json:"json_path,omitempty"`
+}
+
+// UnmarshalJSON accepts a plain string as a regex
+func (v *OutputVariable) UnmarshalJSON(data []byte) error {
+	var regex string
+	if err := json.Unmarshal(data, &regex); err == nil {
+		*v = OutputVariable{Regex: regex}
+		return nil
+	}
+
+	type outputVariable OutputVariable
+	return json.Unmarshal(data, (*outputVariable)(v))
+}
+
+// Outputs maps the names of step variables to how their values are extracted
+// from the stdout of a command, so that later steps can use them
+type Outputs map[string]OutputVariable
+
+// ParseOutputs parses the outputs step parameter, which is a JSON object; it
+// returns nil if the parameter is empty
+func ParseOutputs(param *test.Param) (Outputs, error) {
+	if param.IsEmpty() {
+		return nil, nil
+	}
+
+	var outputs Outputs
+	if err := json.Unmarshal(param.JSON(), &outputs); err != nil {
+		return nil, fmt.Errorf("invalid outputs: %w", err)
+	}
+	if err := outputs.Validate(); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// Validate checks the variable names and how their values are extracted
+func (o Outputs) Validate() error {
+	for name, v := range o {
+		if err := test.CheckIdentifier(name); err != nil {
+			return fmt.Errorf("invalid output variable name '%s': %w", name, err)
+		}
+		if _, _, err := v.compile(); err != nil {
+			return fmt.Errorf("invalid output variable '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (v OutputVariable) compile() (*regexp.Regexp, JSONPath, error) {
+	if (v.Regex == "") == (v.JSONPath == "") {
+		return nil, nil, fmt.Errorf("exactly one of regex or json_path must be set")
+	}
+
+	if v.JSONPath != "" {
+		path, err := ParseJSONPath(v.JSONPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid json path %q: %w", v.JSONPath, err)
+		}
+		return nil, path, nil
+	}
+
+	re, err := regexp.Compile(v.Regex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid regex %q: %w", v.Regex, err)
+	}
+	if v.Group != nil && (*v.Group < 0 || *v.Group > re.NumSubexp()) {
+		return nil, nil, fmt.Errorf("regex %q has no group %d", v.Regex, *v.Group)
+	}
+	return re, nil, nil
+}
+
+// Extract returns the values of the variables found in the output; it fails if
+// any of them is not found
+func (o Outputs) Extract(stdout []byte) (map[string]interface{}, error) {
+	// the document is parsed only if needed, and only once
+	var doc interface{}
+	var docErr error
+	parsed := false
+
+	values := make(map[string]interface{}, len(o))
+	for name, v := range o {
+		re, path, err := v.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid output variable '%s': %w", name, err)
+		}
+
+		if path != nil {
+			if !parsed {
+				docErr = json.Unmarshal(stdout, &doc)
+				parsed = true
+			}
+			if docErr != nil {
+				return nil, fmt.Errorf("cannot get output variable '%s', output is not a json document: %w", name, docErr)
+			}
+			value, err := path.Lookup(doc)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get output variable '%s' at %s: %w", name, v.JSONPath, err)
+			}
+			values[name] = value
+			continue
+		}
+
+		m := re.FindSubmatch(stdout)
+		if m == nil {
+			return nil, fmt.Errorf("cannot get output variable '%s', output does not match %q", name, v.Regex)
+		}
+		group := 0
+		if v.Group != nil {
+			group = *v.Group
+		} else if re.NumSubexp() > 0 {
+			group = 1
+		}
+		values[name] = string(m[group])
+	}
+	return values, nil
+}
+
+// Publish extracts the variables from the output and adds them to the step
+// variables of the target
+func (o Outputs) Publish(stepsVars test.StepsVariables, tgtID string, stdout []byte) error {
+	if len(o) == 0 {
+		return nil
+	}
+
+	values, err := o.Extract(stdout)
+	if err != nil {
+		return err
+	}
+	for name, value := range values {
+		if err := stepsVars.Add(tgtID, name, value); err != nil {
+			return fmt.Errorf("failed to add output variable '%s': %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package teststeps
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/linuxboot/contest/pkg/test"
+	"github.com/stretchr/testify/require"
+)
+
+type stepsVarsRecorder map[string]interface{}
+
+func (r stepsVarsRecorder) Get(tgtID string, stepLabel, name string, out interface{}) error {
+	return nil
+}
+
+func (r stepsVarsRecorder) Add(tgtID string, name string, in interface{}) error {
+	r[tgtID+"."+name] = in
+	return nil
+}
+
+func TestOutputs(t *testing.T) {
+	param := test.NewParam(`{
+		"fw_version": "firmware: (\\S+)",
+		"fw_line": {"regex": "firmware: \\S+"},
+		"fw_date": {"regex": "firmware: (\\S+) \\((\\S+)\\)", "group": 2},
+		"temp": {"json_path": "$.sensors[1]['temp c']"}
+	}`)
+	outputs, err := ParseOutputs(param)
+	require.NoError(t, err)
+
+	// both the regexes and the json path apply to the whole output
+	values, err := outputs.Extract([]byte(`{"info": "firmware: 1.2.3 (2022-01-01)", "sensors": [{}, {"temp c": 42}]}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"fw_version": "1.2.3",
+		"fw_line":    "firmware: 1.2.3",
+		"fw_date":    "2022-01-01",
+		"temp":       float64(42),
+	}, values)
+
+	_, err = outputs.Extract([]byte(`{"info": "firmware: 1.2.3 (2022-01-01)", "sensors": []}`))
+	require.Error(t, err)
+
+	vars := stepsVarsRecorder{}
+	require.NoError(t, Outputs{"fw_version": {Regex: "firmware: (\\S+)"}}.Publish(vars, "T1", []byte("firmware: 1.2.3\n")))
+	require.Equal(t, stepsVarsRecorder{"T1.fw_version": "1.2.3"}, vars)
+
+	require.Error(t, Outputs{"fw_version": {Regex: "firmware: (\\S+)"}}.Publish(vars, "T1", []byte("no version\n")))
+
+	outputs, err = ParseOutputs(test.NewParam(""))
+	require.NoError(t, err)
+	require.Nil(t, outputs)
+}
+
+func TestOutputsInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"1st": "a"}`,
+		`{"a": "("}`,
+		`{"a": {}}`,
+		`{"a": {"regex": "a", "json_path": "$.a"}}`,
+		`{"a": {"regex": "(a)", "group": 2}}`,
+		`{"a": {"json_path": "a"}}`,
+		`{"a": {"json_path": "$.a[b]"}}`,
+	} {
+		var outputs Outputs
+		require.NoError(t, json.Unmarshal([]byte(data), &outputs))
+		require.Error(t, outputs.Validate(), data)
+	}
+}
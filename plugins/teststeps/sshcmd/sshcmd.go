@@ -59,8 +59,11 @@ type SSHCmd struct {
 	Executable      *test.Param
 	Args            []test.Param
 	Expect          *test.Param
+	Outputs         *test.Param
 	Timeout         *test.Param
 	SkipIfEmptyHost *test.Param
+
+	outputs teststeps.Outputs
 }
 
 // Name returns the plugin name.
@@ -250,9 +253,9 @@ func (ts *SSHCmd) Run(
 							return fmt.Errorf("match for %s not found for target %v", expect, target)
 						}
 					}
-				} else {
-					ctx.Warnf("Stderr of command '%s' is '%s'", cmd, stderr.Bytes())
+					return ts.outputs.Publish(stepsVars, target.ID, stdout.Bytes())
 				}
+				ctx.Warnf("Stderr of command '%s' is '%s'", cmd, stderr.Bytes())
 				return err
 			case <-ctx.Done():
 				return session.Signal(ssh.SIGKILL)
@@ -262,7 +265,7 @@ func (ts *SSHCmd) Run(
 					matches := re.FindAll(stdout.Bytes(), -1)
 					if len(matches) > 0 {
 						log.Infof("match for regex '%s' found", expect)
-						return nil
+						return ts.outputs.Publish(stepsVars, target.ID, stdout.Bytes())
 					}
 				}
 				if time.Now().After(timeTimeout) {
@@ -318,6 +321,12 @@ func (ts *SSHCmd) validateAndPopulate(params test.TestStepParameters) error {
 	}
 	ts.Args = params.Get("args")
 	ts.Expect = params.GetOne("expect")
+	ts.Outputs = params.GetOne("outputs")
+	outputs, err := teststeps.ParseOutputs(ts.Outputs)
+	if err != nil {
+		return fmt.Errorf("invalid 'outputs' parameter: %v", err)
+	}
+	ts.outputs = outputs
 
 	if params.GetOne("timeout").IsEmpty() {
 		ts.Timeout = test.NewParam(defaultTimeoutParameter)
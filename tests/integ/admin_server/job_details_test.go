@@ -0,0 +1,147 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safesql"
+	"github.com/linuxboot/contest/cmds/admin_server/server"
+	"github.com/linuxboot/contest/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+const insertTestEventStmt = "insert into test_events (job_id, run_id, test_name, test_attempt, test_step_label, event_name, target_id, payload, emit_time) values (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func AddTargetEvents(db *safesql.DB, jobID types.JobID, start time.Time) error {
+	events := []struct {
+		attempt   uint32
+		stepLabel string
+		eventName string
+		targetID  string
+		payload   *string
+		offset    time.Duration
+	}{
+		{0, "", "TargetAcquired", "t1", nil, 0},
+		{0, "step1", "TargetIn", "t1", nil, time.Second},
+		{0, "step1", "TargetErr", "t1", stringPtr(`{"Error": "retry me"}`), 2 * time.Second},
+		{1, "", "TargetAcquired", "t1", nil, 3 * time.Second},
+		{1, "step1", "TargetIn", "t1", nil, 4 * time.Second},
+		{1, "step1", "TargetOut", "t1", nil, 5 * time.Second},
+		{1, "step2", "TargetIn", "t1", nil, 6 * time.Second},
+		{1, "step2", "TargetErr", "t1", stringPtr(`{"Error": "failed"}`), 7 * time.Second},
+		{1, "step2", "StepLog", "t1", nil, 7 * time.Second},
+	}
+	for _, ev := range events {
+		if _, err := db.Exec(
+			safesql.New(insertTestEventStmt),
+			jobID, 1, "test", ev.attempt, ev.stepLabel, ev.eventName, ev.targetID, ev.payload, start.Add(ev.offset),
+		); err != nil {
+			return fmt.Errorf("error while inserting test event: %w", err)
+		}
+	}
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func getJobEndpoint(addr string, result interface{}, elems ...string) (int, error) {
+	url, err := url.ParseRequestURI(addr)
+	if err != nil {
+		return 0, fmt.Errorf("error while parsing the url(%v): %w", addr, err)
+	}
+	url.Path = path.Join(append([]string{url.Path}, elems...)...)
+
+	res, err := http.Get(url.String())
+	if err != nil {
+		return 0, fmt.Errorf("error while Sending GET request: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode, nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return 0, fmt.Errorf("error while parsing request body: %w", err)
+	}
+	return res.StatusCode, nil
+}
+
+func TestGetJobDetails(t *testing.T) {
+	db, err := InitCleanDBConn(*flagContestDBURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(safesql.New("TRUNCATE TABLE test_events")); err != nil {
+		t.Fatal(err)
+	}
+
+	jobIDs, err := AddJobsWithTags(db, []string{"job1"}, []string{"tag2", "tag1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobID := jobIDs[0]
+	start := time.Now().Truncate(time.Second)
+	if err := AddTargetEvents(db, jobID, start); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("details", func(tt *testing.T) {
+		var details server.JobDetails
+		statusCode, err := getJobEndpoint(*flagAdminJobEndpoint, &details, fmt.Sprint(jobID))
+		if err != nil {
+			tt.Fatal(err)
+		}
+		require.Equal(tt, http.StatusOK, statusCode)
+		require.Equal(tt, jobID, details.JobID)
+		require.Equal(tt, "job1", details.Name)
+		require.Equal(tt, []string{"tag1", "tag2"}, details.Tags)
+		require.Nil(tt, details.Report)
+	})
+
+	t.Run("status", func(tt *testing.T) {
+		var runs []server.RunStatus
+		statusCode, err := getJobEndpoint(*flagAdminJobEndpoint, &runs, fmt.Sprint(jobID), "status")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		require.Equal(tt, http.StatusOK, statusCode)
+		require.Len(tt, runs, 1)
+		require.Len(tt, runs[0].Tests, 1)
+		test := runs[0].Tests[0]
+		require.Equal(tt, uint32(1), test.TestAttempt)
+		require.Equal(tt, []string{"t1"}, test.Targets)
+		require.Len(tt, test.Steps, 2)
+		require.Equal(tt, "step1", test.Steps[0].TestStepLabel)
+		require.Nil(tt, test.Steps[0].Targets[0].Error)
+		require.Equal(tt, "step2", test.Steps[1].TestStepLabel)
+		require.Equal(tt, "failed", *test.Steps[1].Targets[0].Error)
+		require.True(tt, start.Add(6*time.Second).Equal(*test.Steps[1].Targets[0].InTime))
+	})
+
+	t.Run("not-found", func(tt *testing.T) {
+		var details server.JobDetails
+		statusCode, err := getJobEndpoint(*flagAdminJobEndpoint, &details, fmt.Sprint(jobID+1))
+		if err != nil {
+			tt.Fatal(err)
+		}
+		require.Equal(tt, http.StatusNotFound, statusCode)
+	})
+
+	t.Run("bad-request", func(tt *testing.T) {
+		var details server.JobDetails
+		statusCode, err := getJobEndpoint(*flagAdminJobEndpoint, &details, "abc")
+		if err != nil {
+			tt.Fatal(err)
+		}
+		require.Equal(tt, http.StatusBadRequest, statusCode)
+	})
+}
@@ -8,16 +8,24 @@ package common
 import (
 	"os"
 
+	"github.com/linuxboot/contest/db/rdbms/dialect"
 	"github.com/linuxboot/contest/pkg/storage"
 	"github.com/linuxboot/contest/plugins/storage/rdbms"
 )
 
+// DatabaseDialectEnv is the environment variable which selects the database
+// used by storage tests, either "mysql" (default) or "postgres".
+const DatabaseDialectEnv = "CONTEST_DB_DIALECT"
+
 func GetDatabaseURI() string {
+	host := "localhost"
 	if os.Getenv("CI") != "" {
-		return "contest:contest@tcp(dbstorage:3306)/contest_integ?parseTime=true"
-	} else {
-		return "contest:contest@tcp(localhost:3306)/contest_integ?parseTime=true"
+		host = "dbstorage"
+	}
+	if os.Getenv(DatabaseDialectEnv) == dialect.PostgreSQL.String() {
+		return "postgres://contest:contest@" + host + ":5432/contest_integ?sslmode=disable"
 	}
+	return "contest:contest@tcp(" + host + ":3306)/contest_integ?parseTime=true"
 }
 
 func NewStorage(opts ...rdbms.Opt) (storage.Storage, error) {
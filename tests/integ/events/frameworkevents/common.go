@@ -155,3 +155,31 @@ func (suite *FrameworkEventsSuite) TestRetrieveSingleFrameworkEventByEmitTime()
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), 4, len(results))
 }
+
+func (suite *FrameworkEventsSuite) TestRetrieveFrameworkEventsIncrementally() {
+
+	emitTime := time.Now().Truncate(2 * time.Second)
+	for i := 0; i < 2; i++ {
+		require.NoError(suite.T(), populateFrameworkEvents(suite.txStorage, emitTime))
+	}
+
+	all, err := suite.txStorage.GetFrameworkEvent(ctx, mustBuildQuery(suite.T(), frameworkevent.QueryJobID(1)))
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), all, 4)
+
+	results, err := suite.txStorage.GetFrameworkEvent(ctx, mustBuildQuery(suite.T(),
+		frameworkevent.QueryJobID(1),
+		frameworkevent.QuerySequenceIDAfter(all[1].SequenceID),
+	))
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), all[2:], results)
+
+	results, err = suite.txStorage.GetFrameworkEvent(ctx, mustBuildQuery(suite.T(),
+		frameworkevent.QueryEventName("AFrameworkEvent"),
+		frameworkevent.QueryOrder(event.OrderDescending),
+		frameworkevent.QueryLimit(1),
+	))
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), all[2].SequenceID, results[0].SequenceID)
+}
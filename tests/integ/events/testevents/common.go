@@ -212,6 +212,50 @@ func (suite *TestEventsSuite) TestRetrieveSingleTestEventsByNameAndJobID() {
 	assertTestEvents(suite.T(), results, emitTime)
 }
 
+func (suite *TestEventsSuite) TestRetrieveTestEventsIncrementally() {
+
+	emitTime := time.Now().Truncate(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		require.NoError(suite.T(), populateTestEvents(suite.txStorage, emitTime))
+	}
+
+	// Page through the events of job 1, two at a time.
+	var (
+		pages           [][]testevent.Event
+		sequenceIDAfter uint64
+	)
+	for {
+		queryFields := []testevent.QueryField{testevent.QueryJobID(1), testevent.QueryLimit(2)}
+		if sequenceIDAfter != 0 {
+			queryFields = append(queryFields, testevent.QuerySequenceIDAfter(sequenceIDAfter))
+		}
+		results, err := suite.txStorage.GetTestEvents(ctx, mustBuildQuery(suite.T(), queryFields...))
+		require.NoError(suite.T(), err)
+		if len(results) == 0 {
+			break
+		}
+		pages = append(pages, results)
+		sequenceIDAfter = results[len(results)-1].SequenceID
+	}
+	require.Len(suite.T(), pages, 2)
+	require.Len(suite.T(), pages[0], 2)
+	require.Len(suite.T(), pages[1], 1)
+	assert.Less(suite.T(), pages[0][0].SequenceID, pages[0][1].SequenceID)
+	assert.Less(suite.T(), pages[0][1].SequenceID, pages[1][0].SequenceID)
+	assertTestEvents(suite.T(), pages[1], emitTime)
+
+	// The latest event comes first in descending order.
+	results, err := suite.txStorage.GetTestEvents(ctx, mustBuildQuery(suite.T(),
+		testevent.QueryTestStepLabel("TestStepLabel"),
+		testevent.QueryOrder(event.OrderDescending),
+		testevent.QueryLimit(1),
+	))
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), results, 1)
+	assert.Equal(suite.T(), types.JobID(2), results[0].Header.JobID)
+	assert.Greater(suite.T(), results[0].SequenceID, pages[1][0].SequenceID)
+}
+
 func (suite TestEventsSuite) GetStorageEngineVault() storage.EngineVault {
 	return suite.storageEngineVault
 }
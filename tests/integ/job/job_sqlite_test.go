@@ -0,0 +1,29 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// +build integration
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/linuxboot/contest/plugins/storage/sqlite"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestJobSuiteSQLiteStorage(t *testing.T) {
+	testSuite := JobSuite{}
+	storagelayer, err := sqlite.New(sqlite.URIScheme + filepath.Join(t.TempDir(), "contest.db"))
+	if err != nil {
+		panic(fmt.Sprintf("could not initialize sqlite storage layer: %v", err))
+	}
+	defer storagelayer.Close()
+	testSuite.storage = storagelayer
+
+	suite.Run(t, &testSuite)
+}
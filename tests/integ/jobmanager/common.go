@@ -58,10 +58,19 @@ import (
 type CommandType string
 
 const (
-	StartJob CommandType = "start"
-	StopJob  CommandType = "stop"
-	Status   CommandType = "status"
-	List     CommandType = "list"
+	StartJob  CommandType = "start"
+	StopJob   CommandType = "stop"
+	PauseJob  CommandType = "pause"
+	ResumeJob CommandType = "resume"
+	Locks     CommandType = "locks"
+	Unlock    CommandType = "unlock"
+	Purge     CommandType = "purge"
+	Export    CommandType = "export"
+	Import    CommandType = "import"
+	Diff      CommandType = "diff"
+	Analytics CommandType = "analytics"
+	Status    CommandType = "status"
+	List      CommandType = "list"
 )
 
 type command struct {
@@ -70,6 +79,19 @@ type command struct {
 	jobDescriptor string
 	// List arguments
 	jobQuery *storage.JobQuery
+	// Locks and Unlock arguments
+	targetIDs []string
+	// Purge arguments
+	archive bool
+	// Diff arguments
+	otherJobID types.JobID
+	// Analytics arguments
+	tag string
+	// Import arguments
+	jobArchive []byte
+	// Status arguments
+	runsAfter types.RunID
+	maxRuns   uint
 }
 
 const fakeJobID types.JobID = 1234567
@@ -112,8 +134,62 @@ func (tl *TestListener) Serve(ctx xcontext.Context, contestApi *api.API) error {
 					tl.errorCh <- err
 				}
 				tl.responseCh <- resp
+			case PauseJob:
+				resp, err := contestApi.Pause(ctx, "IntegrationTest", command.jobID)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case ResumeJob:
+				resp, err := contestApi.Resume(ctx, "IntegrationTest", command.jobID)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Locks:
+				resp, err := contestApi.Locks(ctx, "IntegrationTest", command.targetIDs)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Unlock:
+				resp, err := contestApi.Unlock(ctx, "IntegrationTest", command.targetIDs)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Purge:
+				resp, err := contestApi.Purge(ctx, "IntegrationTest", command.jobID, command.archive)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Diff:
+				resp, err := contestApi.Diff(ctx, "IntegrationTest", command.jobID, command.otherJobID)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Analytics:
+				resp, err := contestApi.Analytics(ctx, "IntegrationTest", command.tag, time.Time{}, time.Now())
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Export:
+				resp, err := contestApi.Export(ctx, "IntegrationTest", command.jobID)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
+			case Import:
+				resp, err := contestApi.Import(ctx, "IntegrationTest", command.jobArchive)
+				if err != nil {
+					tl.errorCh <- err
+				}
+				tl.responseCh <- resp
 			case Status:
-				resp, err := contestApi.Status(ctx, "IntegrationTest", command.jobID)
+				resp, err := contestApi.StatusPage(ctx, "IntegrationTest", command.jobID, command.runsAfter, command.maxRuns)
 				if err != nil {
 					tl.errorCh <- err
 				}
@@ -257,6 +333,29 @@ func (suite *TestJobManagerSuite) stopJob(jobID types.JobID) error {
 	return nil
 }
 
+func (suite *TestJobManagerSuite) pauseJob(jobID types.JobID) error {
+	return suite.jobCommand(command{commandType: PauseJob, jobID: jobID})
+}
+
+func (suite *TestJobManagerSuite) resumeJob(jobID types.JobID) error {
+	return suite.jobCommand(command{commandType: ResumeJob, jobID: jobID})
+}
+
+func (suite *TestJobManagerSuite) jobCommand(cmd command) error {
+	_, err := suite.command(cmd)
+	return err
+}
+
+func (suite *TestJobManagerSuite) command(cmd command) (api.Response, error) {
+	suite.listener.commandCh <- cmd
+	select {
+	case resp := <-suite.listener.responseCh:
+		return resp, resp.Err
+	case <-time.After(2 * time.Second):
+		return api.Response{}, fmt.Errorf("Listener response should come within the timeout")
+	}
+}
+
 func (suite *TestJobManagerSuite) jobStatus(jobID types.JobID) (*job.Status, error) {
 	suite.listener.commandCh <- command{commandType: Status, jobID: jobID}
 	var resp api.Response
@@ -602,6 +701,293 @@ func (suite *TestJobManagerSuite) TestPauseAndFailToResume() {
 		true)
 }
 
+func (suite *TestJobManagerSuite) TestPauseAndResumeViaAPI() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorSlowEcho2)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobStarted, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+
+	// Pause during the first run, the job must not be resumable before
+	// it is fully paused.
+	time.Sleep(250 * time.Millisecond)
+	require.NoError(suite.T(), suite.pauseJob(jobID))
+	_, err = pollForEvent(suite.eventManager, job.EventJobPaused, jobID, 3*time.Second)
+	require.NoError(suite.T(), err)
+	// Targets stay locked while the job is paused.
+	suite.verifyTargetLockStatus([]string{"id1", "id2"}, true)
+
+	// Only running jobs can be paused, only paused jobs can be resumed.
+	require.Error(suite.T(), suite.pauseJob(jobID))
+	require.Error(suite.T(), suite.resumeJob(fakeJobID))
+
+	require.NoError(suite.T(), suite.resumeJob(jobID))
+	_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 5*time.Second)
+	require.NoError(suite.T(), err)
+	suite.verifyTargetLockStatus([]string{"id1", "id2"}, false)
+
+	suite.jmCancel()
+	select {
+	case <-suite.jobManagerCh:
+	case <-time.After(3 * time.Second):
+		suite.T().Errorf("JobManager should return within the timeout")
+	}
+}
+
+func (suite *TestJobManagerSuite) TestPauseViaAPIAndRestart() {
+	suite.startJobManager(true /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorSlowEcho2)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobStarted, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+	time.Sleep(250 * time.Millisecond)
+	require.NoError(suite.T(), suite.pauseJob(jobID))
+	_, err = pollForEvent(suite.eventManager, job.EventJobPaused, jobID, 3*time.Second)
+	require.NoError(suite.T(), err)
+
+	suite.jmPause()
+	select {
+	case <-suite.jobManagerCh:
+	case <-time.After(3 * time.Second):
+		suite.T().Errorf("JobManager should return within the timeout")
+	}
+
+	// A job paused by the user is not resumed when the server starts.
+	suite.initJobManager("")
+	suite.startJobManager(true /* resumeJobs */)
+	time.Sleep(500 * time.Millisecond)
+	ev, err := pollForEvent(suite.eventManager, job.EventJobStarted, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), ev, 1)
+	suite.verifyTargetLockStatus([]string{"id1", "id2"}, true)
+
+	require.NoError(suite.T(), suite.resumeJob(jobID))
+	_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 5*time.Second)
+	require.NoError(suite.T(), err)
+
+	suite.jmCancel()
+	select {
+	case <-suite.jobManagerCh:
+	case <-time.After(3 * time.Second):
+		suite.T().Errorf("JobManager should return within the timeout")
+	}
+}
+
+func (suite *TestJobManagerSuite) TestListAndForceUnlockTargets() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorSlowEcho2)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobStarted, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+	// A paused job keeps its targets locked.
+	time.Sleep(250 * time.Millisecond)
+	require.NoError(suite.T(), suite.pauseJob(jobID))
+	_, err = pollForEvent(suite.eventManager, job.EventJobPaused, jobID, 3*time.Second)
+	require.NoError(suite.T(), err)
+
+	resp, err := suite.command(command{commandType: Locks})
+	require.NoError(suite.T(), err)
+	locks := resp.Data.(api.ResponseDataLocks).Locks
+	require.Len(suite.T(), locks, 2)
+	require.Equal(suite.T(), "id1", locks[0].TargetID)
+	require.Equal(suite.T(), jobID, locks[0].JobID)
+	require.Equal(suite.T(), "id2", locks[1].TargetID)
+
+	_, err = suite.command(command{commandType: Unlock})
+	require.Error(suite.T(), err)
+	resp, err = suite.command(command{commandType: Unlock, targetIDs: []string{"id1", "id3"}})
+	require.NoError(suite.T(), err)
+	unlocked := resp.Data.(api.ResponseDataUnlock).Unlocked
+	require.Len(suite.T(), unlocked, 1)
+	require.Equal(suite.T(), "id1", unlocked[0].TargetID)
+	suite.verifyTargetLockStatus([]string{"id1"}, false)
+	suite.verifyTargetLockStatus([]string{"id2"}, true)
+
+	// The override is recorded in the job's events.
+	ev, err := pollForEvent(suite.eventManager, target.EventTargetLockForceUnlocked, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), ev, 1)
+	var payload target.ForceUnlockPayload
+	require.NoError(suite.T(), json.Unmarshal(*ev[0].Payload, &payload))
+	require.Equal(suite.T(), "id1", payload.Lock.TargetID)
+	require.Equal(suite.T(), "IntegrationTest", payload.Requestor)
+
+	suite.jmCancel()
+	select {
+	case <-suite.jobManagerCh:
+	case <-time.After(3 * time.Second):
+		suite.T().Errorf("JobManager should return within the timeout")
+	}
+}
+
+func (suite *TestJobManagerSuite) TestPurgeJobViaAPI() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorNoop)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+	runningJobID, err := suite.startJob(jobDescriptorSlowEcho)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobStarted, runningJobID, 1*time.Second)
+	require.NoError(suite.T(), err)
+
+	// Running jobs cannot be purged, and archiving requires an archive directory.
+	_, err = suite.command(command{commandType: Purge, jobID: runningJobID})
+	require.Error(suite.T(), err)
+	_, err = suite.command(command{commandType: Purge, jobID: jobID, archive: true})
+	require.Error(suite.T(), err)
+
+	resp, err := suite.command(command{commandType: Purge, jobID: jobID})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), api.ResponseDataPurge{JobID: jobID}, resp.Data)
+
+	_, err = suite.jsm.GetJobRequest(suite.jmCtx, jobID)
+	require.Error(suite.T(), err)
+	ev, err := suite.eventManager.Fetch(suite.jmCtx, frameworkevent.QueryJobID(jobID))
+	require.NoError(suite.T(), err)
+	require.Empty(suite.T(), ev)
+	tev, err := suite.testEventManager.Fetch(suite.jmCtx, testevent.QueryJobID(jobID))
+	require.NoError(suite.T(), err)
+	require.Empty(suite.T(), tev)
+	_, err = suite.jobStatus(jobID)
+	require.Error(suite.T(), err)
+
+	require.NoError(suite.T(), suite.stopJob(runningJobID))
+}
+
+func (suite *TestJobManagerSuite) TestJobStatusPage() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorNoopMultiRun)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 5*time.Second)
+	require.NoError(suite.T(), err)
+
+	status, err := suite.jobStatus(jobID)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), types.RunID(3), status.NumRuns)
+	require.Len(suite.T(), status.RunStatuses, 3)
+	require.NotNil(suite.T(), status.RunStatus)
+	require.Equal(suite.T(), types.RunID(3), status.RunStatus.RunID)
+
+	resp, err := suite.command(command{commandType: Status, jobID: jobID, runsAfter: 1, maxRuns: 1})
+	require.NoError(suite.T(), err)
+	status = resp.Data.(api.ResponseDataStatus).Status
+	require.Equal(suite.T(), types.RunID(3), status.NumRuns)
+	require.Len(suite.T(), status.RunStatuses, 1)
+	require.Equal(suite.T(), types.RunID(2), status.RunStatuses[0].RunID)
+	require.Nil(suite.T(), status.RunStatus)
+
+	resp, err = suite.command(command{commandType: Status, jobID: jobID, runsAfter: 3})
+	require.NoError(suite.T(), err)
+	require.Empty(suite.T(), resp.Data.(api.ResponseDataStatus).Status.RunStatuses)
+}
+
+func (suite *TestJobManagerSuite) TestExportImportJobViaAPI() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	jobID, err := suite.startJob(jobDescriptorNoopMultiRun)
+	require.NoError(suite.T(), err)
+	_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 5*time.Second)
+	require.NoError(suite.T(), err)
+
+	resp, err := suite.command(command{commandType: Export, jobID: jobID})
+	require.NoError(suite.T(), err)
+	exported := resp.Data.(api.ResponseDataExport)
+	require.Equal(suite.T(), jobID, exported.JobID)
+
+	resp, err = suite.command(command{commandType: Import, jobArchive: exported.Archive})
+	require.NoError(suite.T(), err)
+	newJobID := resp.Data.(api.ResponseDataImport).JobID
+	require.NotEqual(suite.T(), jobID, newJobID)
+
+	status, err := suite.jobStatus(jobID)
+	require.NoError(suite.T(), err)
+	newStatus, err := suite.jobStatus(newJobID)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), status.State, newStatus.State)
+	require.True(suite.T(), status.StartTime.Equal(newStatus.StartTime))
+	require.Equal(suite.T(), status.NumRuns, newStatus.NumRuns)
+	require.Len(suite.T(), newStatus.RunStatuses, len(status.RunStatuses))
+	for i, runStatus := range newStatus.RunStatuses {
+		require.Equal(suite.T(), newJobID, runStatus.JobID)
+		require.Len(suite.T(), runStatus.TestStatuses, len(status.RunStatuses[i].TestStatuses))
+	}
+	require.Len(suite.T(), newStatus.JobReport.RunReports, len(status.JobReport.RunReports))
+
+	// Running jobs cannot be imported, and invalid archives are rejected.
+	_, err = suite.command(command{commandType: Import, jobArchive: []byte("{}")})
+	require.Error(suite.T(), err)
+	_, err = suite.command(command{commandType: Import, jobArchive: []byte("not json")})
+	require.Error(suite.T(), err)
+}
+
+func (suite *TestJobManagerSuite) TestDiffJobsViaAPI() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	var jobIDs []types.JobID
+	for _, jd := range []string{jobDescriptorNoop, jobDescriptorNoop, jobDescriptorFailure} {
+		jobID, err := suite.startJob(jd)
+		require.NoError(suite.T(), err)
+		_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 1*time.Second)
+		require.NoError(suite.T(), err)
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	resp, err := suite.command(command{commandType: Diff, jobID: jobIDs[0], otherJobID: jobIDs[1]})
+	require.NoError(suite.T(), err)
+	diff := resp.Data.(api.ResponseDataDiff).Diff
+	require.Equal(suite.T(), jobIDs[0], diff.JobA)
+	require.Equal(suite.T(), jobIDs[1], diff.JobB)
+	require.Len(suite.T(), diff.Runs, 1)
+	require.Len(suite.T(), diff.Runs[0].Steps, 1)
+	require.Equal(suite.T(), "noop_label", diff.Runs[0].Steps[0].TestStepLabel)
+	require.Equal(suite.T(), job.DiffChangeNone, diff.Runs[0].Steps[0].Change)
+	require.Empty(suite.T(), diff.Runs[0].Steps[0].Targets)
+
+	resp, err = suite.command(command{commandType: Diff, jobID: jobIDs[0], otherJobID: jobIDs[2]})
+	require.NoError(suite.T(), err)
+	diff = resp.Data.(api.ResponseDataDiff).Diff
+	require.Len(suite.T(), diff.Runs, 1)
+	require.Len(suite.T(), diff.Runs[0].Steps, 2)
+	require.Equal(suite.T(), job.DiffChangeMissing, diff.Runs[0].Steps[0].Change)
+	require.Equal(suite.T(), job.DiffChangeNew, diff.Runs[0].Steps[1].Change)
+	require.Equal(suite.T(), "fail_label", diff.Runs[0].Steps[1].TestStepLabel)
+
+	_, err = suite.command(command{commandType: Diff, jobID: jobIDs[0], otherJobID: fakeJobID})
+	require.Error(suite.T(), err)
+}
+
+func (suite *TestJobManagerSuite) TestAnalyticsViaAPI() {
+	suite.startJobManager(false /* resumeJobs */)
+
+	for _, jd := range []string{jobDescriptorNoop, jobDescriptorNoop, jobDescriptorFailure} {
+		jobID, err := suite.startJob(jd)
+		require.NoError(suite.T(), err)
+		_, err = pollForEvent(suite.eventManager, job.EventJobCompleted, jobID, 1*time.Second)
+		require.NoError(suite.T(), err)
+	}
+
+	resp, err := suite.command(command{commandType: Analytics, tag: "integration_testing"})
+	require.NoError(suite.T(), err)
+	report := resp.Data.(api.ResponseDataAnalytics).Report
+	require.Equal(suite.T(), uint(3), report.Jobs)
+	require.Len(suite.T(), report.Steps, 2)
+	require.Equal(suite.T(), "fail_label", report.Steps[0].TestStepLabel)
+	require.Equal(suite.T(), report.Steps[0].Executions, report.Steps[0].Failures)
+	require.Equal(suite.T(), 1.0, report.Steps[0].FailureRate)
+	require.Equal(suite.T(), "noop_label", report.Steps[1].TestStepLabel)
+	require.Zero(suite.T(), report.Steps[1].Failures)
+	require.NotEmpty(suite.T(), report.Targets)
+
+	_, err = suite.command(command{commandType: Analytics, tag: "_bad"})
+	require.Error(suite.T(), err)
+}
+
 func (suite *TestJobManagerSuite) getTargetEvents(testName, targetID string) string {
 	return suite.getEvents(testName, &targetID, nil)
 }
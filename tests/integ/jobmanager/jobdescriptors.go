@@ -96,6 +96,9 @@ var testStepsNoop = `
         "TestName": "IntegrationTest: noop"
     }`
 var jobDescriptorNoop = descriptorMust(jobDescriptorTemplate, testStepsNoop)
+var jobDescriptorNoopMultiRun = descriptorMust2(
+	jobDescriptorTemplate,
+	&templateData{Version: jobDescriptorVersion, Runs: 3, RunInterval: "10ms", Def: testStepsNoop})
 var jobDescriptorNoop2 = descriptorMust2(
 	jobDescriptorTemplate,
 	&templateData{Version: jobDescriptorVersion, Runs: 1, RunInterval: "1s", Def: testStepsNoop, ExtraTags: `, "foo"`},
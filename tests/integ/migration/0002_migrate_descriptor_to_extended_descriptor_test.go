@@ -13,6 +13,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -120,6 +121,9 @@ func (suite *TestDescriptorMigrationSuite) TestUpMigratesToExtendedDescriptor()
 
 }
 func TestTestDescriptorMigrationSuite(t *testing.T) {
+	if os.Getenv(common.DatabaseDialectEnv) == "postgres" {
+		t.Skip("golang migrations are not applied to PostgreSQL")
+	}
 	testSuite := TestDescriptorMigrationSuite{}
 	suite.Run(t, &testSuite)
 }
@@ -326,3 +326,49 @@ func (ts *TargetLockerTestSuite) TestLockingTransactional() {
 	// this means it can be locked by the first owner
 	require.NoError(ts.T(), ts.tl.Lock(ctx, job1, defaultTimeout, target1))
 }
+
+func (ts *TargetLockerTestSuite) TestListLocks() {
+	require.NoError(ts.T(), ts.tl.Lock(ctx, job1, defaultTimeout, target1))
+	require.NoError(ts.T(), ts.tl.Lock(ctx, job2, shortTimeout, target2))
+	locks, err := ts.tl.ListLocks(ctx, nil)
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), locks, 2)
+	require.Equal(ts.T(), "001", locks[0].TargetID)
+	require.Equal(ts.T(), job1, locks[0].JobID)
+	require.True(ts.T(), locks[0].ExpiresAt.After(locks[0].CreatedAt))
+	require.Equal(ts.T(), "002", locks[1].TargetID)
+	require.Equal(ts.T(), job2, locks[1].JobID)
+	// only the requested targets that are locked are returned
+	locks, err = ts.tl.ListLocks(ctx, allTargets[1:])
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), locks, 1)
+	require.Equal(ts.T(), "002", locks[0].TargetID)
+	// expired locks are still listed
+	ts.clock.Add(shortTimeout + time.Second)
+	locks, err = ts.tl.ListLocks(ctx, twoTargets)
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), locks, 2)
+}
+
+func (ts *TargetLockerTestSuite) TestForceUnlock() {
+	require.NoError(ts.T(), ts.tl.Lock(ctx, job1, defaultTimeout, target1))
+	require.NoError(ts.T(), ts.tl.Lock(ctx, job2, defaultTimeout, target2))
+	unlocked, err := ts.tl.ForceUnlock(ctx, allTargets)
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), unlocked, 2)
+	require.Equal(ts.T(), job1, unlocked[0].JobID)
+	require.Equal(ts.T(), job2, unlocked[1].JobID)
+	locks, err := ts.tl.ListLocks(ctx, nil)
+	require.NoError(ts.T(), err)
+	require.Empty(ts.T(), locks)
+	// targets can be locked by another job now, and the previous owner
+	// cannot refresh its locks anymore.
+	require.NoError(ts.T(), ts.tl.Lock(ctx, job2, defaultTimeout, target1))
+	require.Error(ts.T(), ts.tl.RefreshLocks(ctx, job1, defaultTimeout, target1))
+}
+
+func (ts *TargetLockerTestSuite) TestForceUnlockNoTargets() {
+	unlocked, err := ts.tl.ForceUnlock(ctx, nil)
+	require.NoError(ts.T(), err)
+	require.Empty(ts.T(), unlocked)
+}
@@ -17,17 +17,16 @@ import (
 	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
 	"github.com/linuxboot/contest/pkg/xcontext/logger"
 
+	"github.com/linuxboot/contest/db/rdbms/dialect"
 	"github.com/linuxboot/contest/tools/migration/rdbms/migrate"
 
-	_ "github.com/go-sql-driver/mysql"
-
 	"github.com/pressly/goose"
 	"github.com/sirupsen/logrus"
 )
 
 var (
 	flags        = flag.NewFlagSet("migrate", flag.ExitOnError)
-	flagDBDriver = flags.String("dbDriver", "mysql", "DB Driver")
+	flagDBDriver = flags.String("dbDriver", "", "DB Driver, inferred from the database URI if not specified")
 	flagDBURI    = flags.String("dbURI", "contest:contest@tcp(localhost:3306)/contest?parseTime=true", "Database URI")
 	flagDir      = flags.String("dir", "", "Directory containing migration scripts")
 	flagDebug    = flags.Bool("debug", false, "Enabled debug logging")
@@ -85,13 +84,21 @@ func main() {
 		ctx.Logger().Fatalf("migration directory was not specified")
 	}
 
-	for _, m := range migrate.Migrations {
-		migration := m.Factory(ctx.WithField("migration", filepath.Base(m.Name)))
-		goose.AddNamedMigration(m.Name, migration.Up, migration.Down)
+	driverName := *flagDBDriver
+	if driverName == "" {
+		driverName = dialect.FromURI(*flagDBURI).DriverName()
+	}
+	// Golang migrations backfill data of databases created before they were
+	// introduced, PostgreSQL databases are newer than all of them.
+	if dialect.FromDriverName(driverName) == dialect.MySQL {
+		for _, m := range migrate.Migrations {
+			migration := m.Factory(ctx.WithField("migration", filepath.Base(m.Name)))
+			goose.AddNamedMigration(m.Name, migration.Up, migration.Down)
+		}
 	}
 
 	command := os.Args[len(os.Args)-1]
-	db, err := goose.OpenDBWithDriver(*flagDBDriver, *flagDBURI)
+	db, err := goose.OpenDBWithDriver(driverName, *flagDBURI)
 	if err != nil {
 		ctx.Logger().Fatalf("failed to open DB: %v", err)
 	}
@@ -9,7 +9,7 @@ import (
 	"fmt"
 	"github.com/google/go-safeweb/safesql"
 
-	"github.com/go-sql-driver/mysql"
+	"github.com/linuxboot/contest/db/rdbms/dialect"
 	"github.com/pressly/goose"
 )
 
@@ -29,11 +29,9 @@ func DBVersion(db safesql.DB) (uint64, error) {
 	query := safesql.TrustedSQLStringConcat(safesql.New("select version_id, is_applied from "), safesql.New(gooseTableName), safesql.New(" order by id desc"))
 	rows, err := db.Query(query)
 	if err != nil {
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-			if mysqlErr.Number == 1146 {
-				// db versioning table does not exist, assume version 0
-				return 0, nil
-			}
+		if dialect.IsUndefinedTable(err) {
+			// db versioning table does not exist, assume version 0
+			return 0, nil
 		}
 		return 0, fmt.Errorf("could not retrieve db version, error %+v, %T: %w", err, err, err)
 	}